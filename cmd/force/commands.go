@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+func runLogin(a *app, args []string) error {
+	fs := newFlagSet("login", "[flags]")
+	loginURL := fs.String("login-url", "", "login URL, defaults to "+force.DefaultLoginURL)
+	username := fs.String("username", "", "username")
+	password := fs.String("password", "", "password, including any security token")
+	clientID := fs.String("client-id", "", "connected app consumer key")
+	clientSecret := fs.String("client-secret", "", "connected app consumer secret")
+	flow := fs.String("flow", "password", "OAuth flow: password, client-credentials or device")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	// Flags take precedence over the stored profile and environment variables. Only the stored profile and the
+	// flags are saved, so credentials read from environment variables never end up in the config file.
+	p, saved := a.config.profile(a.profileName), a.config.storedProfile(a.profileName)
+	for _, target := range []*Profile{p, saved} {
+		for value, field := range map[*string]*string{
+			loginURL: &target.LoginURL, username: &target.Username, password: &target.Password,
+			clientID: &target.ClientID, clientSecret: &target.ClientSecret,
+		} {
+			if *value != "" {
+				*field = *value
+			}
+		}
+	}
+
+	// Always authenticate with credentials rather than reusing a previously saved session.
+	p.AccessToken = ""
+	var opts []force.Option
+	switch *flow {
+	case "password":
+		opts = p.options()
+	case "client-credentials":
+		// Drop any stored user so the saved profile keeps using the client credentials grant.
+		p.Username, p.Password = "", ""
+		saved.Username, saved.Password = "", ""
+		opts = append(p.endpointOptions(), force.ClientCredentials(p.ClientID, p.ClientSecret))
+	case "device":
+		deviceFlow := &force.DeviceFlow{LoginURL: p.LoginURL, ClientID: p.ClientID}
+		opts = append(p.endpointOptions(), force.DeviceAuth(deviceFlow, func(auth *force.DeviceAuthorization) error {
+			_, err := fmt.Fprintln(a.stdout, auth.String())
+			return err
+		}))
+	default:
+		return fmt.Errorf("unknown OAuth flow %q", *flow)
+	}
+	client, err := force.New(opts...)
+	if err != nil {
+		return err
+	}
+
+	saved.InstanceURL = client.InstanceURL()
+	saved.AccessToken = client.AccessToken()
+	a.config.Profiles[a.profileName] = saved
+	if a.config.DefaultProfile == "" {
+		a.config.DefaultProfile = a.profileName
+	}
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Logged in to %s as profile %q\n", saved.InstanceURL, a.profileName)
+	return nil
+}
+
+func runDescribe(a *app, args []string) error {
+	fs := newFlagSet("describe", "[flags] <object>")
+	fieldsOnly := fs.Bool("fields", false, "list only the fields as a table")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	meta, _, err := client.Describe(args[0])
+	if err != nil {
+		return err
+	}
+
+	if !*fieldsOnly {
+		return writeJSON(a.stdout, meta)
+	}
+
+	rows := make([][]string, 0, len(meta.Fields))
+	for _, f := range meta.Fields {
+		rows = append(rows, []string{f.GetName(), f.GetLabel(), f.Type.ToString(), fmt.Sprint(f.GetLength()),
+			fmt.Sprint(!f.GetNillable() && !f.GetDefaultedOnCreate() && f.GetCreateable())})
+	}
+	return writeTable(a.stdout, []string{"NAME", "LABEL", "TYPE", "LENGTH", "REQUIRED"}, rows)
+}
+
+func runQuery(a *app, args []string) error {
+	fs := newFlagSet("query", "[flags] <soql>")
+	format := fs.String("format", formatTable, "output format: table, json, csv or ndjson")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := validateFormat(*format, formatTable, formatJSON, formatCSV, formatNDJSON); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	return writeQuery(a.stdout, *format, args[0], client.Iterate(&force.QueryRequest{SOQL: args[0]}))
+}
+
+func runGet(a *app, args []string) error {
+	fs := newFlagSet("get", "[flags] <object> <id>")
+	fields := fs.String("fields", "", "comma separated list of fields to return")
+	args, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	record, _, err := client.Get(args[0], args[1], splitList(*fields)...)
+	if err != nil {
+		return err
+	}
+	return writeJSON(a.stdout, record)
+}
+
+func runCreate(a *app, args []string) error {
+	fs := newFlagSet("create", "[flags] <object>")
+	data := fs.String("data", "", "record fields as a JSON object, read from stdin if omitted")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	fields, err := a.readRecord(*data)
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	result, _, err := client.Create(args[0], fields)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout, result.ID)
+	return nil
+}
+
+func runUpdate(a *app, args []string) error {
+	fs := newFlagSet("update", "[flags] <object> <id>")
+	data := fs.String("data", "", "record fields as a JSON object, read from stdin if omitted")
+	args, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	fields, err := a.readRecord(*data)
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	_, err = client.Update(args[0], args[1], fields)
+	return err
+}
+
+func runDelete(a *app, args []string) error {
+	fs := newFlagSet("delete", "<object> <id>")
+	args, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	_, err = client.Destroy(args[0], args[1])
+	return err
+}
+
+func runLimits(a *app, args []string) error {
+	fs := newFlagSet("limits", "[flags]")
+	format := fs.String("format", formatTable, "output format: table or json")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if err := validateFormat(*format, formatTable, formatJSON); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	limits, _, err := client.Limits()
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return writeJSON(a.stdout, limits)
+	}
+
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{name, fmt.Sprint(limits[name].Max), fmt.Sprint(limits[name].Remaining)})
+	}
+	return writeTable(a.stdout, []string{"NAME", "MAX", "REMAINING"}, rows)
+}
+
+func runDiff(a *app, args []string) error {
+	fs := newFlagSet("diff", "[flags] [object ...]")
+	target := fs.String("target", "", "profile of the org to compare with, defaults to the selected profile")
+	apiVersion := fs.String("api-version", "", "API version of the selected profile")
+	targetAPIVersion := fs.String("target-api-version", "", "API version of the target")
+	format := fs.String("format", formatTable, "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" && *targetAPIVersion == "" {
+		fs.Usage()
+		return fmt.Errorf("diff requires -target or -target-api-version")
+	}
+	if err := validateFormat(*format, formatTable, formatJSON); err != nil {
+		return err
+	}
+
+	source := a.config.profile(a.profileName)
+	if *apiVersion != "" {
+		source.APIVersion = *apiVersion
+	}
+	targetProfile := a.config.profile(a.profileName)
+	if *target != "" {
+		targetProfile = a.config.profile(*target)
+	}
+	if *targetAPIVersion != "" {
+		targetProfile.APIVersion = *targetAPIVersion
+	}
+
+	sourceClient, err := source.client()
+	if err != nil {
+		return err
+	}
+	targetClient, err := targetProfile.client()
+	if err != nil {
+		return err
+	}
+	diff, err := force.CompareSchemas(sourceClient, targetClient, fs.Args()...)
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return writeJSON(a.stdout, diff)
+	}
+	return diff.WriteReport(a.stdout)
+}
+
+func runSnapshot(a *app, args []string) error {
+	fs := newFlagSet("snapshot", "[flags] [object ...]")
+	dir := fs.String("dir", "schema", "directory to save the snapshot to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	snapshot, err := client.Snapshot(fs.Args()...)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Save(*dir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Saved %d SObject(s) to %s\n", len(snapshot.ObjectNames()), *dir)
+	return nil
+}
+
+func runGenerate(a *app, args []string) error {
+	fs := newFlagSet("generate", "[flags] <object> [object ...]")
+	snapshotDir := fs.String("snapshot", "", "snapshot directory to read describes from instead of the org")
+	pkg := fs.String("package", "models", "package name of the generated code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("generate expects at least 1 argument")
+	}
+
+	var snapshot *force.Snapshot
+	if *snapshotDir != "" {
+		var err error
+		if snapshot, err = force.LoadSnapshot(*snapshotDir); err != nil {
+			return err
+		}
+	} else {
+		client, err := a.client()
+		if err != nil {
+			return err
+		}
+		if snapshot, err = client.Snapshot(fs.Args()...); err != nil {
+			return err
+		}
+	}
+
+	src, err := snapshot.GenerateStructs(*pkg, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	_, err = a.stdout.Write(src)
+	return err
+}
+
+// readRecord decodes a JSON object from the flag value or, if empty, from stdin.
+func (a *app) readRecord(data string) (map[string]interface{}, error) {
+	raw := []byte(data)
+	if data == "" {
+		var err error
+		if raw, err = ioutil.ReadAll(a.stdin); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("record must be a JSON object: %v", err)
+	}
+	return fields, nil
+}
+
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
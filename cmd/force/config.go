@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config is the CLI configuration file holding one or more named org profiles.
+type Config struct {
+	// DefaultProfile is used when no profile is selected by flag or environment variable.
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+
+	// Profiles are keyed by profile name.
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Profile holds the credentials and session for a single org.
+type Profile struct {
+	LoginURL     string `json:"loginUrl,omitempty"`
+	InstanceURL  string `json:"instanceUrl,omitempty"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	APIVersion   string `json:"apiVersion,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// defaultConfigPath returns the config file location, honoring FORCE_CONFIG.
+func defaultConfigPath() string {
+	if p := os.Getenv("FORCE_CONFIG"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".force.json"
+	}
+	return filepath.Join(home, ".force", "config.json")
+}
+
+// loadConfig reads the config file. A missing file results in an empty config.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config %s: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the config file with permissions restricted to the current user as it holds secrets.
+func (c *Config) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// profileName resolves the selected profile from the flag, FORCE_PROFILE or the config default.
+func (c *Config) profileName(flagValue string) string {
+	switch {
+	case flagValue != "":
+		return flagValue
+	case os.Getenv("FORCE_PROFILE") != "":
+		return os.Getenv("FORCE_PROFILE")
+	case c.DefaultProfile != "":
+		return c.DefaultProfile
+	}
+	return "default"
+}
+
+// storedProfile returns a copy of the named profile as saved in the config file.
+func (c *Config) storedProfile(name string) *Profile {
+	p := &Profile{}
+	if stored, ok := c.Profiles[name]; ok {
+		*p = *stored
+	}
+	return p
+}
+
+// profile returns a copy of the named profile with any FORCE_* environment variables applied on top.
+func (c *Config) profile(name string) *Profile {
+	p := c.storedProfile(name)
+
+	env := map[string]*string{
+		"FORCE_LOGIN_URL":     &p.LoginURL,
+		"FORCE_INSTANCE_URL":  &p.InstanceURL,
+		"FORCE_ACCESS_TOKEN":  &p.AccessToken,
+		"FORCE_API_VERSION":   &p.APIVersion,
+		"FORCE_USERNAME":      &p.Username,
+		"FORCE_PASSWORD":      &p.Password,
+		"FORCE_CLIENT_ID":     &p.ClientID,
+		"FORCE_CLIENT_SECRET": &p.ClientSecret,
+	}
+	for key, field := range env {
+		if v := os.Getenv(key); v != "" {
+			*field = v
+		}
+	}
+
+	return p
+}
+
+// client builds a force.Client from the profile. A saved session is checked first if the profile also has
+// credentials, and the credentials are used instead when the org rejects the session with INVALID_SESSION_ID.
+func (p *Profile) client() (*force.Client, error) {
+	client, err := force.New(p.options()...)
+	if err != nil || p.AccessToken == "" || !p.hasCredentials() {
+		return client, err
+	}
+
+	// A client created from a saved session doesn't contact the org, so make a cheap request to check the session.
+	if _, _, err := client.Limits(); err == nil {
+		return client, nil
+	} else if !strings.Contains(err.Error(), "INVALID_SESSION_ID") {
+		return nil, err
+	}
+
+	credentials := *p
+	credentials.AccessToken = ""
+	return force.New(credentials.options()...)
+}
+
+// hasCredentials reports whether the profile can authenticate without a saved session.
+func (p *Profile) hasCredentials() bool {
+	return p.Username != "" || (p.ClientID != "" && p.ClientSecret != "")
+}
+
+// options converts the profile into client options, preferring a saved session over credentials. A profile with
+// a client ID and secret but no username authenticates with the client credentials grant.
+func (p *Profile) options() []force.Option {
+	opts := p.endpointOptions()
+	if p.AccessToken != "" {
+		return append(opts, force.InstanceURL(p.InstanceURL), force.AccessToken(p.AccessToken))
+	}
+	if p.Username == "" && p.ClientID != "" && p.ClientSecret != "" {
+		return append(opts, force.ClientCredentials(p.ClientID, p.ClientSecret))
+	}
+	return append(opts, force.OAuthCred(p.Username, p.Password, p.ClientID, p.ClientSecret))
+}
+
+// endpointOptions converts the login URL and API version of the profile into client options.
+func (p *Profile) endpointOptions() []force.Option {
+	opts := make([]force.Option, 0)
+	if p.LoginURL != "" {
+		opts = append(opts, force.LoginURL(p.LoginURL))
+	}
+	if p.APIVersion != "" {
+		opts = append(opts, force.APIVersion(p.APIVersion))
+	}
+	return opts
+}
@@ -0,0 +1,151 @@
+// Command force is a command-line tool for querying, describing and modifying Salesforce records.
+//
+// Credentials are read from FORCE_* environment variables or from named org profiles stored in
+// ~/.force/config.json (or the file named by FORCE_CONFIG):
+//
+//	force login -profile dev -username me@example.com -password ... -client-id ... -client-secret ...
+//	force login -profile ci -flow client-credentials -login-url https://mydomain.my.salesforce.com -client-id ...
+//	force -profile dev query -format csv "select Id, Name, Owner.Name from Account"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a CLI subcommand.
+type command struct {
+	// summary is a one line description.
+	summary string
+
+	// run executes the subcommand with its arguments.
+	run func(app *app, args []string) error
+}
+
+var commands = map[string]*command{
+	"login":    {summary: "authenticate and save the session to the profile", run: runLogin},
+	"describe": {summary: "describe a SObject", run: runDescribe},
+	"query":    {summary: "run a SOQL query, fetching every page", run: runQuery},
+	"get":      {summary: "retrieve a record", run: runGet},
+	"create":   {summary: "create a record from JSON", run: runCreate},
+	"update":   {summary: "update a record from JSON", run: runUpdate},
+	"delete":   {summary: "delete a record", run: runDelete},
+	"limits":   {summary: "show org limits", run: runLimits},
+	"diff":     {summary: "compare the schema of two orgs or API versions", run: runDiff},
+	"snapshot": {summary: "save the describe of SObjects to a directory", run: runSnapshot},
+	"generate": {summary: "generate Go structs for SObjects", run: runGenerate},
+}
+
+// app holds the state shared by all subcommands.
+type app struct {
+	configPath  string
+	config      *Config
+	profileName string
+	stdin       io.Reader
+	stdout      io.Writer
+}
+
+// client builds a force.Client from the selected profile. When an expired session is replaced, the new session is
+// saved to the stored profile.
+func (a *app) client() (*force.Client, error) {
+	p := a.config.profile(a.profileName)
+	client, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+
+	stored, ok := a.config.Profiles[a.profileName]
+	if !ok || p.AccessToken == "" || stored.AccessToken != p.AccessToken || client.AccessToken() == p.AccessToken {
+		return client, nil
+	}
+	stored.InstanceURL, stored.AccessToken = client.InstanceURL(), client.AccessToken()
+	if err := a.config.save(a.configPath); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "force: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("force", flag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath(), "path to the config file")
+	profile := fs.String("profile", "", "name of the org profile to use")
+	fs.Usage = func() { usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		usage(fs)
+		return fmt.Errorf("no command specified")
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		usage(fs)
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	a := &app{
+		configPath:  *configPath,
+		config:      cfg,
+		profileName: cfg.profileName(*profile),
+		stdin:       stdin,
+		stdout:      stdout,
+	}
+	return cmd.run(a, fs.Args()[1:])
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintf(out, "Usage: force [-config path] [-profile name] <command> [flags] [args]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-9s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintf(out, "\nGlobal flags:\n")
+	fs.PrintDefaults()
+}
+
+// newFlagSet returns a flag set for a subcommand with usage output describing its arguments.
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: force %s %s\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses subcommand flags and checks the number of positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", fs.Name(), n, fs.NArg())
+	}
+	return fs.Args(), nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var forceEnv = []string{"FORCE_CONFIG", "FORCE_PROFILE", "FORCE_LOGIN_URL", "FORCE_INSTANCE_URL", "FORCE_ACCESS_TOKEN",
+	"FORCE_API_VERSION", "FORCE_USERNAME", "FORCE_PASSWORD", "FORCE_CLIENT_ID", "FORCE_CLIENT_SECRET"}
+
+func newFakeServer() *forcetest.Server {
+	fake := forcetest.NewServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Account", "001",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Industry", force.FieldDataTypes.Picklist),
+	))
+	return fake
+}
+
+// writeConfig clears the FORCE_* environment variables and writes a config file with the given profiles.
+func writeConfig(t *testing.T, profiles map[string]*Profile, defaultProfile string) string {
+	for _, key := range forceEnv {
+		t.Setenv(key, "")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &Config{DefaultProfile: defaultProfile, Profiles: profiles}
+	assert.Nil(t, cfg.save(path))
+	return path
+}
+
+// sessionConfig writes a config whose default profile holds a session for the fake org.
+func sessionConfig(t *testing.T, fake *forcetest.Server) string {
+	return writeConfig(t, map[string]*Profile{
+		"dev": {InstanceURL: fake.URL, AccessToken: fake.AccessToken()},
+	}, "dev")
+}
+
+func runCommand(configPath, stdin string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := run(append([]string{"-config", configPath}, args...), strings.NewReader(stdin), &stdout)
+	return stdout.String(), err
+}
+
+func readConfig(t *testing.T, path string) *Config {
+	cfg, err := loadConfig(path)
+	assert.Nil(t, err)
+	return cfg
+}
+
+func TestLogin(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("me@example.com", "secret", "client-id", "client-secret")
+
+	path := writeConfig(t, map[string]*Profile{}, "")
+	t.Setenv("FORCE_PASSWORD", "secret")
+	t.Setenv("FORCE_CLIENT_SECRET", "client-secret")
+
+	out, err := runCommand(path, "", "-profile", "dev", "login", "-login-url", fake.URL,
+		"-username", "me@example.com", "-client-id", "client-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "Logged in to "+fake.URL+" as profile \"dev\"\n", out)
+
+	// The password and client secret came from the environment, so they must not be saved.
+	cfg := readConfig(t, path)
+	assert.Equal(t, "dev", cfg.DefaultProfile)
+	assert.Equal(t, &Profile{LoginURL: fake.URL, InstanceURL: fake.URL, AccessToken: fake.AccessToken(),
+		Username: "me@example.com", ClientID: "client-id"}, cfg.Profiles["dev"])
+
+	// Credentials passed as flags are saved.
+	_, err = runCommand(path, "", "login", "-password", "secret", "-client-secret", "client-secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", readConfig(t, path).Profiles["dev"].Password)
+	assert.Equal(t, "client-secret", readConfig(t, path).Profiles["dev"].ClientSecret)
+
+	_, err = runCommand(path, "", "login", "-password", "wrong")
+	assert.NotNil(t, err)
+	_, err = runCommand(path, "", "login", "-flow", "saml")
+	assert.EqualError(t, err, `unknown OAuth flow "saml"`)
+}
+
+func TestExpiredSession(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+
+	path := writeConfig(t, map[string]*Profile{
+		"dev": {LoginURL: fake.URL, InstanceURL: fake.URL, AccessToken: "EXPIRED", Username: "me@example.com",
+			Password: "secret", ClientID: "client-id", ClientSecret: "client-secret"},
+		"token": {InstanceURL: fake.URL, AccessToken: "EXPIRED"},
+	}, "dev")
+
+	out, err := runCommand(path, "", "limits", "-format", "json")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "DailyApiRequests")
+	assert.Equal(t, fake.AccessToken(), readConfig(t, path).Profiles["dev"].AccessToken)
+
+	// Without credentials the expired session can't be replaced.
+	_, err = runCommand(path, "", "-profile", "token", "limits")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "INVALID_SESSION_ID")
+}
+
+func TestDescribe(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	path := sessionConfig(t, fake)
+
+	out, err := runCommand(path, "", "describe", "-fields", "Account")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "NAME")
+	assert.Contains(t, out, "Industry")
+
+	out, err = runCommand(path, "", "describe", "Account")
+	assert.Nil(t, err)
+	var meta force.SObjectMetadata
+	assert.Nil(t, json.Unmarshal([]byte(out), &meta))
+	assert.Equal(t, "Account", meta.GetName())
+
+	_, err = runCommand(path, "", "describe")
+	assert.EqualError(t, err, "describe expects 1 argument(s), got 0")
+}
+
+func TestQuery(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	path := sessionConfig(t, fake)
+
+	out, err := runCommand(path, "", "query", "select Id, Name from Account")
+	assert.Nil(t, err)
+	assert.Equal(t, "No records found.\n", out)
+
+	_, err = fake.Insert("Account", force.SObject{"Name": "Acme"})
+	assert.Nil(t, err)
+
+	out, err = runCommand(path, "", "query", "-format", "csv", "select Name from Account")
+	assert.Nil(t, err)
+	assert.Equal(t, "Name\nAcme\n", out)
+
+	out, err = runCommand(path, "", "query", "select Name from Account")
+	assert.Nil(t, err)
+	assert.Equal(t, "Name\nAcme\n", out)
+
+	_, err = runCommand(path, "", "query", "-format", "xml", "select Name from Account")
+	assert.EqualError(t, err, `unsupported format "xml"`)
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	path := sessionConfig(t, fake)
+
+	out, err := runCommand(path, `{"Name": "Acme"}`, "create", "Account")
+	assert.Nil(t, err)
+	id := strings.TrimSpace(out)
+	record, ok := fake.Record("Account", id)
+	assert.True(t, ok)
+	assert.Equal(t, "Acme", record["Name"])
+
+	_, err = runCommand(path, "", "update", "-data", `{"Industry": "Energy"}`, "Account", id)
+	assert.Nil(t, err)
+	record, _ = fake.Record("Account", id)
+	assert.Equal(t, "Energy", record["Industry"])
+
+	out, err = runCommand(path, "", "get", "-fields", "Name", "Account", id)
+	assert.Nil(t, err)
+	assert.Contains(t, out, `"Name": "Acme"`)
+
+	_, err = runCommand(path, "", "delete", "Account", id)
+	assert.Nil(t, err)
+	_, ok = fake.Record("Account", id)
+	assert.False(t, ok)
+
+	_, err = runCommand(path, "not json", "create", "Account")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "record must be a JSON object")
+}
+
+func TestLimits(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	path := sessionConfig(t, fake)
+
+	out, err := runCommand(path, "", "limits")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(out, "NAME"))
+	assert.Contains(t, out, "DataStorageMB")
+
+	out, err = runCommand(path, "", "limits", "-format", "json")
+	assert.Nil(t, err)
+	var limits map[string]*force.Limit
+	assert.Nil(t, json.Unmarshal([]byte(out), &limits))
+	assert.Equal(t, 20, limits["FileStorageMB"].Max)
+
+	_, err = runCommand(path, "", "limits", "-format", "csv")
+	assert.EqualError(t, err, `unsupported format "csv"`)
+}
+
+func TestDiff(t *testing.T) {
+	source := newFakeServer()
+	defer source.Close()
+	target := newFakeServer()
+	defer target.Close()
+	target.AddSObject(forcetest.NewSObjectMetadata("Invoice__c", "a01"))
+
+	path := writeConfig(t, map[string]*Profile{
+		"dev":  {InstanceURL: source.URL, AccessToken: source.AccessToken()},
+		"prod": {InstanceURL: target.URL, AccessToken: target.AccessToken()},
+	}, "dev")
+
+	header := "Comparing " + source.URL + " v50.0 with " + target.URL + " v50.0\n\n"
+	out, err := runCommand(path, "", "diff", "-target", "prod")
+	assert.Nil(t, err)
+	assert.Equal(t, header+"+ Invoice__c\n", out)
+
+	out, err = runCommand(path, "", "diff", "-target", "prod", "Account")
+	assert.Nil(t, err)
+	assert.Equal(t, header+"No differences found.\n", out)
+
+	_, err = runCommand(path, "", "diff", "-target", "prod", "-format", "csv")
+	assert.EqualError(t, err, `unsupported format "csv"`)
+	_, err = runCommand(path, "", "diff")
+	assert.EqualError(t, err, "diff requires -target or -target-api-version")
+}
+
+func TestSnapshotAndGenerate(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	path := sessionConfig(t, fake)
+	dir := filepath.Join(t.TempDir(), "schema")
+
+	out, err := runCommand(path, "", "snapshot", "-dir", dir, "Account")
+	assert.Nil(t, err)
+	assert.Equal(t, "Saved 1 SObject(s) to "+dir+"\n", out)
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	fromSnapshot, err := runCommand(path, "", "generate", "-snapshot", dir, "-package", "salesforce", "Account")
+	assert.Nil(t, err)
+	assert.Contains(t, fromSnapshot, "package salesforce")
+	assert.Contains(t, fromSnapshot, "type Account struct")
+
+	fromOrg, err := runCommand(path, "", "generate", "-package", "salesforce", "Account")
+	assert.Nil(t, err)
+	assert.Equal(t, fromSnapshot, fromOrg)
+
+	_, err = runCommand(path, "", "generate")
+	assert.EqualError(t, err, "generate expects at least 1 argument")
+}
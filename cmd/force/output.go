@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// validateFormat checks the output format is one of the formats supported by a subcommand.
+func validateFormat(format string, supported ...string) error {
+	for _, s := range supported {
+		if format == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeQuery streams every record of the query in the requested format. Table and CSV columns follow the
+// SOQL select list, or the first record if it uses FIELDS().
+func writeQuery(w io.Writer, format, soql string, iter *force.QueryIterator) error {
+	switch format {
+	case formatJSON:
+		records := make([]force.SObject, 0)
+		for iter.Next() {
+			records = append(records, force.StripAttributes(iter.Record()))
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		return writeJSON(w, records)
+	case formatNDJSON:
+		return force.WriteQueryIterator(force.NewNDJSONWriter(w), iter)
+	case formatCSV:
+		return force.WriteQueryIterator(force.NewCSVWriter(w, force.SelectFields(soql)), iter)
+	}
+
+	columns := force.SelectFields(soql)
+	rows := make([][]string, 0)
+	for iter.Next() {
+		record := force.FlattenRecord(iter.Record())
+		if columns == nil {
+			// The columns of FIELDS() are inferred from the first record, as the CSV writer does.
+			columns = make([]string, 0, len(record))
+			for k := range record {
+				columns = append(columns, k)
+			}
+			sort.Strings(columns)
+		}
+
+		flat := map[string]interface{}{}
+		for k, v := range record {
+			flat[strings.ToLower(k)] = v
+		}
+
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = force.FormatValue(flat[strings.ToLower(c)])
+		}
+		rows = append(rows, row)
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No records found.")
+		return nil
+	}
+	return writeTable(w, columns, rows)
+}
@@ -0,0 +1,239 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"strings"
+)
+
+// MaxActionInputs is the maximum number of inputs sent in a single action invocation request.
+const MaxActionInputs = 200
+
+// ActionTypes represents the types of custom actions.
+var ActionTypes = struct {
+	Apex        string
+	Flow        string
+	QuickAction string
+	EmailAlert  string
+}{
+	Apex:        "apex",
+	Flow:        "flow",
+	QuickAction: "quickAction",
+	EmailAlert:  "emailAlert",
+}
+
+// Action represents an invocable action, such as the `emailSimple` standard action or an autolaunched Flow.
+type Action struct {
+	Label string `json:"label,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// ActionList represents the response when listing actions.
+type ActionList struct {
+	Actions []*Action `json:"actions"`
+}
+
+// ActionDescribe represents the inputs and outputs of an action.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_action.meta/api_action/actions_intro_invoking.htm
+type ActionDescribe struct {
+	Category         string             `json:"category,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Label            string             `json:"label,omitempty"`
+	Name             string             `json:"name,omitempty"`
+	Type             string             `json:"type,omitempty"`
+	Standard         bool               `json:"standard"`
+	TargetEntityName *string            `json:"targetEntityName,omitempty"`
+	Inputs           []*ActionParameter `json:"inputs"`
+	Outputs          []*ActionParameter `json:"outputs"`
+}
+
+// ActionParameter represents an input or output of an action.
+type ActionParameter struct {
+	ByteLength     int                                 `json:"byteLength,omitempty"`
+	Description    string                              `json:"description,omitempty"`
+	Label          string                              `json:"label,omitempty"`
+	MaxOccurs      int                                 `json:"maxOccurs,omitempty"`
+	Name           string                              `json:"name"`
+	PicklistValues []SObjectFieldPicklistEntryMetadata `json:"picklistValues,omitempty"`
+	Required       bool                                `json:"required"`
+	SObjectType    *string                             `json:"sobjectType,omitempty"`
+	Type           string                              `json:"type"`
+}
+
+// FindInput returns the input with the name, ignoring case, or nil if there is none.
+func (d *ActionDescribe) FindInput(name string) *ActionParameter {
+	for _, p := range d.Inputs {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// ActionRequest represents the body of an action invocation request.
+type ActionRequest struct {
+	Inputs []interface{} `json:"inputs"`
+}
+
+// ActionResult represents the result of invoking an action with a single input.
+type ActionResult struct {
+	// Index is the position of the input among all inputs of the invocation.
+	Index int `json:"-"`
+
+	ActionName   string                 `json:"actionName,omitempty"`
+	IsSuccess    bool                   `json:"isSuccess"`
+	Errors       []*SObjectError        `json:"errors"`
+	OutputValues map[string]interface{} `json:"outputValues"`
+	Version      int                    `json:"version,omitempty"`
+}
+
+// Decode decodes the output values into v, which is typically a struct with json tags.
+func (r *ActionResult) Decode(v interface{}) error {
+	data, err := json.Marshal(r.OutputValues)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ActionError represents an invocation in which some inputs failed.
+type ActionError struct {
+	// ActionName is the name of the invoked action.
+	ActionName string
+
+	// Failures holds the results of the failed inputs.
+	Failures []*ActionResult
+}
+
+// Error summarizes the failed inputs.
+func (e *ActionError) Error() string {
+	problems := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		messages := make([]string, 0, len(f.Errors))
+		for _, err := range f.Errors {
+			messages = append(messages, err.Error())
+		}
+		problems = append(problems, fmt.Sprintf("input %d: %s", f.Index, strings.Join(messages, ", ")))
+	}
+	return fmt.Sprintf("%d inputs to %s failed: %s", len(e.Failures), e.ActionName, strings.Join(problems, "; "))
+}
+
+// StandardActions lists the standard actions, such as `emailSimple` and `chatterPost`.
+func (c *Client) StandardActions() ([]*Action, *simpleresty.Response, error) {
+	return c.listActions("standard")
+}
+
+// CustomActionTypes returns the URLs of the custom action types keyed by type, such as `flow`.
+func (c *Client) CustomActionTypes() (map[string]string, *simpleresty.Response, error) {
+	var result map[string]string
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/actions/custom", c.apiVersion))
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// CustomActions lists the custom actions of one of the ActionTypes, such as the autolaunched Flows.
+func (c *Client) CustomActions(actionType string) ([]*Action, *simpleresty.Response, error) {
+	return c.listActions("custom/" + actionType)
+}
+
+func (c *Client) listActions(resource string) ([]*Action, *simpleresty.Response, error) {
+	var result *ActionList
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/actions/%s", c.apiVersion, resource))
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result.Actions, response, nil
+}
+
+// DescribeStandardAction describes the inputs and outputs of a standard action.
+func (c *Client) DescribeStandardAction(name string) (*ActionDescribe, *simpleresty.Response, error) {
+	return c.describeAction("standard/" + name)
+}
+
+// DescribeCustomAction describes the inputs and outputs of a custom action of one of the ActionTypes.
+func (c *Client) DescribeCustomAction(actionType, name string) (*ActionDescribe, *simpleresty.Response, error) {
+	return c.describeAction(fmt.Sprintf("custom/%s/%s", actionType, name))
+}
+
+func (c *Client) describeAction(resource string) (*ActionDescribe, *simpleresty.Response, error) {
+	var result *ActionDescribe
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/actions/%s", c.apiVersion, resource))
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// InvokeStandardAction invokes a standard action once per input. See InvokeCustomAction.
+func (c *Client) InvokeStandardAction(name string, inputs ...interface{}) ([]*ActionResult, error) {
+	return c.invokeAction(name, "standard/"+name, inputs)
+}
+
+// InvokeCustomAction invokes a custom action of one of the ActionTypes once per input. An input can be a
+// SObject, a map or a struct with json tags.
+//
+// Inputs are sent in batches of up to MaxActionInputs and a result is returned for each input in order. If any
+// input fails, every result is returned along with an *ActionError collecting the failures. Batches are invoked
+// independently, so a failure doesn't undo earlier batches.
+func (c *Client) InvokeCustomAction(actionType, name string, inputs ...interface{}) ([]*ActionResult, error) {
+	return c.invokeAction(name, fmt.Sprintf("custom/%s/%s", actionType, name), inputs)
+}
+
+// LaunchFlow launches an autolaunched Flow once per input, or once without inputs if none are supplied.
+// See InvokeCustomAction.
+func (c *Client) LaunchFlow(flowName string, inputs ...interface{}) ([]*ActionResult, error) {
+	if len(inputs) == 0 {
+		inputs = []interface{}{SObject{}}
+	}
+	return c.InvokeCustomAction(ActionTypes.Flow, flowName, inputs...)
+}
+
+func (c *Client) invokeAction(name, resource string, inputs []interface{}) ([]*ActionResult, error) {
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/actions/%s", c.apiVersion, resource))
+
+	results := make([]*ActionResult, 0, len(inputs))
+	actionErr := &ActionError{ActionName: name, Failures: []*ActionResult{}}
+	for start := 0; start < len(inputs); start += MaxActionInputs {
+		end := start + MaxActionInputs
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+
+		// Salesforce responds with an error status if any input fails, but still returns every result.
+		var batch []*ActionResult
+		req := c.http.ConstructRequest(&batch, &ActionRequest{Inputs: inputs[start:end]}).SetError(&batch)
+		req.Method = simpleresty.PostMethod
+		req.URL = urlStr
+		if _, err := c.http.Dispatch(req); err != nil && len(batch) != end-start {
+			return results, err
+		}
+
+		for i, r := range batch {
+			r.Index = start + i
+			if !r.IsSuccess {
+				actionErr.Failures = append(actionErr.Failures, r)
+			}
+		}
+		results = append(results, batch...)
+	}
+
+	if len(actionErr.Failures) > 0 {
+		return results, actionErr
+	}
+	return results, nil
+}
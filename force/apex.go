@@ -0,0 +1,375 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+)
+
+// ApexError represents anonymous Apex that failed to compile or threw an unhandled exception.
+type ApexError struct {
+	// Line is the line of the compile problem or exception.
+	Line int
+
+	// Column is the column of the compile problem or exception.
+	Column int
+
+	// CompileProblem describes why the Apex didn't compile. Empty if it compiled.
+	CompileProblem string
+
+	// ExceptionMessage is the message of the unhandled exception.
+	ExceptionMessage string
+
+	// StackTrace is the stack trace of the unhandled exception.
+	StackTrace string
+}
+
+// Error returns the compile problem or exception message along with its position.
+func (e *ApexError) Error() string {
+	if e.CompileProblem != "" {
+		return fmt.Sprintf("line %d, column %d: compile error: %s", e.Line, e.Column, e.CompileProblem)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.ExceptionMessage)
+}
+
+// Err returns an *ApexError if the Apex failed to compile or threw an unhandled exception, otherwise nil.
+func (r *ExecuteAnonymousResult) Err() error {
+	if r.Compiled && r.Success {
+		return nil
+	}
+	return &ApexError{
+		Line:             r.Line,
+		Column:           r.Column,
+		CompileProblem:   r.GetCompileProblem(),
+		ExceptionMessage: r.GetExceptionMessage(),
+		StackTrace:       r.GetExceptionStackTrace(),
+	}
+}
+
+// ExecuteAnonymous compiles and executes the Apex using the Tooling API. If the Apex fails to compile or throws
+// an unhandled exception, the result is returned along with an *ApexError describing the problem.
+func (c *Client) ExecuteAnonymous(apex string) (*ExecuteAnonymousResult, error) {
+	result, _, err := c.Tooling.ExecuteAnonymous(apex)
+	if err != nil {
+		return nil, err
+	}
+	return result, result.Err()
+}
+
+// ApexTestOutcomes represents all outcomes of an Apex test method.
+var ApexTestOutcomes = struct {
+	Pass        string
+	Fail        string
+	CompileFail string
+	Skip        string
+}{
+	Pass:        "Pass",
+	Fail:        "Fail",
+	CompileFail: "CompileFail",
+	Skip:        "Skip",
+}
+
+// ApexTestRunResult represents the outcome of running Apex tests.
+type ApexTestRunResult struct {
+	// JobID is the ID of the AsyncApexJob of an asynchronous run.
+	JobID string
+
+	// NumTestsRun is the number of test methods run.
+	NumTestsRun int
+
+	// NumFailures is the number of test methods that didn't pass.
+	NumFailures int
+
+	// TotalTime is the run time in milliseconds.
+	TotalTime float64
+
+	// Tests holds the result of every test method.
+	Tests []*ApexTestMethodResult
+
+	// Coverage holds the code coverage of every class and trigger, unless coverage was skipped.
+	Coverage []*ApexCodeCoverage
+
+	// CoverageWarnings holds any code coverage warnings of a synchronous run.
+	CoverageWarnings []string
+}
+
+// Passed returns whether every test method passed.
+func (r *ApexTestRunResult) Passed() bool {
+	return r.NumFailures == 0
+}
+
+// Failures returns the test methods that didn't pass.
+func (r *ApexTestRunResult) Failures() []*ApexTestMethodResult {
+	failures := make([]*ApexTestMethodResult, 0)
+	for _, t := range r.Tests {
+		if t.Outcome != ApexTestOutcomes.Pass && t.Outcome != ApexTestOutcomes.Skip {
+			failures = append(failures, t)
+		}
+	}
+	return failures
+}
+
+// ApexTestMethodResult represents the result of a single Apex test method.
+type ApexTestMethodResult struct {
+	ClassID    string
+	ClassName  string
+	MethodName string
+
+	// Outcome is one of the ApexTestOutcomes.
+	Outcome    string
+	Message    string
+	StackTrace string
+
+	// Time is the run time of the method in milliseconds.
+	Time float64
+}
+
+// ApexCodeCoverage represents the code coverage of an Apex class or trigger.
+type ApexCodeCoverage struct {
+	ID                string
+	Name              string
+	NumLinesCovered   int
+	NumLinesUncovered int
+
+	// UncoveredLines lists the line numbers not covered, if known.
+	UncoveredLines []int
+}
+
+// Percent returns the percentage of lines covered. Classes without any lines are 100% covered.
+func (c *ApexCodeCoverage) Percent() float64 {
+	total := c.NumLinesCovered + c.NumLinesUncovered
+	if total == 0 {
+		return 100
+	}
+	return float64(c.NumLinesCovered) * 100 / float64(total)
+}
+
+// RunTestsSynchronousResult represents the response of a synchronous Apex test run.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_tooling.meta/api_tooling/intro_rest_resources_runtestssynchronous.htm
+type RunTestsSynchronousResult struct {
+	NumTestsRun          int                       `json:"numTestsRun"`
+	NumFailures          int                       `json:"numFailures"`
+	TotalTime            float64                   `json:"totalTime"`
+	Successes            []*RunTestSuccess         `json:"successes"`
+	Failures             []*RunTestFailure         `json:"failures"`
+	CodeCoverage         []*RunTestCodeCoverage    `json:"codeCoverage"`
+	CodeCoverageWarnings []*RunTestCoverageWarning `json:"codeCoverageWarnings"`
+}
+
+// RunTestSuccess represents a passing test method of a synchronous run.
+type RunTestSuccess struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Namespace  *string `json:"namespace"`
+	MethodName string  `json:"methodName"`
+	Time       float64 `json:"time"`
+}
+
+// RunTestFailure represents a failing test method of a synchronous run.
+type RunTestFailure struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Namespace  *string `json:"namespace"`
+	MethodName string  `json:"methodName"`
+	Message    string  `json:"message"`
+	StackTrace string  `json:"stackTrace"`
+	Type       string  `json:"type"`
+	Time       float64 `json:"time"`
+}
+
+// RunTestCodeCoverage represents the code coverage of a class or trigger in a synchronous run.
+type RunTestCodeCoverage struct {
+	ID                     string                 `json:"id"`
+	Name                   string                 `json:"name"`
+	Namespace              *string                `json:"namespace"`
+	Type                   string                 `json:"type"`
+	NumLocations           int                    `json:"numLocations"`
+	NumLocationsNotCovered int                    `json:"numLocationsNotCovered"`
+	LocationsNotCovered    []*RunTestCodeLocation `json:"locationsNotCovered"`
+}
+
+// RunTestCodeLocation represents a line of code.
+type RunTestCodeLocation struct {
+	Line          int     `json:"line"`
+	Column        int     `json:"column"`
+	NumExecutions int     `json:"numExecutions"`
+	Time          float64 `json:"time"`
+}
+
+// RunTestCoverageWarning represents a code coverage warning of a synchronous run.
+type RunTestCoverageWarning struct {
+	ID        string  `json:"id"`
+	Name      *string `json:"name"`
+	Namespace *string `json:"namespace"`
+	Message   string  `json:"message"`
+}
+
+// RunTestsSynchronous runs Apex tests and waits for the results. Salesforce only allows tests of a single class
+// to be run synchronously.
+func (t *ToolingService) RunTestsSynchronous(opts *RunTestsRequest) (*RunTestsSynchronousResult, *simpleresty.Response, error) {
+	var result *RunTestsSynchronousResult
+	urlStr := t.toolingURL("runTestsSynchronous")
+
+	response, err := t.client.http.Post(urlStr, &result, opts)
+	if err != nil {
+		return nil, response, err
+	}
+	return result, response, nil
+}
+
+// RunTests runs Apex tests synchronously and returns their pass/fail and coverage results. Failing tests are
+// reported in the result rather than as an error.
+func (c *Client) RunTests(opts *RunTestsRequest) (*ApexTestRunResult, error) {
+	sync, _, err := c.Tooling.RunTestsSynchronous(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApexTestRunResult{
+		NumTestsRun:      sync.NumTestsRun,
+		NumFailures:      sync.NumFailures,
+		TotalTime:        sync.TotalTime,
+		Tests:            []*ApexTestMethodResult{},
+		Coverage:         []*ApexCodeCoverage{},
+		CoverageWarnings: []string{},
+	}
+	for _, s := range sync.Successes {
+		result.Tests = append(result.Tests, &ApexTestMethodResult{ClassID: s.ID, ClassName: s.Name,
+			MethodName: s.MethodName, Outcome: ApexTestOutcomes.Pass, Time: s.Time})
+	}
+	for _, f := range sync.Failures {
+		result.Tests = append(result.Tests, &ApexTestMethodResult{ClassID: f.ID, ClassName: f.Name,
+			MethodName: f.MethodName, Outcome: ApexTestOutcomes.Fail, Message: f.Message, StackTrace: f.StackTrace,
+			Time: f.Time})
+	}
+	for _, cc := range sync.CodeCoverage {
+		coverage := &ApexCodeCoverage{ID: cc.ID, Name: cc.Name, NumLinesCovered: cc.NumLocations - cc.NumLocationsNotCovered,
+			NumLinesUncovered: cc.NumLocationsNotCovered, UncoveredLines: []int{}}
+		for _, l := range cc.LocationsNotCovered {
+			coverage.UncoveredLines = append(coverage.UncoveredLines, l.Line)
+		}
+		result.Coverage = append(result.Coverage, coverage)
+	}
+	for _, w := range sync.CodeCoverageWarnings {
+		result.CoverageWarnings = append(result.CoverageWarnings, w.Message)
+	}
+	return result, nil
+}
+
+// apexQueueItemDone are the ApexTestQueueItem statuses of a finished class.
+var apexQueueItemDone = map[string]bool{"Completed": true, "Failed": true, "Aborted": true}
+
+// RunTestsAsync queues Apex tests, polls their ApexTestQueueItems until every class finishes and returns the
+// ApexTestResults along with the org's ApexCodeCoverageAggregate coverage. Failing tests are reported in the
+// result rather than as an error.
+func (c *Client) RunTestsAsync(opts *RunTestsRequest, pollOpts ...PollOption) (*ApexTestRunResult, error) {
+	poller, err := NewPoller(pollOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID, _, err := c.Tooling.RunTestsAsynchronous(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApexTestRunResult{
+		JobID:            jobID,
+		Tests:            []*ApexTestMethodResult{},
+		Coverage:         []*ApexCodeCoverage{},
+		CoverageWarnings: []string{},
+	}
+
+	var items []SObject
+	pollErr := poller.Poll(func() (bool, error) {
+		items, err = c.Tooling.QueryAll(&QueryRequest{SOQL: fmt.Sprintf("SELECT Id, ApexClassId, ApexClass.Name, "+
+			"Status, ExtendedStatus FROM ApexTestQueueItem WHERE ParentJobId = '%s'", jobID)})
+		if err != nil {
+			return false, err
+		}
+		for _, item := range items {
+			if !apexQueueItemDone[FormatValue(item["Status"])] {
+				return false, nil
+			}
+		}
+		return len(items) > 0, nil
+	})
+	if pollErr != nil {
+		return nil, fmt.Errorf("apex test run %s: %v", jobID, pollErr)
+	}
+
+	// Classes that failed to run, typically because they don't compile, have no test results.
+	for _, item := range items {
+		if FormatValue(item["Status"]) == "Failed" {
+			flat := FlattenRecord(item)
+			result.Tests = append(result.Tests, &ApexTestMethodResult{ClassID: FormatValue(flat["ApexClassId"]),
+				ClassName: FormatValue(flat["ApexClass.Name"]), Outcome: ApexTestOutcomes.CompileFail,
+				Message: FormatValue(flat["ExtendedStatus"])})
+		}
+	}
+
+	tests, err := c.Tooling.QueryAll(&QueryRequest{SOQL: fmt.Sprintf("SELECT Id, ApexClassId, ApexClass.Name, "+
+		"MethodName, Outcome, Message, StackTrace, RunTime FROM ApexTestResult WHERE AsyncApexJobId = '%s'", jobID)})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tests {
+		flat := FlattenRecord(t)
+		runTime, _ := flat["RunTime"].(float64)
+		result.Tests = append(result.Tests, &ApexTestMethodResult{
+			ClassID:    FormatValue(flat["ApexClassId"]),
+			ClassName:  FormatValue(flat["ApexClass.Name"]),
+			MethodName: FormatValue(flat["MethodName"]),
+			Outcome:    FormatValue(flat["Outcome"]),
+			Message:    FormatValue(flat["Message"]),
+			StackTrace: FormatValue(flat["StackTrace"]),
+			Time:       runTime,
+		})
+		result.TotalTime += runTime
+	}
+	for _, t := range result.Tests {
+		if t.Outcome != ApexTestOutcomes.CompileFail {
+			result.NumTestsRun++
+		}
+	}
+	result.NumFailures = len(result.Failures())
+
+	if opts != nil && opts.GetSkipCodeCoverage() {
+		return result, nil
+	}
+
+	coverage, err := c.Tooling.QueryAll(&QueryRequest{SOQL: "SELECT ApexClassOrTriggerId, ApexClassOrTrigger.Name, " +
+		"NumLinesCovered, NumLinesUncovered, Coverage FROM ApexCodeCoverageAggregate"})
+	if err != nil {
+		return nil, err
+	}
+	for _, cc := range coverage {
+		result.Coverage = append(result.Coverage, decodeCoverageAggregate(cc))
+	}
+	return result, nil
+}
+
+// decodeCoverageAggregate converts an ApexCodeCoverageAggregate record to an ApexCodeCoverage.
+func decodeCoverageAggregate(record SObject) *ApexCodeCoverage {
+	coverage := &ApexCodeCoverage{
+		ID:             FormatValue(record["ApexClassOrTriggerId"]),
+		UncoveredLines: []int{},
+	}
+	if parent, ok := record["ApexClassOrTrigger"].(map[string]interface{}); ok {
+		coverage.Name = FormatValue(parent["Name"])
+	}
+	if n, ok := record["NumLinesCovered"].(float64); ok {
+		coverage.NumLinesCovered = int(n)
+	}
+	if n, ok := record["NumLinesUncovered"].(float64); ok {
+		coverage.NumLinesUncovered = int(n)
+	}
+	if detail, ok := record["Coverage"].(map[string]interface{}); ok {
+		lines, _ := detail["uncoveredLines"].([]interface{})
+		for _, l := range lines {
+			if n, ok := l.(float64); ok {
+				coverage.UncoveredLines = append(coverage.UncoveredLines, int(n))
+			}
+		}
+	}
+	return coverage
+}
@@ -0,0 +1,59 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ApexREST calls a custom Apex REST endpoint exposed by an @RestResource class under /services/apexrest.
+//
+// The path is relative to /services/apexrest, such as `/invoices/INV-1`, and may include a query string. The
+// body, if not nil, is encoded as JSON and a JSON response is decoded into result, which may also be nil.
+func (c *Client) ApexREST(method, path string, body, result interface{}) (*simpleresty.Response, error) {
+	req := c.http.ConstructRequest(result, body)
+	req.Method = strings.ToUpper(method)
+	req.URL = c.http.RequestURL("/services/apexrest/%s", strings.TrimPrefix(path, "/"))
+
+	return c.http.Dispatch(req)
+}
+
+// Do sends an arbitrary HTTP request using the client's authentication, headers, transport and retries.
+//
+// A request URL without a host, such as `/services/data/v50.0/limits`, is resolved against the instance URL. An
+// absolute URL must be on the instance URL, as the request carries the client's access token.
+// Headers set on the request take precedence over the client's. Like every other call, a non-2xx response is
+// returned along with an error.
+func (c *Client) Do(req *http.Request) (*simpleresty.Response, error) {
+	if req.URL.IsAbs() {
+		instanceURL, err := url.Parse(c.instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(req.URL.Scheme, instanceURL.Scheme) || !strings.EqualFold(req.URL.Host, instanceURL.Host) {
+			return nil, fmt.Errorf("request URL %s is not on the instance URL %s", req.URL.Redacted(), c.instanceURL)
+		}
+	}
+
+	r := c.http.R().SetContext(req.Context())
+	for name, values := range req.Header {
+		r.Header[name] = values
+	}
+
+	if req.Body != nil {
+		body, readErr := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		r.SetBody(body)
+	}
+
+	r.Method = req.Method
+	r.URL = c.http.RequestURL("%s", req.URL.RequestURI())
+
+	return c.http.Dispatch(r)
+}
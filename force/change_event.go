@@ -0,0 +1,301 @@
+package force
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"strings"
+	"sync"
+	"time"
+)
+
+const changeEventHeaderField = "ChangeEventHeader"
+
+// ChangeTypes represents all change types of a Change Data Capture event.
+//
+// GAP change types are sent when Salesforce could not generate a full event, in which case only the header is
+// populated and the record should be retrieved. GapOverflow is sent instead of individual events when a single
+// transaction changes too many records.
+var ChangeTypes = struct {
+	Create      string
+	Update      string
+	Delete      string
+	Undelete    string
+	GapCreate   string
+	GapUpdate   string
+	GapDelete   string
+	GapUndelete string
+	GapOverflow string
+}{
+	Create:      "CREATE",
+	Update:      "UPDATE",
+	Delete:      "DELETE",
+	Undelete:    "UNDELETE",
+	GapCreate:   "GAP_CREATE",
+	GapUpdate:   "GAP_UPDATE",
+	GapDelete:   "GAP_DELETE",
+	GapUndelete: "GAP_UNDELETE",
+	GapOverflow: "GAP_OVERFLOW",
+}
+
+// ChangeEventHeader represents the header fields of a Change Data Capture event.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.change_data_capture.meta/change_data_capture/cdc_event_fields_header.htm
+type ChangeEventHeader struct {
+	EntityName      string   `json:"entityName"`
+	RecordIDs       []string `json:"recordIds"`
+	ChangeType      string   `json:"changeType"`
+	ChangeOrigin    string   `json:"changeOrigin,omitempty"`
+	TransactionKey  string   `json:"transactionKey,omitempty"`
+	SequenceNumber  int      `json:"sequenceNumber,omitempty"`
+	CommitTimestamp int64    `json:"commitTimestamp"`
+	CommitNumber    int64    `json:"commitNumber,omitempty"`
+	CommitUser      string   `json:"commitUser,omitempty"`
+	ChangedFields   []string `json:"changedFields,omitempty"`
+	NulledFields    []string `json:"nulledFields,omitempty"`
+	DiffFields      []string `json:"diffFields,omitempty"`
+}
+
+// CommitTime returns the CommitTimestamp as a time.
+func (h *ChangeEventHeader) CommitTime() time.Time {
+	return time.Unix(0, h.CommitTimestamp*int64(time.Millisecond)).UTC()
+}
+
+// ChangeEvent represents a decoded Change Data Capture event.
+type ChangeEvent struct {
+	// Channel is the channel the event was received on.
+	Channel string
+
+	// ReplayID identifies the event within the channel.
+	ReplayID int64
+
+	// Header describes the change.
+	Header *ChangeEventHeader
+
+	// Fields holds the record fields of the event. Update events only include changed fields.
+	Fields SObject
+
+	// Metadata is the describe metadata of the changed SObject, if it was supplied to the ChangeSubscriber.
+	Metadata *SObjectMetadata
+}
+
+// Decode decodes the event fields into the supplied interface.
+func (e *ChangeEvent) Decode(output interface{}) error {
+	return mapstructure.Decode(e.Fields, output)
+}
+
+// DecodeChangeEvent decodes a Change Data Capture event received on a `/data/` channel.
+func DecodeChangeEvent(event *StreamingEvent) (*ChangeEvent, error) {
+	var payload struct {
+		Header *ChangeEventHeader `json:"ChangeEventHeader"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("event on %s has no %s", event.Channel, changeEventHeaderField)
+	}
+
+	var fields SObject
+	if err := event.DecodePayload(&fields); err != nil {
+		return nil, err
+	}
+	delete(fields, changeEventHeaderField)
+
+	return &ChangeEvent{
+		Channel:  event.Channel,
+		ReplayID: event.ReplayID,
+		Header:   payload.Header,
+		Fields:   fields,
+	}, nil
+}
+
+// ChangeEventChannel returns the Change Data Capture channel of a SObject, such as `/data/AccountChangeEvent`
+// or `/data/Invoice__ChangeEvent`. An empty name returns the channel of all selected objects,
+// `/data/ChangeEvents`.
+func ChangeEventChannel(objectName string) string {
+	switch {
+	case objectName == "":
+		return "/data/ChangeEvents"
+	case strings.HasSuffix(objectName, "__c"):
+		return "/data/" + strings.TrimSuffix(objectName, "c") + "ChangeEvent"
+	}
+	return "/data/" + objectName + "ChangeEvent"
+}
+
+// ChangeHandler processes a ChangeEvent. Returning an error stops the ChangeSubscriber without checkpointing
+// the event, so it is delivered again when the subscriber restarts.
+type ChangeHandler func(event *ChangeEvent) error
+
+// ChangeSubscriber consumes Change Data Capture events for a set of SObjects and checkpoints the replay ID of
+// each event to a ReplayStore once it has been handled.
+//
+// On start, every channel resumes from its checkpointed replay ID so a restarted consumer neither misses nor
+// reprocesses events, provided the checkpoint is still within Salesforce's retention window.
+//
+//	account, _, err := client.Describe("Account")
+//	store, err := force.NewFileReplayStore("replay.json")
+//	subscriber, err := force.NewChangeSubscriber(stream, store, []*force.SObjectMetadata{account})
+//	err = subscriber.Run(func(event *force.ChangeEvent) error {
+//		...
+//	})
+type ChangeSubscriber struct {
+	stream   *StreamingClient
+	store    ReplayStore
+	channels []string
+	metadata map[string]*SObjectMetadata
+	replayID int64
+
+	mu   sync.Mutex
+	subs []*Subscription
+}
+
+// ChangeSubscriberOption is a functional option for configuring a ChangeSubscriber.
+type ChangeSubscriberOption func(*ChangeSubscriber) error
+
+// ChangeReplayFrom sets the replay ID used for channels without a checkpoint. Defaults to ReplayNew.
+func ChangeReplayFrom(replayID int64) ChangeSubscriberOption {
+	return func(s *ChangeSubscriber) error {
+		if replayID < ReplayAll {
+			return fmt.Errorf("invalid replay ID %d", replayID)
+		}
+		s.replayID = replayID
+		return nil
+	}
+}
+
+// NewChangeSubscriber returns a ChangeSubscriber for the SObjects described by the metadata. If no metadata is
+// supplied, it subscribes to the `/data/ChangeEvents` channel of all objects selected for Change Data Capture.
+func NewChangeSubscriber(stream *StreamingClient, store ReplayStore, objects []*SObjectMetadata, opts ...ChangeSubscriberOption) (*ChangeSubscriber, error) {
+	if stream == nil {
+		return nil, fmt.Errorf("streaming client must be defined")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("replay store must be defined")
+	}
+
+	s := &ChangeSubscriber{
+		stream:   stream,
+		store:    store,
+		channels: []string{},
+		metadata: map[string]*SObjectMetadata{},
+		replayID: ReplayNew,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, meta := range objects {
+		if meta == nil || meta.GetName() == "" {
+			return nil, fmt.Errorf("sobject metadata must be defined")
+		}
+		s.channels = append(s.channels, ChangeEventChannel(meta.GetName()))
+		s.metadata[strings.ToLower(meta.GetName())] = meta
+	}
+	if len(s.channels) == 0 {
+		s.channels = append(s.channels, ChangeEventChannel(""))
+	}
+	return s, nil
+}
+
+// Channels returns the channels the ChangeSubscriber subscribes to.
+func (s *ChangeSubscriber) Channels() []string {
+	return s.channels
+}
+
+// Run subscribes to every channel and calls the handler for each event, one at a time, saving the event's replay
+// ID after the handler succeeds. It blocks until the handler returns an error, Close is called or the
+// StreamingClient stops, and returns the error that ended it, if any.
+func (s *ChangeSubscriber) Run(handler ChangeHandler) error {
+	if err := s.subscribe(); err != nil {
+		return err
+	}
+	defer s.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Merge the events of every subscription so the handler is called sequentially.
+	merged := make(chan *StreamingEvent)
+	var wg sync.WaitGroup
+	s.mu.Lock()
+	for _, sub := range s.subs {
+		wg.Add(1)
+		go func(sub *Subscription) {
+			defer wg.Done()
+			for event := range sub.Events() {
+				select {
+				case merged <- event:
+				case <-stop:
+					return
+				}
+			}
+		}(sub)
+	}
+	s.mu.Unlock()
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	for event := range merged {
+		change, err := DecodeChangeEvent(event)
+		if err != nil {
+			return err
+		}
+		change.Metadata = s.metadata[strings.ToLower(change.Header.EntityName)]
+
+		if err := handler(change); err != nil {
+			return err
+		}
+		if err := s.store.Save(event.Channel, event.ReplayID); err != nil {
+			return fmt.Errorf("unable to save replay ID of %s: %v", event.Channel, err)
+		}
+	}
+	return s.stream.Err()
+}
+
+// Close unsubscribes from every channel, causing Run to return.
+func (s *ChangeSubscriber) Close() error {
+	s.mu.Lock()
+	subs := s.subs
+	s.subs = nil
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, sub := range subs {
+		if err := sub.Unsubscribe(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// subscribe subscribes every channel from its checkpoint.
+func (s *ChangeSubscriber) subscribe() error {
+	subs := make([]*Subscription, 0, len(s.channels))
+	for _, channel := range s.channels {
+		replayID, ok, err := s.store.Load(channel)
+		if err != nil {
+			return fmt.Errorf("unable to load replay ID of %s: %v", channel, err)
+		}
+		if !ok {
+			replayID = s.replayID
+		}
+
+		sub, err := s.stream.Subscribe(channel, replayID)
+		if err != nil {
+			for _, prev := range subs {
+				_ = prev.Unsubscribe()
+			}
+			return err
+		}
+		subs = append(subs, sub)
+	}
+
+	s.mu.Lock()
+	s.subs = subs
+	s.mu.Unlock()
+	return nil
+}
@@ -1,9 +1,15 @@
 package force
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/davidji99/simpleresty"
+	"github.com/go-resty/resty/v2"
 	"github.com/mitchellh/mapstructure"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -36,6 +42,11 @@ type Client struct {
 	// Reuse a single struct instead of allocating one for each service on the heap.
 	common service
 
+	// Services used for talking to different parts of the API.
+	Tooling    *ToolingService
+	Reports    *ReportsService
+	Dashboards *DashboardsService
+
 	// Additional HTTP headers
 	customHTTPHeaders map[string]string
 
@@ -54,8 +65,45 @@ type Client struct {
 	// oauthCred
 	oauthCred *OAuthCredentials
 
+	// token is the OAuth token response the client authenticated with, if any.
+	token *TokenResponse
+
+	// clientCredentials, if set, authenticates with the client credentials grant rather than the password grant.
+	clientCredentials bool
+
+	// deviceAuth, if set, authenticates with the device flow.
+	deviceAuth func() (*TokenResponse, error)
+
 	// accessToken
 	accessToken string
+
+	// transport, if set, is used for all HTTP requests including OAuth.
+	transport http.RoundTripper
+
+	// retryCount is the number of times a failed request is retried.
+	retryCount int
+
+	// retryWaitTime is the initial delay before retrying a failed request.
+	retryWaitTime time.Duration
+
+	// cacheMu protects the metadata caches below.
+	cacheMu sync.Mutex
+
+	// describeCache holds SObject metadata used for validation, record types and platform events keyed by
+	// lower-cased object name.
+	describeCache map[string]*SObjectMetadata
+
+	// picklistCache holds the picklist values of record types keyed by lower-cased object name and record type ID.
+	picklistCache map[string]map[string]*UIPicklistValues
+
+	// keyPrefixes maps key prefixes to SObject names. It is loaded from DescribeGlobal on first use.
+	keyPrefixes *KeyPrefixRegistry
+
+	// validateWrites, if set, validates records in Create and Update before sending them.
+	validateWrites bool
+
+	// verifySignature, if set, verifies the signature of the token response against the client secret.
+	verifySignature bool
 }
 
 // service represents the http
@@ -74,8 +122,15 @@ func New(opts ...Option) (*Client, error) {
 		instanceURL:       "",
 		accessToken:       "",
 		oauthCred:         nil,
+		describeCache:     map[string]*SObjectMetadata{},
+		picklistCache:     map[string]map[string]*UIPicklistValues{},
 	}
 
+	c.common.client = c
+	c.Tooling = (*ToolingService)(&c.common)
+	c.Reports = (*ReportsService)(&c.common)
+	c.Dashboards = (*DashboardsService)(&c.common)
+
 	// Define any user custom Client settings
 	if optErr := c.parseOptions(opts...); optErr != nil {
 		return nil, optErr
@@ -102,6 +157,17 @@ func (c *Client) authenticate() error {
 		return nil
 	}
 
+	if c.deviceAuth != nil {
+		r, err := c.deviceAuth()
+		if err != nil {
+			return fmt.Errorf("unable to authenticate: %v", err)
+		}
+		c.token = r
+		c.instanceURL = r.GetInstanceURL()
+		c.accessToken = r.GetAccessToken()
+		return nil
+	}
+
 	// Validate to make sure oauthCred is defined
 	if c.oauthCred == nil {
 		return fmt.Errorf("no OAuth credentials defined")
@@ -118,46 +184,77 @@ func (c *Client) authenticate() error {
 			oauthRespErr.ErrorCode.ToString(), oauthRespErr.Description)
 	}
 
+	if c.verifySignature {
+		if err := r.VerifySignature(c.oauthCred.ClientSecret); err != nil {
+			return fmt.Errorf("unable to authenticate: %v", err)
+		}
+	}
+
+	c.token = r
 	c.instanceURL = r.GetInstanceURL()
 	c.accessToken = r.GetAccessToken()
 
 	return nil
 }
 
-// OAuth submits an OAuth request.
+// OAuth submits an OAuth request using the password grant, or the client credentials grant if the client was
+// configured with ClientCredentials.
 func (c *Client) OAuth() (*TokenResponse, *TokenErrorResponse, error) {
-	return OAuth(c.loginURL, c.oauthCred)
+	if c.clientCredentials {
+		return requestToken(c.oauthClient(), clientCredentialsGrantParams(c.oauthCred.ClientID,
+			c.oauthCred.ClientSecret))
+	}
+	return requestToken(c.oauthClient(), passwordGrantParams(c.oauthCred))
+}
+
+// oauthClient returns a client for the login URL that shares the Client's transport.
+func (c *Client) oauthClient() *simpleresty.Client {
+	oClient := simpleresty.NewWithBaseURL(c.loginURL)
+	if c.transport != nil {
+		oClient.SetTransport(c.transport)
+	}
+	return oClient
 }
 
 // OAuth submits an OAuth request.
 func OAuth(loginURL string, o *OAuthCredentials) (*TokenResponse, *TokenErrorResponse, error) {
-	var tokenResponse *TokenResponse
-	var tokenErrResponse *TokenErrorResponse
-	oClient := simpleresty.NewWithBaseURL(loginURL)
-	url := oClient.RequestURL("/services/oauth2/token")
-
-	_, postErr := oClient.R().
-		SetFormData(map[string]string{
-			"grant_type":    "password",
-			"client_id":     o.ClientID,
-			"client_secret": o.ClientSecret,
-			"username":      o.Username,
-			"password":      o.Password,
-		}).
-		SetHeaders(map[string]string{"Accept": MediaTypeJSON, "Content-Type": FormURLEncodedHeader}).
-		SetResult(&tokenResponse).
-		SetError(&tokenErrResponse).
-		Post(url)
-
-	return tokenResponse, tokenErrResponse, postErr
+	return OAuthCustom(loginURL, passwordGrantParams(o))
 }
 
 // OAuthCustom is the same as the OAuth function but provides the ability to pass in the entire
 // form parameters.
 func OAuthCustom(loginURL string, params map[string]string) (*TokenResponse, *TokenErrorResponse, error) {
+	return requestToken(simpleresty.NewWithBaseURL(loginURL), params)
+}
+
+// OAuthClientCredentials submits an OAuth request using the client credentials grant, which authenticates as the
+// integration user of the connected app. The login URL must be the org's My Domain URL.
+func OAuthClientCredentials(loginURL, clientID, clientSecret string) (*TokenResponse, *TokenErrorResponse, error) {
+	return OAuthCustom(loginURL, clientCredentialsGrantParams(clientID, clientSecret))
+}
+
+func clientCredentialsGrantParams(clientID, clientSecret string) map[string]string {
+	return map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+	}
+}
+
+func passwordGrantParams(o *OAuthCredentials) map[string]string {
+	return map[string]string{
+		"grant_type":    "password",
+		"client_id":     o.ClientID,
+		"client_secret": o.ClientSecret,
+		"username":      o.Username,
+		"password":      o.Password,
+	}
+}
+
+// requestToken posts the form parameters to the token endpoint of the supplied login client.
+func requestToken(oClient *simpleresty.Client, params map[string]string) (*TokenResponse, *TokenErrorResponse, error) {
 	var tokenResponse *TokenResponse
 	var tokenErrResponse *TokenErrorResponse
-	oClient := simpleresty.NewWithBaseURL(loginURL)
 	url := oClient.RequestURL("/services/oauth2/token")
 
 	_, postErr := oClient.R().
@@ -180,6 +277,86 @@ func (c *Client) setupClient(accessToken string) {
 		SetHeaders(c.customHTTPHeaders).
 		SetTimeout(5 * time.Minute).
 		SetAllowGetMethodPayload(true)
+
+	if c.transport != nil {
+		c.http.SetTransport(c.transport)
+	}
+
+	// Resty counts the first attempt as one of its retries.
+	if c.retryCount > 0 {
+		c.http.SetRetryCount(c.retryCount + 1).
+			SetRetryWaitTime(c.retryWaitTime).
+			AddRetryCondition(retryable)
+	}
+}
+
+// retryable reports whether a request should be retried. Idempotent requests are retried on network errors, rate
+// limiting and server errors. Other requests may already have taken effect after a server error or a timeout, so
+// they are only retried when rate limited or when the connection failed before anything was sent.
+func retryable(r *resty.Response, err error) bool {
+	if r == nil || r.Request == nil || r.Request.Context().Value(noRetryKey{}) != nil {
+		return false
+	}
+	if err != nil {
+		return idempotent(r.Request.Method) || dialFailed(err)
+	}
+	if r.StatusCode() == http.StatusTooManyRequests {
+		return true
+	}
+	return r.StatusCode() >= http.StatusInternalServerError && idempotent(r.Request.Method)
+}
+
+// noRetryKey marks the context of a request that must never be retried, such as one with a streamed body.
+type noRetryKey struct{}
+
+// withoutRetries returns a context that disables retries for the request it is set on.
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// idempotent reports whether repeating a request with the method has the same effect as sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// dialFailed reports whether err is a failure to connect, which happens before any of the request is sent.
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// InstanceURL returns the Salesforce instance URL the client sends requests to.
+func (c *Client) InstanceURL() string {
+	return c.instanceURL
+}
+
+// AccessToken returns the access token used to authenticate requests.
+func (c *Client) AccessToken() string {
+	return c.accessToken
+}
+
+// APIVersion returns the Force API version used by the client, such as `v50.0`.
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// Transport returns the http.RoundTripper set by the Transport option, or nil if the default is used.
+func (c *Client) Transport() http.RoundTripper {
+	return c.transport
+}
+
+// UserAgent returns the user agent sent with every request.
+func (c *Client) UserAgent() string {
+	return c.userAgent
+}
+
+// Token returns the OAuth token response the client authenticated with, or nil if it was given an access token.
+func (c *Client) Token() *TokenResponse {
+	return c.token
 }
 
 // Describe gets the metadata regarding a SObject.
@@ -195,11 +372,39 @@ func (c *Client) Describe(apiName string) (*SObjectMetadata, *simpleresty.Respon
 	return result, response, getErr
 }
 
+// DescribeGlobalResult represents the SObjects available in an organization.
+type DescribeGlobalResult struct {
+	Encoding     string `json:"encoding"`
+	MaxBatchSize int    `json:"maxBatchSize"`
+
+	// SObjects hold the basic metadata of each SObject, without its fields, child relationships or record types.
+	SObjects []*SObjectMetadata `json:"sobjects"`
+}
+
+// DescribeGlobal lists the SObjects available in the organization along with their basic metadata.
+func (c *Client) DescribeGlobal() (*DescribeGlobalResult, *simpleresty.Response, error) {
+	var result *DescribeGlobalResult
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects", c.apiVersion))
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, getErr
+}
+
 // Create a new SObject.
 //
 // This request does not return the newly created object regardless of status.
 // Rather it returns a JSON result of SObjectCreateResult.
 func (c *Client) Create(objectName string, opts interface{}) (*SObjectCreateResult, *simpleresty.Response, error) {
+	if c.validateWrites {
+		if err := c.validate(objectName, opts, true); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	var result *SObjectCreateResult
 	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects/%s", c.apiVersion, objectName))
 
@@ -207,10 +412,41 @@ func (c *Client) Create(objectName string, opts interface{}) (*SObjectCreateResu
 	return result, response, err
 }
 
+// SObjectGetRequest represents the optional parameters when retrieving a SObject.
+type SObjectGetRequest struct {
+	Fields string `url:"fields,omitempty"`
+}
+
+// Get retrieves an existing SObject by its ID.
+//
+// If any fields are specified, only those fields are returned. Otherwise, all fields are returned.
+func (c *Client) Get(objectName, objectId string, fields ...string) (SObject, *simpleresty.Response, error) {
+	var result SObject
+	urlStr, urlStrErr := c.http.RequestURLWithQueryParams(
+		fmt.Sprintf("/services/data/%s/sobjects/%s/%s", c.apiVersion, objectName, objectId),
+		&SObjectGetRequest{Fields: strings.Join(fields, ",")})
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, response, getErr
+	}
+
+	return result, response, nil
+}
+
 // Update an existing SObject.
 //
 // The request does not return any body if the PATCH is successful.
 func (c *Client) Update(objectName, objectId string, opts interface{}) (*simpleresty.Response, error) {
+	if c.validateWrites {
+		if err := c.validate(objectName, opts, false); err != nil {
+			return nil, err
+		}
+	}
+
 	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects/%s/%s", c.apiVersion, objectName, objectId))
 
 	response, err := c.http.Patch(urlStr, nil, opts)
@@ -268,6 +504,19 @@ func (c *Client) Query(q *QueryRequest) (*QueryResult, *simpleresty.Response, er
 	return result, response, nil
 }
 
+// QueryMore retrieves the next page of results using the NextRecordsURL of a previous QueryResult.
+func (c *Client) QueryMore(nextRecordsURL string) (*QueryResult, *simpleresty.Response, error) {
+	var result *QueryResult
+	urlStr := c.http.RequestURL(nextRecordsURL)
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
 // QueryAndDecode executes a request to find SObjects and unmarshalls the result into the supplied interface.
 func (c *Client) QueryAndDecode(q *QueryRequest, output interface{}) (*QueryResult, *simpleresty.Response, error) {
 	result, response, queryErr := c.Query(q)
@@ -300,7 +549,23 @@ func (c *Client) GetBaseSObjectQuery(objectName string) (string, error) {
 		return "", describeErr
 	}
 
+	return baseSObjectQuery(sobject, objectName), nil
+}
+
+// baseSObjectQuery returns a query selecting every field of a SObject.
+func baseSObjectQuery(sobject *SObjectMetadata, objectName string) string {
 	// The space after the second '%s' is required. Do not remove!
-	queryString := fmt.Sprintf("select %s from %s ", sobject.GetFieldNamesString(), objectName)
-	return queryString, nil
+	return fmt.Sprintf("select %s from %s ", sobject.GetFieldNamesString(), objectName)
 }
+
+// Bool is a helper routine that allocates a new bool value
+// to store v and returns a pointer to it.
+func Bool(v bool) *bool { return &v }
+
+// Int is a helper routine that allocates a new int value
+// to store v and returns a pointer to it.
+func Int(v int) *int { return &v }
+
+// String is a helper routine that allocates a new string value
+// to store v and returns a pointer to it.
+func String(v string) *string { return &v }
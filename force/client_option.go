@@ -3,8 +3,10 @@ package force
 import (
 	"fmt"
 	"github.com/davidji99/simpleresty"
+	"net/http"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Option is a functional option for configuring the API client.
@@ -18,6 +20,51 @@ func HTTP(http *simpleresty.Client) Option {
 	}
 }
 
+// Transport sets the http.RoundTripper used for all requests, including OAuth.
+//
+// Pass a *Recorder to record interactions to a cassette or replay them offline.
+func Transport(t http.RoundTripper) Option {
+	return func(c *Client) error {
+		c.transport = t
+		return nil
+	}
+}
+
+// Retry retries failed requests up to count times. The delay between attempts starts at waitTime and backs off
+// exponentially.
+//
+// GET, HEAD, PUT and DELETE requests are retried on network errors, rate limiting and server errors. Other requests,
+// such as the POST of Create, may already have been committed when a server error or timeout occurs, so they are
+// only retried when rate limited or when the connection failed before the request was sent.
+func Retry(count int, waitTime time.Duration) Option {
+	return func(c *Client) error {
+		if count < 0 || waitTime < 0 {
+			return fmt.Errorf("retry count and wait time cannot be negative")
+		}
+		c.retryCount = count
+		c.retryWaitTime = waitTime
+		return nil
+	}
+}
+
+// ValidateWrites validates records against their SObject's describe metadata in Create and Update before sending
+// them, returning a *ValidationError instead of making the request. Describes are cached by the client.
+func ValidateWrites() Option {
+	return func(c *Client) error {
+		c.validateWrites = true
+		return nil
+	}
+}
+
+// VerifyTokenSignature verifies the signature of the token response against the client secret when authenticating
+// with OAuthCred or ClientCredentials, failing authentication if it doesn't match.
+func VerifyTokenSignature() Option {
+	return func(c *Client) error {
+		c.verifySignature = true
+		return nil
+	}
+}
+
 // UserAgent allows overriding of the default User Agent.
 func UserAgent(userAgent string) Option {
 	return func(c *Client) error {
@@ -82,6 +129,20 @@ func AccessToken(t string) Option {
 	}
 }
 
+// OAuthToken authenticates with the access token and instance URL of a token response, such as the result of
+// WebServerFlow.Exchange.
+func OAuthToken(token *TokenResponse) Option {
+	return func(c *Client) error {
+		if token == nil || token.GetAccessToken() == "" || token.GetInstanceURL() == "" {
+			return fmt.Errorf("token response must have an access token and instance URL")
+		}
+		c.token = token
+		c.accessToken = token.GetAccessToken()
+		c.instanceURL = token.GetInstanceURL()
+		return nil
+	}
+}
+
 // OAuthCred sets the credentials needed for OAuth.
 func OAuthCred(username, password, clientID, clientSecret string) Option {
 	return func(c *Client) error {
@@ -94,10 +155,39 @@ func OAuthCred(username, password, clientID, clientSecret string) Option {
 		}
 
 		c.oauthCred = &OAuthCredentials{
-			ClientID:      clientID,
-			ClientSecret:  clientSecret,
-			Username:      username,
-			Password:      password,
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Username:     username,
+			Password:     password,
+		}
+		c.clientCredentials = false
+		return nil
+	}
+}
+
+// ClientCredentials sets the credentials of a connected app to authenticate with the OAuth client credentials
+// grant, which runs as the app's integration user. LoginURL must be set to the org's My Domain URL.
+func ClientCredentials(clientID, clientSecret string) Option {
+	return func(c *Client) error {
+		if strings.TrimSpace(clientID) == "" || strings.TrimSpace(clientSecret) == "" {
+			return fmt.Errorf("client ID and secret cannot be empty")
+		}
+
+		c.oauthCred = &OAuthCredentials{ClientID: clientID, ClientSecret: clientSecret}
+		c.clientCredentials = true
+		return nil
+	}
+}
+
+// DeviceAuth authenticates with the OAuth device flow. The prompt is called with the user code and verification
+// URI to present to the user, after which the token endpoint is polled until the user approves access.
+func DeviceAuth(flow *DeviceFlow, prompt func(*DeviceAuthorization) error) Option {
+	return func(c *Client) error {
+		if flow == nil || prompt == nil {
+			return fmt.Errorf("device flow and prompt must be defined")
+		}
+		c.deviceAuth = func() (*TokenResponse, error) {
+			return flow.Run(prompt)
 		}
 		return nil
 	}
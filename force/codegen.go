@@ -0,0 +1,106 @@
+package force
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+// GenerateStructs generates gofmt-ed Go source declaring a struct per SObject, with a field per SObject field
+// typed by FieldDataType.ConvertToGoDataType and tagged with its API name. Checkbox and number fields are pointers
+// so that false and 0 are written while unset fields are omitted, and compound address and location fields are
+// maps. The metadata can come from Client.Describe or from a Snapshot:
+//
+//	// Invoice is generated from the describe of the Invoice__c SObject.
+//	type Invoice struct {
+//		ID        string       `json:"Id,omitempty"`
+//		Amount    *json.Number `json:"Amount__c,omitempty"`
+//		Paid      *bool        `json:"Paid__c,omitempty"`
+//		AccountID string       `json:"Account__c,omitempty"`
+//	}
+func GenerateStructs(packageName string, sobjects ...*SObjectMetadata) ([]byte, error) {
+	var body bytes.Buffer
+	usesJSON := false
+	for _, meta := range sobjects {
+		fmt.Fprintf(&body, "\n// %s is generated from the describe of the %s SObject.\n", goIdentifier(meta.GetName()),
+			meta.GetName())
+		fmt.Fprintf(&body, "type %s struct {\n", goIdentifier(meta.GetName()))
+
+		seen := map[string]int{}
+		for _, f := range meta.Fields {
+			name := goIdentifier(f.GetName())
+			if f.Type == FieldDataTypes.Reference && strings.HasSuffix(name, "Id") {
+				name = strings.TrimSuffix(name, "Id") + "ID"
+			} else if f.Type == FieldDataTypes.Reference && !strings.HasSuffix(name, "ID") {
+				name += "ID"
+			}
+			if seen[name]++; seen[name] > 1 {
+				name = fmt.Sprintf("%s%d", name, seen[name])
+			}
+
+			goType := structFieldType(f.Type)
+			if strings.Contains(goType, "json.") {
+				usesJSON = true
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:\"%s,omitempty\"`\n", name, goType, f.GetName())
+		}
+		body.WriteString("}\n")
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by force-go. DO NOT EDIT.\n\npackage %s\n", packageName)
+	if usesJSON {
+		src.WriteString("\nimport \"encoding/json\"\n")
+	}
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated structs: %v", err)
+	}
+	return formatted, nil
+}
+
+// structFieldType returns the Go type of a generated struct field.
+func structFieldType(t FieldDataType) string {
+	switch t {
+	case FieldDataTypes.Address, FieldDataTypes.Location:
+		return "map[string]interface{}"
+	case FieldDataTypes.Boolean, FieldDataTypes.Int, FieldDataTypes.Long, FieldDataTypes.Double,
+		FieldDataTypes.Currency, FieldDataTypes.Percent:
+		return "*" + t.ConvertToGoDataType()
+	}
+	return t.ConvertToGoDataType()
+}
+
+// goIdentifier converts a SObject or field API name, such as `Billing_Country__c`, to an exported Go identifier,
+// such as `BillingCountry`. A name that is only `Id` becomes `ID`.
+func goIdentifier(apiName string) string {
+	name := apiName
+	for _, suffix := range []string{"__c", "__r", "__e", "__mdt", "__x", "__b"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if name == "Id" {
+		return "ID"
+	}
+
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package force
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DateFormat is the layout Salesforce uses for date fields.
+	DateFormat = "2006-01-02"
+
+	// DateTimeFormat is the layout Salesforce uses for datetime fields.
+	DateTimeFormat = "2006-01-02T15:04:05.000Z0700"
+
+	// TimeFormat is the layout Salesforce uses for time fields.
+	TimeFormat = "15:04:05.000Z"
+)
+
+// DefaultDateLayouts are the layouts CoerceFieldValue accepts for date and datetime fields.
+var DefaultDateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006",
+	"2006/01/02",
+	"02-Jan-2006",
+	"Jan 2, 2006",
+}
+
+// DefaultDateTimeLayouts are the layouts CoerceFieldValue accepts for datetime fields, in addition to
+// DefaultDateLayouts.
+var DefaultDateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"1/2/2006 3:04 PM",
+}
+
+// DefaultTimeLayouts are the layouts CoerceFieldValue accepts for time fields.
+var DefaultTimeLayouts = []string{
+	"15:04:05.000Z",
+	"15:04:05.000",
+	"15:04:05",
+	"15:04",
+	"3:04 PM",
+	"3:04PM",
+}
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9]{15}([a-zA-Z0-9]{3})?$`)
+
+// CoerceFieldValue converts the string form of a value, typically read from a CSV file, into the JSON value
+// expected by Salesforce for the field's FieldDataType.
+//
+// Dates and datetimes are parsed with DefaultDateLayouts and DefaultDateTimeLayouts followed by any additional
+// layouts. Datetimes without a zone are treated as UTC. Numbers may use ',' only as a thousands separator.
+// Multipicklist values are separated by ';', as picklist values may themselves contain ','. Picklist values are
+// matched case-insensitively against the field's values and labels, and rejected if the picklist is restricted and
+// nothing matches. An empty string returns nil.
+func CoerceFieldValue(field *SObjectFieldMetadata, raw string, layouts ...string) (interface{}, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil, nil
+	}
+
+	switch field.Type {
+	case FieldDataTypes.Boolean:
+		switch strings.ToLower(value) {
+		case "true", "t", "yes", "y", "1":
+			return true, nil
+		case "false", "f", "no", "n", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("'%s' is not a valid boolean", raw)
+	case FieldDataTypes.Int:
+		i, err := strconv.Atoi(stripNumber(value))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid integer", raw)
+		}
+		return i, nil
+	case FieldDataTypes.Long:
+		i, err := strconv.ParseInt(stripNumber(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid long", raw)
+		}
+		return i, nil
+	case FieldDataTypes.Double, FieldDataTypes.Currency, FieldDataTypes.Percent:
+		f, err := strconv.ParseFloat(stripNumber(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid number", raw)
+		}
+		return f, nil
+	case FieldDataTypes.Date:
+		t, err := parseTime(value, append(append([]string{}, DefaultDateLayouts...), layouts...))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid date", raw)
+		}
+		return t.Format(DateFormat), nil
+	case FieldDataTypes.DateTime:
+		dateTimeLayouts := append(append([]string{}, DefaultDateTimeLayouts...), DefaultDateLayouts...)
+		t, err := parseTime(value, append(dateTimeLayouts, layouts...))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid datetime", raw)
+		}
+		return t.UTC().Format(DateTimeFormat), nil
+	case FieldDataTypes.Time:
+		t, err := parseTime(strings.ToUpper(value), append(append([]string{}, DefaultTimeLayouts...), layouts...))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid time", raw)
+		}
+		return t.Format(TimeFormat), nil
+	case FieldDataTypes.Picklist:
+		return coercePicklistValue(field, value)
+	case FieldDataTypes.Multipicklist:
+		seen := map[string]bool{}
+		values := make([]string, 0)
+		for _, part := range strings.Split(value, ";") {
+			v, err := coercePicklistValue(field, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := v.(string); ok && s != "" && !seen[s] {
+				seen[s] = true
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ";"), nil
+	case FieldDataTypes.Reference, FieldDataTypes.ID:
+		if !idPattern.MatchString(value) {
+			return nil, fmt.Errorf("'%s' is not a valid Salesforce ID", raw)
+		}
+		return value, nil
+	}
+
+	// Text based fields keep their original, untrimmed value.
+	return raw, nil
+}
+
+// coercePicklistValue returns the canonical value of a picklist entry matching the value or label.
+func coercePicklistValue(field *SObjectFieldMetadata, value string) (interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, entry := range field.PicklistValues {
+		if strings.EqualFold(entry.GetValue(), value) || strings.EqualFold(entry.GetLabel(), value) {
+			return entry.GetValue(), nil
+		}
+	}
+	if field.GetRestrictedPicklist() {
+		return nil, fmt.Errorf("'%s' is not a valid value for restricted picklist %s", value, field.GetName())
+	}
+	return value, nil
+}
+
+// thousandsPattern matches a number grouped with ',' thousands separators, such as `-1,234,567.89`.
+var thousandsPattern = regexp.MustCompile(`^[-+]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// stripNumber removes currency symbols, percent signs and ',' thousands separators. A ',' that isn't a thousands
+// separator, such as the decimal comma of `1,5`, is kept so that parsing the number fails rather than misreading it.
+func stripNumber(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch r {
+		case '$', '€', '£', '%', ' ':
+			return -1
+		}
+		return r
+	}, value)
+	if thousandsPattern.MatchString(value) {
+		return strings.Replace(value, ",", "", -1)
+	}
+	return value
+}
+
+func parseTime(value string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse '%s'", value)
+}
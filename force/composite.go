@@ -0,0 +1,139 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"strings"
+)
+
+// MaxCollectionSize is the maximum number of records in a single sObject collection request.
+const MaxCollectionSize = 200
+
+// SObjectCollectionRequest represents the body of a sObject collection create, update or upsert request.
+type SObjectCollectionRequest struct {
+	AllOrNone bool      `json:"allOrNone"`
+	Records   []SObject `json:"records"`
+}
+
+// SObjectCollectionResult represents the result of a single record in a sObject collection request.
+// Results are returned in the same order as the records in the request.
+type SObjectCollectionResult struct {
+	ID      string          `json:"id,omitempty"`
+	Success bool            `json:"success"`
+	Created *bool           `json:"created,omitempty"`
+	Errors  []*SObjectError `json:"errors,omitempty"`
+}
+
+// SObjectError represents an error returned for a single record.
+type SObjectError struct {
+	StatusCode string   `json:"statusCode,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+// Error returns the status code and message of a SObjectError.
+func (e *SObjectError) Error() string {
+	if len(e.Fields) > 0 {
+		return fmt.Sprintf("%s: %s (%s)", e.StatusCode, e.Message, strings.Join(e.Fields, ", "))
+	}
+	return fmt.Sprintf("%s: %s", e.StatusCode, e.Message)
+}
+
+// CreateCollection creates up to MaxCollectionSize records of a SObject in a single request.
+//
+// If allOrNone is true, no records are created unless every record succeeds.
+func (c *Client) CreateCollection(objectName string, records []SObject, allOrNone bool) ([]*SObjectCollectionResult, *simpleresty.Response, error) {
+	if err := checkCollectionSize(len(records)); err != nil {
+		return nil, nil, err
+	}
+
+	var result []*SObjectCollectionResult
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/composite/sobjects", c.apiVersion))
+	body := &SObjectCollectionRequest{AllOrNone: allOrNone, Records: withTypeAttribute(objectName, records)}
+
+	response, err := c.http.Post(urlStr, &result, body)
+	return result, response, err
+}
+
+// UpdateCollection updates up to MaxCollectionSize records of a SObject in a single request.
+// Each record must include its `Id`.
+//
+// If allOrNone is true, no records are updated unless every record succeeds.
+func (c *Client) UpdateCollection(objectName string, records []SObject, allOrNone bool) ([]*SObjectCollectionResult, *simpleresty.Response, error) {
+	if err := checkCollectionSize(len(records)); err != nil {
+		return nil, nil, err
+	}
+
+	var result []*SObjectCollectionResult
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/composite/sobjects", c.apiVersion))
+	body := &SObjectCollectionRequest{AllOrNone: allOrNone, Records: withTypeAttribute(objectName, records)}
+
+	response, err := c.http.Patch(urlStr, &result, body)
+	return result, response, err
+}
+
+// UpsertCollection creates or updates up to MaxCollectionSize records of a SObject in a single request,
+// matching existing records on the external ID field.
+//
+// If allOrNone is true, no records are written unless every record succeeds.
+func (c *Client) UpsertCollection(objectName, externalIDField string, records []SObject, allOrNone bool) ([]*SObjectCollectionResult, *simpleresty.Response, error) {
+	if err := checkCollectionSize(len(records)); err != nil {
+		return nil, nil, err
+	}
+
+	var result []*SObjectCollectionResult
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/composite/sobjects/%s/%s",
+		c.apiVersion, objectName, externalIDField))
+	body := &SObjectCollectionRequest{AllOrNone: allOrNone, Records: withTypeAttribute(objectName, records)}
+
+	response, err := c.http.Patch(urlStr, &result, body)
+	return result, response, err
+}
+
+// DestroyCollectionRequest represents the query parameters of a sObject collection delete request.
+type DestroyCollectionRequest struct {
+	IDs       string `url:"ids"`
+	AllOrNone bool   `url:"allOrNone"`
+}
+
+// DestroyCollection deletes up to MaxCollectionSize records, of any SObject, in a single request.
+//
+// If allOrNone is true, no records are deleted unless every record succeeds.
+func (c *Client) DestroyCollection(ids []string, allOrNone bool) ([]*SObjectCollectionResult, *simpleresty.Response, error) {
+	if err := checkCollectionSize(len(ids)); err != nil {
+		return nil, nil, err
+	}
+
+	var result []*SObjectCollectionResult
+	urlStr, urlStrErr := c.http.RequestURLWithQueryParams(
+		fmt.Sprintf("/services/data/%s/composite/sobjects", c.apiVersion),
+		&DestroyCollectionRequest{IDs: strings.Join(ids, ","), AllOrNone: allOrNone})
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, err := c.http.Delete(urlStr, &result, nil)
+	return result, response, err
+}
+
+func checkCollectionSize(n int) error {
+	if n > MaxCollectionSize {
+		return fmt.Errorf("a collection request cannot contain more than %d records, got %d", MaxCollectionSize, n)
+	}
+	return nil
+}
+
+// withTypeAttribute returns copies of the records with `attributes.type` set to the object name, as required
+// by sObject collection requests.
+func withTypeAttribute(objectName string, records []SObject) []SObject {
+	out := make([]SObject, 0, len(records))
+	for _, r := range records {
+		record := make(SObject, len(r)+1)
+		for k, v := range r {
+			record[k] = v
+		}
+		record["attributes"] = map[string]interface{}{"type": objectName}
+		out = append(out, record)
+	}
+	return out
+}
@@ -0,0 +1,315 @@
+package force
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RecordWriter writes query records to an output format.
+type RecordWriter interface {
+	// Write writes a single record.
+	Write(record SObject) error
+
+	// Flush writes any buffered data to the underlying io.Writer.
+	Flush() error
+}
+
+// CSVWriter writes records as CSV rows, flattening parent relationships such as `Account.Owner.Name`
+// into dotted columns and dropping `attributes`.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []string
+
+	// headerWritten is set once the header row has been written.
+	headerWritten bool
+}
+
+// NewCSVWriter returns a CSVWriter with the supplied columns, typically the result of SelectFields.
+//
+// If no columns are supplied, they are inferred from the first record and sorted alphabetically. As a null
+// relationship of the first record is inferred as a single column, such as `Account` rather than `Account.Name`,
+// supply the columns when relationships may be null. Column names are matched case-insensitively against record
+// fields.
+func NewCSVWriter(w io.Writer, columns []string) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// Columns returns the CSV columns. It returns nil if columns are inferred and no record has been written yet.
+func (c *CSVWriter) Columns() []string {
+	return c.columns
+}
+
+// Write writes a record as a CSV row, preceded by the header row on the first call.
+func (c *CSVWriter) Write(record SObject) error {
+	flat := FlattenRecord(record)
+	if len(c.columns) == 0 {
+		c.columns = make([]string, 0, len(flat))
+		for k := range flat {
+			c.columns = append(c.columns, k)
+		}
+		sort.Strings(c.columns)
+	}
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	lower := make(map[string]interface{}, len(flat))
+	for k, v := range flat {
+		lower[strings.ToLower(k)] = v
+	}
+
+	row := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		row[i] = FormatValue(lower[strings.ToLower(col)])
+	}
+	return c.w.Write(row)
+}
+
+// Flush writes any buffered rows. The header row is written even if no records were written,
+// provided the columns are known.
+func (c *CSVWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) writeHeader() error {
+	if c.headerWritten || len(c.columns) == 0 {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(c.columns)
+}
+
+// NDJSONWriter writes records as newline delimited JSON, dropping `attributes` but keeping
+// parent relationships nested.
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a NDJSONWriter.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write writes a record as a single line of JSON.
+func (n *NDJSONWriter) Write(record SObject) error {
+	return n.enc.Encode(StripAttributes(record))
+}
+
+// Flush is a no-op as every record is written immediately.
+func (n *NDJSONWriter) Flush() error {
+	return nil
+}
+
+// WriteQueryResult writes every record of a single QueryResult and flushes the writer.
+func WriteQueryResult(w RecordWriter, result *QueryResult) error {
+	for _, record := range result.Records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// WriteQueryIterator writes every remaining record of a QueryIterator, fetching additional pages as needed,
+// and flushes the writer.
+func WriteQueryIterator(w RecordWriter, iter *QueryIterator) error {
+	for iter.Next() {
+		if err := w.Write(iter.Record()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// FlattenRecord converts nested parent relationship objects into dotted keys, such as `Account.Owner.Name`,
+// and drops `attributes` and child relationship subquery results. A null relationship can't be told apart from a
+// null field without the describe, so it is kept as a nil value under the relationship name, such as `Account`.
+func FlattenRecord(record SObject) map[string]interface{} {
+	flat := map[string]interface{}{}
+	flattenInto(flat, "", record)
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, prefix string, record map[string]interface{}) {
+	for k, v := range record {
+		if k == "attributes" {
+			continue
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if _, isSubquery := t["records"]; isSubquery {
+				continue
+			}
+			flattenInto(flat, prefix+k+".", t)
+		case SObject:
+			flattenInto(flat, prefix+k+".", t)
+		default:
+			flat[prefix+k] = v
+		}
+	}
+}
+
+// StripAttributes returns a copy of the record without `attributes`, including on nested relationships.
+func StripAttributes(record SObject) SObject {
+	out := make(SObject, len(record))
+	for k, v := range record {
+		if k == "attributes" {
+			continue
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = map[string]interface{}(StripAttributes(t))
+		case SObject:
+			v = StripAttributes(t)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// FormatValue converts a decoded JSON value into its string form for tabular output. Nil values become an
+// empty string and numbers are never written in exponent form.
+func FormatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case json.Number:
+		return t.String()
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// SelectFields returns the columns of a SOQL select list in order, such as `Id` and `Account.Owner.Name`.
+//
+// Child relationship subqueries are skipped. Function calls use their alias if present. Otherwise aggregate functions
+// use the `exprN` name Salesforce assigns to them, and functions such as toLabel, FORMAT and convertCurrency use the
+// name of the field they wrap. It returns nil if the select list uses FIELDS(), as its columns depend on the SObject.
+func SelectFields(soql string) []string {
+	start := indexKeyword(soql, "select", 0)
+	if start < 0 {
+		return nil
+	}
+	start += len("select")
+
+	// Find the top level FROM, ignoring any inside subqueries.
+	end, depth := -1, 0
+	for i := start; i < len(soql) && end < 0; i++ {
+		switch soql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && indexKeyword(soql[i:], "from", 0) == 0 && unicode.IsSpace(rune(soql[i-1])) {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+
+	fields := make([]string, 0)
+	expr := 0
+	for _, item := range splitTopLevel(soql[start:end]) {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+			continue
+		case strings.HasPrefix(item, "("):
+			continue
+		case strings.Contains(item, "("):
+			// Function call, optionally followed by an alias.
+			function := strings.ToLower(strings.TrimSpace(item[:strings.Index(item, "(")]))
+			closing := strings.LastIndex(item, ")")
+			alias := strings.TrimSpace(item[closing+1:])
+			switch {
+			case function == "fields":
+				return nil
+			case alias != "":
+				fields = append(fields, alias)
+			case aggregateFunctions[function]:
+				fields = append(fields, fmt.Sprintf("expr%d", expr))
+				expr++
+			default:
+				fields = append(fields, wrappedField(item))
+			}
+		default:
+			fields = append(fields, strings.Fields(item)[0])
+		}
+	}
+	return fields
+}
+
+// aggregateFunctions are the SOQL aggregate functions, keyed by lower-cased name.
+var aggregateFunctions = map[string]bool{
+	"avg": true, "count": true, "count_distinct": true, "max": true, "min": true, "sum": true,
+}
+
+// wrappedField returns the field wrapped by a function call such as `toLabel(Status)`, unwrapping nested calls.
+func wrappedField(item string) string {
+	for strings.Contains(item, "(") {
+		item = item[strings.Index(item, "(")+1 : strings.LastIndex(item, ")")]
+	}
+	return strings.TrimSpace(item)
+}
+
+// splitTopLevel splits a select list on commas that are not inside parentheses.
+func splitTopLevel(s string) []string {
+	parts := make([]string, 0)
+	depth, last := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// indexKeyword returns the index of a case-insensitive keyword in s at or after from, or -1.
+// The keyword must be followed by whitespace or the end of s.
+func indexKeyword(s, keyword string, from int) int {
+	lower := strings.ToLower(s)
+	for i := from; i+len(keyword) <= len(s); i++ {
+		if lower[i:i+len(keyword)] != keyword {
+			continue
+		}
+		if i+len(keyword) == len(s) || unicode.IsSpace(rune(s[i+len(keyword)])) {
+			return i
+		}
+	}
+	return -1
+}
@@ -9,42 +9,60 @@ type FieldDataType string
 var FieldDataTypes = struct {
 	Address                    FieldDataType
 	AnyType                    FieldDataType
+	Base64                     FieldDataType
+	Boolean                    FieldDataType
 	Calculated                 FieldDataType
 	Combobox                   FieldDataType
 	Currency                   FieldDataType
 	DataCategoryGroupReference FieldDataType
+	Date                       FieldDataType
+	DateTime                   FieldDataType
+	Double                     FieldDataType
 	Email                      FieldDataType
 	Encryptedstring            FieldDataType
 	ID                         FieldDataType
+	Int                        FieldDataType
 	JunctionIdList             FieldDataType
 	Location                   FieldDataType
+	Long                       FieldDataType
 	Masterrecord               FieldDataType
 	Multipicklist              FieldDataType
 	Percent                    FieldDataType
 	Phone                      FieldDataType
 	Picklist                   FieldDataType
 	Reference                  FieldDataType
+	String                     FieldDataType
 	Textarea                   FieldDataType
+	Time                       FieldDataType
 	URL                        FieldDataType
 }{
 	Address:                    "address",
 	AnyType:                    "anyType",
+	Base64:                     "base64",
+	Boolean:                    "boolean",
 	Calculated:                 "calculated",
 	Combobox:                   "combobox",
 	Currency:                   "currency",
 	DataCategoryGroupReference: "DataCategoryGroupReference",
+	Date:                       "date",
+	DateTime:                   "datetime",
+	Double:                     "double",
 	Email:                      "email",
 	Encryptedstring:            "encryptedstring",
 	ID:                         "ID",
+	Int:                        "int",
 	JunctionIdList:             "JunctionIdList",
 	Location:                   "location",
+	Long:                       "long",
 	Masterrecord:               "masterrecord",
 	Multipicklist:              "multipicklist",
 	Percent:                    "percent",
 	Phone:                      "phone",
 	Picklist:                   "picklist",
 	Reference:                  "reference",
+	String:                     "string",
 	Textarea:                   "textarea",
+	Time:                       "time",
 	URL:                        "url",
 }
 
@@ -54,22 +72,31 @@ var FieldDataTypes = struct {
 var FieldDataTypeGoDataTypeMapping = map[FieldDataType]string{
 	FieldDataTypes.Address:                    "string",
 	FieldDataTypes.AnyType:                    "interface{}",
+	FieldDataTypes.Base64:                     "string",
+	FieldDataTypes.Boolean:                    "bool",
 	FieldDataTypes.Calculated:                 "string",
 	FieldDataTypes.Combobox:                   "string",
 	FieldDataTypes.Currency:                   "json.Number",
 	FieldDataTypes.DataCategoryGroupReference: "string",
+	FieldDataTypes.Date:                       "string",
+	FieldDataTypes.DateTime:                   "string",
+	FieldDataTypes.Double:                     "json.Number",
 	FieldDataTypes.Email:                      "string",
 	FieldDataTypes.Encryptedstring:            "string",
 	FieldDataTypes.ID:                         "string",
+	FieldDataTypes.Int:                        "int",
 	FieldDataTypes.JunctionIdList:             "[]string",
 	FieldDataTypes.Location:                   "string",
+	FieldDataTypes.Long:                       "int64",
 	FieldDataTypes.Masterrecord:               "string",
 	FieldDataTypes.Multipicklist:              "string",
 	FieldDataTypes.Percent:                    "json.Number",
 	FieldDataTypes.Phone:                      "string",
 	FieldDataTypes.Picklist:                   "string",
 	FieldDataTypes.Reference:                  "string",
+	FieldDataTypes.String:                     "string",
 	FieldDataTypes.Textarea:                   "string",
+	FieldDataTypes.Time:                       "string",
 	FieldDataTypes.URL:                        "string",
 }
 
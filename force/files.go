@@ -0,0 +1,220 @@
+package force
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// ContentShareTypes represents the permissions granted by a ContentDocumentLink.
+var ContentShareTypes = struct {
+	Viewer       string
+	Collaborator string
+	Inferred     string
+}{
+	Viewer:       "V",
+	Collaborator: "C",
+	Inferred:     "I",
+}
+
+// ContentVersionUpload represents the fields of a file uploaded as a ContentVersion.
+type ContentVersionUpload struct {
+	Title        string `json:"Title"`
+	PathOnClient string `json:"PathOnClient"`
+
+	Description     *string `json:"Description,omitempty"`
+	ReasonForChange *string `json:"ReasonForChange,omitempty"`
+
+	// ContentDocumentID, if set, uploads a new version of an existing document instead of a new document.
+	ContentDocumentID *string `json:"ContentDocumentId,omitempty"`
+
+	// FirstPublishLocationID, if set, is the record, user or library the document is first published to.
+	FirstPublishLocationID *string `json:"FirstPublishLocationId,omitempty"`
+
+	// LinkedEntityIDs are the records, users or groups the document is shared with once uploaded,
+	// through a ContentDocumentLink each.
+	LinkedEntityIDs []string `json:"-"`
+
+	// ShareType is one of the ContentShareTypes granted by the links. Defaults to Viewer.
+	ShareType *string `json:"-"`
+
+	// Visibility of the links, such as `AllUsers` or `InternalUsers`.
+	Visibility *string `json:"-"`
+}
+
+// ContentVersionUploadResult represents the result of uploading a ContentVersion.
+type ContentVersionUploadResult struct {
+	// ID is the ID of the new ContentVersion.
+	ID string
+
+	// ContentDocumentID is the ID of the document the version belongs to.
+	ContentDocumentID string
+
+	// Links holds the result of creating a ContentDocumentLink for each of the LinkedEntityIDs, in order.
+	Links []*SObjectCollectionResult
+}
+
+// AttachmentUpload represents the fields of a file uploaded as an Attachment.
+type AttachmentUpload struct {
+	Name     string `json:"Name"`
+	ParentID string `json:"ParentId"`
+
+	ContentType *string `json:"ContentType,omitempty"`
+	Description *string `json:"Description,omitempty"`
+	IsPrivate   *bool   `json:"IsPrivate,omitempty"`
+}
+
+// CreateWithBlob creates a SObject with a blob field, such as a Document's `Body`, in a multipart request.
+// The fields are sent as JSON along with the content, which is streamed from the reader rather than
+// base64-encoded in memory.
+//
+// As the content is streamed and can only be read once, the request is never retried, even with the Retry option.
+func (c *Client) CreateWithBlob(objectName, blobField, fileName string, fields interface{},
+	content io.Reader) (*SObjectCreateResult, *simpleresty.Response, error) {
+	body, writer := io.Pipe()
+	defer body.Close()
+
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeBlobForm(form, objectName, blobField, fileName, fields, content))
+	}()
+
+	var result *SObjectCreateResult
+	req := c.http.R().SetHeader("Content-Type", form.FormDataContentType()).SetBody(body).SetResult(&result).
+		SetContext(withoutRetries(context.Background()))
+	req.Method = simpleresty.PostMethod
+	req.URL = c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects/%s", c.apiVersion, objectName))
+
+	response, err := c.http.Dispatch(req)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return result, response, nil
+}
+
+// writeBlobForm writes the JSON fields and the content as the parts of a multipart form.
+func writeBlobForm(form *multipart.Writer, objectName, blobField, fileName string, fields interface{},
+	content io.Reader) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, blobEntityName(objectName)))
+	header.Set("Content-Type", "application/json")
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(part).Encode(fields); err != nil {
+		return err
+	}
+
+	header = textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, blobField,
+		strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(fileName)))
+	header.Set("Content-Type", "application/octet-stream")
+	if part, err = form.CreatePart(header); err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
+
+// blobEntityName returns the name of the JSON part of a multipart request, such as `entity_content` for
+// a ContentVersion.
+func blobEntityName(objectName string) string {
+	if strings.EqualFold(objectName, "ContentVersion") {
+		return "entity_content"
+	}
+	return "entity_" + strings.ToLower(objectName)
+}
+
+// UploadContentVersion uploads a file as a ContentVersion and shares the resulting document with any
+// LinkedEntityIDs. See CreateWithBlob.
+//
+// If the links fail, the result is returned along with an error, as the file has already been uploaded.
+func (c *Client) UploadContentVersion(file *ContentVersionUpload, content io.Reader) (*ContentVersionUploadResult, error) {
+	created, _, err := c.CreateWithBlob("ContentVersion", "VersionData", file.PathOnClient, file, content)
+	if err != nil {
+		return nil, err
+	}
+
+	version, _, err := c.Get("ContentVersion", created.ID, "ContentDocumentId")
+	if err != nil {
+		return nil, err
+	}
+	result := &ContentVersionUploadResult{ID: created.ID}
+	result.ContentDocumentID, _ = version["ContentDocumentId"].(string)
+	if len(file.LinkedEntityIDs) == 0 {
+		return result, nil
+	}
+
+	links := make([]SObject, 0, len(file.LinkedEntityIDs))
+	for _, id := range file.LinkedEntityIDs {
+		link := SObject{"ContentDocumentId": result.ContentDocumentID, "LinkedEntityId": id,
+			"ShareType": ContentShareTypes.Viewer}
+		if file.ShareType != nil {
+			link["ShareType"] = file.GetShareType()
+		}
+		if file.Visibility != nil {
+			link["Visibility"] = file.GetVisibility()
+		}
+		links = append(links, link)
+	}
+
+	result.Links, _, err = c.CreateCollection("ContentDocumentLink", links, true)
+	if err != nil {
+		return result, err
+	}
+	for i, link := range result.Links {
+		if !link.Success && len(link.Errors) > 0 {
+			return result, fmt.Errorf("linking document %s to %s: %s", result.ContentDocumentID,
+				file.LinkedEntityIDs[i], link.Errors[0])
+		}
+	}
+
+	return result, nil
+}
+
+// UploadAttachment uploads a file as an Attachment of its parent record. See CreateWithBlob.
+func (c *Client) UploadAttachment(attachment *AttachmentUpload, content io.Reader) (*SObjectCreateResult, *simpleresty.Response, error) {
+	return c.CreateWithBlob("Attachment", "Body", attachment.Name, attachment, content)
+}
+
+// DownloadBlob streams the content of a blob field, such as a ContentVersion's `VersionData`, to w and
+// returns the number of bytes written.
+func (c *Client) DownloadBlob(objectName, objectId, blobField string, w io.Writer) (int64, error) {
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects/%s/%s/%s",
+		c.apiVersion, objectName, objectId, blobField))
+
+	response, err := c.http.R().SetDoNotParseResponse(true).Get(urlStr)
+	if err != nil {
+		return 0, err
+	}
+	body := response.RawBody()
+	defer body.Close()
+
+	if response.StatusCode() >= 300 {
+		message, _ := ioutil.ReadAll(body)
+		return 0, fmt.Errorf("%s %s: %d %s", simpleresty.GetMethod, urlStr, response.StatusCode(), message)
+	}
+
+	return io.Copy(w, body)
+}
+
+// DownloadContentVersion streams the content of a ContentVersion to w. See DownloadBlob.
+func (c *Client) DownloadContentVersion(id string, w io.Writer) (int64, error) {
+	return c.DownloadBlob("ContentVersion", id, "VersionData", w)
+}
+
+// DownloadAttachment streams the content of an Attachment to w. See DownloadBlob.
+func (c *Client) DownloadAttachment(id string, w io.Writer) (int64, error) {
+	return c.DownloadBlob("Attachment", id, "Body", w)
+}
@@ -5,12 +5,928 @@
 // Code generated by gen-accessors; DO NOT EDIT.
 package force
 
+// HasInputs checks if ActionDescribe has any Inputs.
+func (a *ActionDescribe) HasInputs() bool {
+	if a == nil || a.Inputs == nil {
+		return false
+	}
+	if len(a.Inputs) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasOutputs checks if ActionDescribe has any Outputs.
+func (a *ActionDescribe) HasOutputs() bool {
+	if a == nil || a.Outputs == nil {
+		return false
+	}
+	if len(a.Outputs) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetTargetEntityName returns the TargetEntityName field if it's non-nil, zero value otherwise.
+func (a *ActionDescribe) GetTargetEntityName() string {
+	if a == nil || a.TargetEntityName == nil {
+		return ""
+	}
+	return *a.TargetEntityName
+}
+
+// HasFailures checks if ActionError has any Failures.
+func (a *ActionError) HasFailures() bool {
+	if a == nil || a.Failures == nil {
+		return false
+	}
+	if len(a.Failures) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasActions checks if ActionList has any Actions.
+func (a *ActionList) HasActions() bool {
+	if a == nil || a.Actions == nil {
+		return false
+	}
+	if len(a.Actions) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasPicklistValues checks if ActionParameter has any PicklistValues.
+func (a *ActionParameter) HasPicklistValues() bool {
+	if a == nil || a.PicklistValues == nil {
+		return false
+	}
+	if len(a.PicklistValues) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetSObjectType returns the SObjectType field if it's non-nil, zero value otherwise.
+func (a *ActionParameter) GetSObjectType() string {
+	if a == nil || a.SObjectType == nil {
+		return ""
+	}
+	return *a.SObjectType
+}
+
+// HasInputs checks if ActionRequest has any Inputs.
+func (a *ActionRequest) HasInputs() bool {
+	if a == nil || a.Inputs == nil {
+		return false
+	}
+	if len(a.Inputs) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasErrors checks if ActionResult has any Errors.
+func (a *ActionResult) HasErrors() bool {
+	if a == nil || a.Errors == nil {
+		return false
+	}
+	if len(a.Errors) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasUncoveredLines checks if ApexCodeCoverage has any UncoveredLines.
+func (a *ApexCodeCoverage) HasUncoveredLines() bool {
+	if a == nil || a.UncoveredLines == nil {
+		return false
+	}
+	if len(a.UncoveredLines) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasCoverage checks if ApexTestRunResult has any Coverage.
+func (a *ApexTestRunResult) HasCoverage() bool {
+	if a == nil || a.Coverage == nil {
+		return false
+	}
+	if len(a.Coverage) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasCoverageWarnings checks if ApexTestRunResult has any CoverageWarnings.
+func (a *ApexTestRunResult) HasCoverageWarnings() bool {
+	if a == nil || a.CoverageWarnings == nil {
+		return false
+	}
+	if len(a.CoverageWarnings) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasTests checks if ApexTestRunResult has any Tests.
+func (a *ApexTestRunResult) HasTests() bool {
+	if a == nil || a.Tests == nil {
+		return false
+	}
+	if len(a.Tests) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetContentType returns the ContentType field if it's non-nil, zero value otherwise.
+func (a *AttachmentUpload) GetContentType() string {
+	if a == nil || a.ContentType == nil {
+		return ""
+	}
+	return *a.ContentType
+}
+
+// GetDescription returns the Description field if it's non-nil, zero value otherwise.
+func (a *AttachmentUpload) GetDescription() string {
+	if a == nil || a.Description == nil {
+		return ""
+	}
+	return *a.Description
+}
+
+// GetIsPrivate returns the IsPrivate field if it's non-nil, zero value otherwise.
+func (a *AttachmentUpload) GetIsPrivate() bool {
+	if a == nil || a.IsPrivate == nil {
+		return false
+	}
+	return *a.IsPrivate
+}
+
+// HasInteractions checks if Cassette has any Interactions.
+func (c *Cassette) HasInteractions() bool {
+	if c == nil || c.Interactions == nil {
+		return false
+	}
+	if len(c.Interactions) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetHeader returns the Header field.
+func (c *ChangeEvent) GetHeader() *ChangeEventHeader {
+	if c == nil {
+		return nil
+	}
+	return c.Header
+}
+
+// GetMetadata returns the Metadata field.
+func (c *ChangeEvent) GetMetadata() *SObjectMetadata {
+	if c == nil {
+		return nil
+	}
+	return c.Metadata
+}
+
+// HasChangedFields checks if ChangeEventHeader has any ChangedFields.
+func (c *ChangeEventHeader) HasChangedFields() bool {
+	if c == nil || c.ChangedFields == nil {
+		return false
+	}
+	if len(c.ChangedFields) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasDiffFields checks if ChangeEventHeader has any DiffFields.
+func (c *ChangeEventHeader) HasDiffFields() bool {
+	if c == nil || c.DiffFields == nil {
+		return false
+	}
+	if len(c.DiffFields) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasNulledFields checks if ChangeEventHeader has any NulledFields.
+func (c *ChangeEventHeader) HasNulledFields() bool {
+	if c == nil || c.NulledFields == nil {
+		return false
+	}
+	if len(c.NulledFields) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasRecordIDs checks if ChangeEventHeader has any RecordIDs.
+func (c *ChangeEventHeader) HasRecordIDs() bool {
+	if c == nil || c.RecordIDs == nil {
+		return false
+	}
+	if len(c.RecordIDs) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetContentDocumentID returns the ContentDocumentID field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetContentDocumentID() string {
+	if c == nil || c.ContentDocumentID == nil {
+		return ""
+	}
+	return *c.ContentDocumentID
+}
+
+// GetDescription returns the Description field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetDescription() string {
+	if c == nil || c.Description == nil {
+		return ""
+	}
+	return *c.Description
+}
+
+// GetFirstPublishLocationID returns the FirstPublishLocationID field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetFirstPublishLocationID() string {
+	if c == nil || c.FirstPublishLocationID == nil {
+		return ""
+	}
+	return *c.FirstPublishLocationID
+}
+
+// HasLinkedEntityIDs checks if ContentVersionUpload has any LinkedEntityIDs.
+func (c *ContentVersionUpload) HasLinkedEntityIDs() bool {
+	if c == nil || c.LinkedEntityIDs == nil {
+		return false
+	}
+	if len(c.LinkedEntityIDs) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetReasonForChange returns the ReasonForChange field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetReasonForChange() string {
+	if c == nil || c.ReasonForChange == nil {
+		return ""
+	}
+	return *c.ReasonForChange
+}
+
+// GetShareType returns the ShareType field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetShareType() string {
+	if c == nil || c.ShareType == nil {
+		return ""
+	}
+	return *c.ShareType
+}
+
+// GetVisibility returns the Visibility field if it's non-nil, zero value otherwise.
+func (c *ContentVersionUpload) GetVisibility() string {
+	if c == nil || c.Visibility == nil {
+		return ""
+	}
+	return *c.Visibility
+}
+
+// HasLinks checks if ContentVersionUploadResult has any Links.
+func (c *ContentVersionUploadResult) HasLinks() bool {
+	if c == nil || c.Links == nil {
+		return false
+	}
+	if len(c.Links) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasDeletedRecords checks if DeletedResult has any DeletedRecords.
+func (d *DeletedResult) HasDeletedRecords() bool {
+	if d == nil || d.DeletedRecords == nil {
+		return false
+	}
+	if len(d.DeletedRecords) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasSObjects checks if DescribeGlobalResult has any SObjects.
+func (d *DescribeGlobalResult) HasSObjects() bool {
+	if d == nil || d.SObjects == nil {
+		return false
+	}
+	if len(d.SObjects) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasScopes checks if DeviceFlow has any Scopes.
+func (d *DeviceFlow) HasScopes() bool {
+	if d == nil || d.Scopes == nil {
+		return false
+	}
+	if len(d.Scopes) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetCompileProblem returns the CompileProblem field if it's non-nil, zero value otherwise.
+func (e *ExecuteAnonymousResult) GetCompileProblem() string {
+	if e == nil || e.CompileProblem == nil {
+		return ""
+	}
+	return *e.CompileProblem
+}
+
+// GetExceptionMessage returns the ExceptionMessage field if it's non-nil, zero value otherwise.
+func (e *ExecuteAnonymousResult) GetExceptionMessage() string {
+	if e == nil || e.ExceptionMessage == nil {
+		return ""
+	}
+	return *e.ExceptionMessage
+}
+
+// GetExceptionStackTrace returns the ExceptionStackTrace field if it's non-nil, zero value otherwise.
+func (e *ExecuteAnonymousResult) GetExceptionStackTrace() string {
+	if e == nil || e.ExceptionStackTrace == nil {
+		return ""
+	}
+	return *e.ExceptionStackTrace
+}
+
+// HasPicklistValuesAdded checks if FieldDiff has any PicklistValuesAdded.
+func (f *FieldDiff) HasPicklistValuesAdded() bool {
+	if f == nil || f.PicklistValuesAdded == nil {
+		return false
+	}
+	if len(f.PicklistValuesAdded) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasPicklistValuesRemoved checks if FieldDiff has any PicklistValuesRemoved.
+func (f *FieldDiff) HasPicklistValuesRemoved() bool {
+	if f == nil || f.PicklistValuesRemoved == nil {
+		return false
+	}
+	if len(f.PicklistValuesRemoved) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasProperties checks if FieldDiff has any Properties.
+func (f *FieldDiff) HasProperties() bool {
+	if f == nil || f.Properties == nil {
+		return false
+	}
+	if len(f.Properties) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasErrors checks if ImportResult has any Errors.
+func (i *ImportResult) HasErrors() bool {
+	if i == nil || i.Errors == nil {
+		return false
+	}
+	if len(i.Errors) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasRecords checks if ImportResult has any Records.
+func (i *ImportResult) HasRecords() bool {
+	if i == nil || i.Records == nil {
+		return false
+	}
+	if len(i.Records) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasUnmappedColumns checks if ImportResult has any UnmappedColumns.
+func (i *ImportResult) HasUnmappedColumns() bool {
+	if i == nil || i.UnmappedColumns == nil {
+		return false
+	}
+	if len(i.UnmappedColumns) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetRequest returns the Request field.
+func (i *Interaction) GetRequest() *RecordedRequest {
+	if i == nil {
+		return nil
+	}
+	return i.Request
+}
+
+// GetResponse returns the Response field.
+func (i *Interaction) GetResponse() *RecordedResponse {
+	if i == nil {
+		return nil
+	}
+	return i.Response
+}
+
+// HasFields checks if ObjectDiff has any Fields.
+func (o *ObjectDiff) HasFields() bool {
+	if o == nil || o.Fields == nil {
+		return false
+	}
+	if len(o.Fields) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasProperties checks if ObjectDiff has any Properties.
+func (o *ObjectDiff) HasProperties() bool {
+	if o == nil || o.Properties == nil {
+		return false
+	}
+	if len(o.Properties) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasRelationships checks if ObjectDiff has any Relationships.
+func (o *ObjectDiff) HasRelationships() bool {
+	if o == nil || o.Relationships == nil {
+		return false
+	}
+	if len(o.Relationships) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasErrors checks if PublishResult has any Errors.
+func (p *PublishResult) HasErrors() bool {
+	if p == nil || p.Errors == nil {
+		return false
+	}
+	if len(p.Errors) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetReplayID returns the ReplayID field if it's non-nil, zero value otherwise.
+func (p *PublishResult) GetReplayID() int64 {
+	if p == nil || p.ReplayID == nil {
+		return 0
+	}
+	return *p.ReplayID
+}
+
 // HasRecords checks if QueryResult has any Records.
 func (q *QueryResult) HasRecords() bool {
 	if q == nil || q.Records == nil {
 		return false
 	}
-	if len(q.Records) == 0 {
+	if len(q.Records) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasProperties checks if RelationshipDiff has any Properties.
+func (r *RelationshipDiff) HasProperties() bool {
+	if r == nil || r.Properties == nil {
+		return false
+	}
+	if len(r.Properties) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetGroupingLevel returns the GroupingLevel field if it's non-nil, zero value otherwise.
+func (r *ReportColumnInfo) GetGroupingLevel() int {
+	if r == nil || r.GroupingLevel == nil {
+		return 0
+	}
+	return *r.GroupingLevel
+}
+
+// GetReportExtendedMetadata returns the ReportExtendedMetadata field.
+func (r *ReportDescribe) GetReportExtendedMetadata() *ReportExtendedMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ReportExtendedMetadata
+}
+
+// GetReportMetadata returns the ReportMetadata field.
+func (r *ReportDescribe) GetReportMetadata() *ReportMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ReportMetadata
+}
+
+// HasAggregates checks if ReportFact has any Aggregates.
+func (r *ReportFact) HasAggregates() bool {
+	if r == nil || r.Aggregates == nil {
+		return false
+	}
+	if len(r.Aggregates) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasRows checks if ReportFact has any Rows.
+func (r *ReportFact) HasRows() bool {
+	if r == nil || r.Rows == nil {
+		return false
+	}
+	if len(r.Rows) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasDataCells checks if ReportFactRow has any DataCells.
+func (r *ReportFactRow) HasDataCells() bool {
+	if r == nil || r.DataCells == nil {
+		return false
+	}
+	if len(r.DataCells) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasGroupings checks if ReportGroupings has any Groupings.
+func (r *ReportGroupings) HasGroupings() bool {
+	if r == nil || r.Groupings == nil {
+		return false
+	}
+	if len(r.Groupings) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasGroupings checks if ReportGroupingValue has any Groupings.
+func (r *ReportGroupingValue) HasGroupings() bool {
+	if r == nil || r.Groupings == nil {
+		return false
+	}
+	if len(r.Groupings) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetCompletionDate returns the CompletionDate field if it's non-nil, zero value otherwise.
+func (r *ReportInstance) GetCompletionDate() string {
+	if r == nil || r.CompletionDate == nil {
+		return ""
+	}
+	return *r.CompletionDate
+}
+
+// HasAggregates checks if ReportMetadata has any Aggregates.
+func (r *ReportMetadata) HasAggregates() bool {
+	if r == nil || r.Aggregates == nil {
+		return false
+	}
+	if len(r.Aggregates) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetCurrency returns the Currency field if it's non-nil, zero value otherwise.
+func (r *ReportMetadata) GetCurrency() string {
+	if r == nil || r.Currency == nil {
+		return ""
+	}
+	return *r.Currency
+}
+
+// HasDetailColumns checks if ReportMetadata has any DetailColumns.
+func (r *ReportMetadata) HasDetailColumns() bool {
+	if r == nil || r.DetailColumns == nil {
+		return false
+	}
+	if len(r.DetailColumns) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasGroupingsAcross checks if ReportMetadata has any GroupingsAcross.
+func (r *ReportMetadata) HasGroupingsAcross() bool {
+	if r == nil || r.GroupingsAcross == nil {
+		return false
+	}
+	if len(r.GroupingsAcross) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasGroupingsDown checks if ReportMetadata has any GroupingsDown.
+func (r *ReportMetadata) HasGroupingsDown() bool {
+	if r == nil || r.GroupingsDown == nil {
+		return false
+	}
+	if len(r.GroupingsDown) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetReportBooleanFilter returns the ReportBooleanFilter field if it's non-nil, zero value otherwise.
+func (r *ReportMetadata) GetReportBooleanFilter() string {
+	if r == nil || r.ReportBooleanFilter == nil {
+		return ""
+	}
+	return *r.ReportBooleanFilter
+}
+
+// HasReportFilters checks if ReportMetadata has any ReportFilters.
+func (r *ReportMetadata) HasReportFilters() bool {
+	if r == nil || r.ReportFilters == nil {
+		return false
+	}
+	if len(r.ReportFilters) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetReportType returns the ReportType field.
+func (r *ReportMetadata) GetReportType() *ReportType {
+	if r == nil {
+		return nil
+	}
+	return r.ReportType
+}
+
+// GetStandardDateFilter returns the StandardDateFilter field.
+func (r *ReportMetadata) GetStandardDateFilter() *ReportStandardDateFilter {
+	if r == nil {
+		return nil
+	}
+	return r.StandardDateFilter
+}
+
+// GetAttributes returns the Attributes field.
+func (r *ReportResult) GetAttributes() *ReportInstance {
+	if r == nil {
+		return nil
+	}
+	return r.Attributes
+}
+
+// GetGroupingsAcross returns the GroupingsAcross field.
+func (r *ReportResult) GetGroupingsAcross() *ReportGroupings {
+	if r == nil {
+		return nil
+	}
+	return r.GroupingsAcross
+}
+
+// GetGroupingsDown returns the GroupingsDown field.
+func (r *ReportResult) GetGroupingsDown() *ReportGroupings {
+	if r == nil {
+		return nil
+	}
+	return r.GroupingsDown
+}
+
+// GetReportExtendedMetadata returns the ReportExtendedMetadata field.
+func (r *ReportResult) GetReportExtendedMetadata() *ReportExtendedMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ReportExtendedMetadata
+}
+
+// GetReportMetadata returns the ReportMetadata field.
+func (r *ReportResult) GetReportMetadata() *ReportMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ReportMetadata
+}
+
+// HasCells checks if ReportRow has any Cells.
+func (r *ReportRow) HasCells() bool {
+	if r == nil || r.Cells == nil {
+		return false
+	}
+	if len(r.Cells) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasGroupings checks if ReportRow has any Groupings.
+func (r *ReportRow) HasGroupings() bool {
+	if r == nil || r.Groupings == nil {
+		return false
+	}
+	if len(r.Groupings) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetReportMetadata returns the ReportMetadata field.
+func (r *ReportRunRequest) GetReportMetadata() *ReportMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ReportMetadata
+}
+
+// GetEndDate returns the EndDate field if it's non-nil, zero value otherwise.
+func (r *ReportStandardDateFilter) GetEndDate() string {
+	if r == nil || r.EndDate == nil {
+		return ""
+	}
+	return *r.EndDate
+}
+
+// GetStartDate returns the StartDate field if it's non-nil, zero value otherwise.
+func (r *ReportStandardDateFilter) GetStartDate() string {
+	if r == nil || r.StartDate == nil {
+		return ""
+	}
+	return *r.StartDate
+}
+
+// HasLocationsNotCovered checks if RunTestCodeCoverage has any LocationsNotCovered.
+func (r *RunTestCodeCoverage) HasLocationsNotCovered() bool {
+	if r == nil || r.LocationsNotCovered == nil {
+		return false
+	}
+	if len(r.LocationsNotCovered) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetNamespace returns the Namespace field if it's non-nil, zero value otherwise.
+func (r *RunTestCodeCoverage) GetNamespace() string {
+	if r == nil || r.Namespace == nil {
+		return ""
+	}
+	return *r.Namespace
+}
+
+// GetName returns the Name field if it's non-nil, zero value otherwise.
+func (r *RunTestCoverageWarning) GetName() string {
+	if r == nil || r.Name == nil {
+		return ""
+	}
+	return *r.Name
+}
+
+// GetNamespace returns the Namespace field if it's non-nil, zero value otherwise.
+func (r *RunTestCoverageWarning) GetNamespace() string {
+	if r == nil || r.Namespace == nil {
+		return ""
+	}
+	return *r.Namespace
+}
+
+// GetNamespace returns the Namespace field if it's non-nil, zero value otherwise.
+func (r *RunTestFailure) GetNamespace() string {
+	if r == nil || r.Namespace == nil {
+		return ""
+	}
+	return *r.Namespace
+}
+
+// GetMaxFailedTests returns the MaxFailedTests field if it's non-nil, zero value otherwise.
+func (r *RunTestsRequest) GetMaxFailedTests() int {
+	if r == nil || r.MaxFailedTests == nil {
+		return 0
+	}
+	return *r.MaxFailedTests
+}
+
+// GetSkipCodeCoverage returns the SkipCodeCoverage field if it's non-nil, zero value otherwise.
+func (r *RunTestsRequest) GetSkipCodeCoverage() bool {
+	if r == nil || r.SkipCodeCoverage == nil {
+		return false
+	}
+	return *r.SkipCodeCoverage
+}
+
+// HasTests checks if RunTestsRequest has any Tests.
+func (r *RunTestsRequest) HasTests() bool {
+	if r == nil || r.Tests == nil {
+		return false
+	}
+	if len(r.Tests) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasCodeCoverage checks if RunTestsSynchronousResult has any CodeCoverage.
+func (r *RunTestsSynchronousResult) HasCodeCoverage() bool {
+	if r == nil || r.CodeCoverage == nil {
+		return false
+	}
+	if len(r.CodeCoverage) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasCodeCoverageWarnings checks if RunTestsSynchronousResult has any CodeCoverageWarnings.
+func (r *RunTestsSynchronousResult) HasCodeCoverageWarnings() bool {
+	if r == nil || r.CodeCoverageWarnings == nil {
+		return false
+	}
+	if len(r.CodeCoverageWarnings) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasFailures checks if RunTestsSynchronousResult has any Failures.
+func (r *RunTestsSynchronousResult) HasFailures() bool {
+	if r == nil || r.Failures == nil {
+		return false
+	}
+	if len(r.Failures) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasSuccesses checks if RunTestsSynchronousResult has any Successes.
+func (r *RunTestsSynchronousResult) HasSuccesses() bool {
+	if r == nil || r.Successes == nil {
+		return false
+	}
+	if len(r.Successes) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetNamespace returns the Namespace field if it's non-nil, zero value otherwise.
+func (r *RunTestSuccess) GetNamespace() string {
+	if r == nil || r.Namespace == nil {
+		return ""
+	}
+	return *r.Namespace
+}
+
+// HasObjects checks if SchemaDiff has any Objects.
+func (s *SchemaDiff) HasObjects() bool {
+	if s == nil || s.Objects == nil {
+		return false
+	}
+	if len(s.Objects) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetManifest returns the Manifest field.
+func (s *Snapshot) GetManifest() *SnapshotManifest {
+	if s == nil {
+		return nil
+	}
+	return s.Manifest
+}
+
+// HasSObjects checks if SnapshotManifest has any SObjects.
+func (s *SnapshotManifest) HasSObjects() bool {
+	if s == nil || s.SObjects == nil {
+		return false
+	}
+	if len(s.SObjects) == 0 {
 		return false
 	}
 	return true
@@ -126,6 +1042,47 @@ func (s *SObjectChildRelationshipMetadata) GetRestrictedDelete() bool {
 	return *s.RestrictedDelete
 }
 
+// HasRecords checks if SObjectCollectionRequest has any Records.
+func (s *SObjectCollectionRequest) HasRecords() bool {
+	if s == nil || s.Records == nil {
+		return false
+	}
+	if len(s.Records) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetCreated returns the Created field if it's non-nil, zero value otherwise.
+func (s *SObjectCollectionResult) GetCreated() bool {
+	if s == nil || s.Created == nil {
+		return false
+	}
+	return *s.Created
+}
+
+// HasErrors checks if SObjectCollectionResult has any Errors.
+func (s *SObjectCollectionResult) HasErrors() bool {
+	if s == nil || s.Errors == nil {
+		return false
+	}
+	if len(s.Errors) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasFields checks if SObjectError has any Fields.
+func (s *SObjectError) HasFields() bool {
+	if s == nil || s.Fields == nil {
+		return false
+	}
+	if len(s.Fields) == 0 {
+		return false
+	}
+	return true
+}
+
 // HasControllingFields checks if SObjectFieldFilteredLookupInfoMetadata has any ControllingFields.
 func (s *SObjectFieldFilteredLookupInfoMetadata) HasControllingFields() bool {
 	if s == nil || s.ControllingFields == nil {
@@ -953,6 +1910,36 @@ func (s *SObjectScopeInfoInfoMetadata) GetName() string {
 	return *s.Name
 }
 
+// GetAdvice returns the Advice field.
+func (s *StreamingMessage) GetAdvice() *StreamingAdvice {
+	if s == nil {
+		return nil
+	}
+	return s.Advice
+}
+
+// HasSupportedConnectionTypes checks if StreamingMessage has any SupportedConnectionTypes.
+func (s *StreamingMessage) HasSupportedConnectionTypes() bool {
+	if s == nil || s.SupportedConnectionTypes == nil {
+		return false
+	}
+	if len(s.SupportedConnectionTypes) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasTestMethods checks if TestItem has any TestMethods.
+func (t *TestItem) HasTestMethods() bool {
+	if t == nil || t.TestMethods == nil {
+		return false
+	}
+	if len(t.TestMethods) == 0 {
+		return false
+	}
+	return true
+}
+
 // GetAccessToken returns the AccessToken field if it's non-nil, zero value otherwise.
 func (t *TokenResponse) GetAccessToken() string {
 	if t == nil || t.AccessToken == nil {
@@ -1016,3 +2003,66 @@ func (t *TokenResponse) GetTokenType() string {
 	}
 	return *t.TokenType
 }
+
+// HasValidFor checks if UIPicklistValue has any ValidFor.
+func (u *UIPicklistValue) HasValidFor() bool {
+	if u == nil || u.ValidFor == nil {
+		return false
+	}
+	if len(u.ValidFor) == 0 {
+		return false
+	}
+	return true
+}
+
+// GetDefaultValue returns the DefaultValue field.
+func (u *UIPicklistValues) GetDefaultValue() *UIPicklistValue {
+	if u == nil {
+		return nil
+	}
+	return u.DefaultValue
+}
+
+// HasValues checks if UIPicklistValues has any Values.
+func (u *UIPicklistValues) HasValues() bool {
+	if u == nil || u.Values == nil {
+		return false
+	}
+	if len(u.Values) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasIDs checks if UpdatedResult has any IDs.
+func (u *UpdatedResult) HasIDs() bool {
+	if u == nil || u.IDs == nil {
+		return false
+	}
+	if len(u.IDs) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasErrors checks if ValidationError has any Errors.
+func (v *ValidationError) HasErrors() bool {
+	if v == nil || v.Errors == nil {
+		return false
+	}
+	if len(v.Errors) == 0 {
+		return false
+	}
+	return true
+}
+
+// HasScopes checks if WebServerFlow has any Scopes.
+func (w *WebServerFlow) HasScopes() bool {
+	if w == nil || w.Scopes == nil {
+		return false
+	}
+	if len(w.Scopes) == 0 {
+		return false
+	}
+	return true
+}
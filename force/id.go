@@ -0,0 +1,259 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"strings"
+	"sync"
+)
+
+// idSuffixAlphabet maps the case flags of a five-character chunk of an ID to a character of its 18-character suffix.
+const idSuffixAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"
+
+// ID represents a Salesforce record ID, either the 15-character case-sensitive form or the 18-character
+// case-insensitive form whose last three characters encode the case of the first 15.
+//
+// Reference: https://help.salesforce.com/articleView?id=000324087&type=1
+type ID string
+
+// ParseID parses a 15 or 18-character ID and returns it in its 18-character form.
+//
+// The case of an 18-character ID is restored from its suffix if it was lost, such as by a spreadsheet that
+// lower-cased it. An ID whose suffix doesn't match its case or flags a digit as upper case is rejected.
+func ParseID(s string) (ID, error) {
+	if !idPattern.MatchString(s) {
+		return "", fmt.Errorf("invalid ID %q: must be 15 or 18 alphanumeric characters", s)
+	}
+	if len(s) == 15 {
+		return ID(s + idSuffix(s)), nil
+	}
+
+	restored, err := restoreIDCase(s)
+	if err != nil {
+		return "", err
+	}
+	if hasMixedCase(s[:15]) && restored[:15] != s[:15] {
+		return "", fmt.Errorf("invalid ID %q: suffix %s does not match the case of the ID", s, s[15:])
+	}
+	return ID(restored), nil
+}
+
+// ValidID reports whether a string is a valid 15 or 18-character ID.
+func ValidID(s string) bool {
+	_, err := ParseID(s)
+	return err == nil
+}
+
+// EqualIDs reports whether two IDs identify the same record, regardless of whether either is in its 15 or
+// 18-character form. Invalid IDs are never equal.
+func EqualIDs(a, b string) bool {
+	idA, err := ParseID(a)
+	if err != nil {
+		return false
+	}
+	idB, err := ParseID(b)
+	if err != nil {
+		return false
+	}
+	return idA == idB
+}
+
+// String returns the ID as a string.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Valid reports whether the ID is a valid 15 or 18-character ID.
+func (id ID) Valid() bool {
+	return ValidID(string(id))
+}
+
+// To18 returns the 18-character form of the ID, or the ID unchanged if it isn't valid.
+func (id ID) To18() ID {
+	parsed, err := ParseID(string(id))
+	if err != nil {
+		return id
+	}
+	return parsed
+}
+
+// To15 returns the case-sensitive 15-character form of the ID, or the ID unchanged if it isn't valid.
+func (id ID) To15() ID {
+	parsed, err := ParseID(string(id))
+	if err != nil {
+		return id
+	}
+	return parsed[:15]
+}
+
+// Suffix returns the three-character checksum suffix that converts the ID to its 18-character form, or an empty
+// string if the ID isn't valid.
+func (id ID) Suffix() string {
+	parsed, err := ParseID(string(id))
+	if err != nil {
+		return ""
+	}
+	return string(parsed[15:])
+}
+
+// KeyPrefix returns the first three characters of the ID, which identify its SObject.
+func (id ID) KeyPrefix() string {
+	if len(id) < 3 {
+		return ""
+	}
+	return string(id[:3])
+}
+
+// Equal reports whether the IDs identify the same record. See EqualIDs.
+func (id ID) Equal(other ID) bool {
+	return EqualIDs(string(id), string(other))
+}
+
+// idSuffix computes the suffix of a 15-character ID. Each suffix character encodes which of the five characters
+// of a chunk are upper case letters, the first character being the least significant bit.
+func idSuffix(id string) string {
+	suffix := make([]byte, 3)
+	for chunk := 0; chunk < 3; chunk++ {
+		flags := 0
+		for i := 0; i < 5; i++ {
+			if c := id[chunk*5+i]; c >= 'A' && c <= 'Z' {
+				flags |= 1 << uint(i)
+			}
+		}
+		suffix[chunk] = idSuffixAlphabet[flags]
+	}
+	return string(suffix)
+}
+
+// restoreIDCase returns an 18-character ID with the case of its first 15 characters set from its suffix.
+func restoreIDCase(id string) (string, error) {
+	restored := []byte(strings.ToLower(id[:15]))
+	for chunk := 0; chunk < 3; chunk++ {
+		flags := strings.IndexByte(idSuffixAlphabet, strings.ToUpper(id[15+chunk : 16+chunk])[0])
+		if flags < 0 {
+			return "", fmt.Errorf("invalid ID %q: %c is not a valid suffix character", id, id[15+chunk])
+		}
+		for i := 0; i < 5; i++ {
+			if flags&(1<<uint(i)) == 0 {
+				continue
+			}
+			c := restored[chunk*5+i]
+			if c < 'a' || c > 'z' {
+				return "", fmt.Errorf("invalid ID %q: suffix %s does not match the ID", id, id[15:])
+			}
+			restored[chunk*5+i] = c - 'a' + 'A'
+		}
+	}
+	return string(restored) + strings.ToUpper(id[15:]), nil
+}
+
+// hasMixedCase reports whether a string has both upper and lower case letters.
+func hasMixedCase(s string) bool {
+	return strings.ToLower(s) != s && strings.ToUpper(s) != s
+}
+
+// KeyPrefixRegistry maps the three-character key prefixes of IDs to SObject names. It is safe for concurrent use.
+type KeyPrefixRegistry struct {
+	mu       sync.RWMutex
+	prefixes map[string]string
+}
+
+// NewKeyPrefixRegistry returns a registry of the key prefixes of the SObjects, such as the SObjects of
+// DescribeGlobal. SObjects without a key prefix are skipped.
+func NewKeyPrefixRegistry(sobjects ...*SObjectMetadata) *KeyPrefixRegistry {
+	r := &KeyPrefixRegistry{prefixes: map[string]string{}}
+	for _, meta := range sobjects {
+		r.RegisterSObject(meta)
+	}
+	return r
+}
+
+// Register maps a key prefix to a SObject name.
+func (r *KeyPrefixRegistry) Register(keyPrefix, objectName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixes[keyPrefix] = objectName
+}
+
+// RegisterSObject maps the key prefix of a SObject to its name, if it has one.
+func (r *KeyPrefixRegistry) RegisterSObject(meta *SObjectMetadata) {
+	if meta.GetKeyPrefix() != "" {
+		r.Register(meta.GetKeyPrefix(), meta.GetName())
+	}
+}
+
+// ObjectName returns the name of the SObject of a key prefix or ID.
+func (r *KeyPrefixRegistry) ObjectName(keyPrefixOrID string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.prefixes[ID(keyPrefixOrID).KeyPrefix()]
+	return name, ok
+}
+
+// KeyPrefix returns the key prefix of a SObject, matching its name case-insensitively.
+func (r *KeyPrefixRegistry) KeyPrefix(objectName string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for prefix, name := range r.prefixes {
+		if strings.EqualFold(name, objectName) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// Len returns the number of registered key prefixes.
+func (r *KeyPrefixRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.prefixes)
+}
+
+// KeyPrefixes returns the client's registry of key prefixes, loading it from DescribeGlobal on first use.
+func (c *Client) KeyPrefixes() (*KeyPrefixRegistry, error) {
+	c.cacheMu.Lock()
+	registry := c.keyPrefixes
+	c.cacheMu.Unlock()
+	if registry != nil {
+		return registry, nil
+	}
+
+	result, _, err := c.DescribeGlobal()
+	if err != nil {
+		return nil, err
+	}
+	registry = NewKeyPrefixRegistry(result.SObjects...)
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	for _, meta := range c.describeCache {
+		registry.RegisterSObject(meta)
+	}
+	c.keyPrefixes = registry
+	return registry, nil
+}
+
+// ObjectNameForID returns the name of the SObject an ID belongs to, from its key prefix.
+func (c *Client) ObjectNameForID(id string) (string, error) {
+	if !ValidID(id) {
+		return "", fmt.Errorf("invalid ID %q", id)
+	}
+	registry, err := c.KeyPrefixes()
+	if err != nil {
+		return "", err
+	}
+	name, ok := registry.ObjectName(id)
+	if !ok {
+		return "", fmt.Errorf("no SObject has the key prefix of ID %s", id)
+	}
+	return name, nil
+}
+
+// GetByID retrieves an existing SObject by its ID, resolving the SObject from the ID's key prefix. See Get.
+func (c *Client) GetByID(id string, fields ...string) (SObject, *simpleresty.Response, error) {
+	objectName, err := c.ObjectNameForID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.Get(objectName, ID(id).To18().String(), fields...)
+}
@@ -0,0 +1,332 @@
+package force
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportOperation represents how an Importer writes records.
+type ImportOperation string
+
+// ImportOperations represents all operations supported by an Importer.
+var ImportOperations = struct {
+	Create ImportOperation
+	Upsert ImportOperation
+}{
+	Create: "create",
+	Upsert: "upsert",
+}
+
+// Importer loads CSV rows into a SObject.
+//
+// Headers are mapped to fields of the SObjectMetadata by API name or label, case-insensitively. Each value is
+// converted with CoerceFieldValue according to the field's FieldDataType and valid rows are written in batches
+// using sObject collections. Rows that fail coercion or are rejected by Salesforce are reported as
+// ImportRowErrors rather than aborting the import.
+type Importer struct {
+	client          *Client
+	meta            *SObjectMetadata
+	operation       ImportOperation
+	externalIDField string
+	batchSize       int
+	allOrNone       bool
+	columns         map[string]string
+	layouts         []string
+}
+
+// ImporterOption is a functional option for configuring an Importer.
+type ImporterOption func(*Importer) error
+
+// ImportUpsert upserts records matched on the external ID field instead of creating them.
+func ImportUpsert(externalIDField string) ImporterOption {
+	return func(i *Importer) error {
+		if strings.TrimSpace(externalIDField) == "" {
+			return fmt.Errorf("external ID field cannot be empty string")
+		}
+		i.operation = ImportOperations.Upsert
+		i.externalIDField = externalIDField
+		return nil
+	}
+}
+
+// ImportBatchSize sets the number of records written per request. Must be between 1 and MaxCollectionSize.
+func ImportBatchSize(size int) ImporterOption {
+	return func(i *Importer) error {
+		if size < 1 || size > MaxCollectionSize {
+			return fmt.Errorf("batch size must be between 1 and %d", MaxCollectionSize)
+		}
+		i.batchSize = size
+		return nil
+	}
+}
+
+// ImportAllOrNone rolls back each batch unless every record in it succeeds.
+func ImportAllOrNone(allOrNone bool) ImporterOption {
+	return func(i *Importer) error {
+		i.allOrNone = allOrNone
+		return nil
+	}
+}
+
+// ImportColumn explicitly maps a CSV header to a field API name, overriding name and label matching.
+func ImportColumn(header, fieldName string) ImporterOption {
+	return func(i *Importer) error {
+		i.columns[strings.ToLower(strings.TrimSpace(header))] = fieldName
+		return nil
+	}
+}
+
+// ImportDateLayouts adds layouts used to parse date, datetime and time values.
+func ImportDateLayouts(layouts ...string) ImporterOption {
+	return func(i *Importer) error {
+		i.layouts = append(i.layouts, layouts...)
+		return nil
+	}
+}
+
+// ImportResult represents the outcome of an import.
+type ImportResult struct {
+	// Processed is the number of data rows read.
+	Processed int
+
+	// Created is the number of records created.
+	Created int
+
+	// Updated is the number of existing records updated by an upsert.
+	Updated int
+
+	// Records holds the result of every successfully written row.
+	Records []*ImportRecordResult
+
+	// Errors holds every row level error.
+	Errors []*ImportRowError
+
+	// UnmappedColumns are headers that didn't match any field and were ignored.
+	UnmappedColumns []string
+}
+
+// ImportRecordResult represents a row written to Salesforce.
+type ImportRecordResult struct {
+	// Row is the 1-based position of the row in the CSV file, counting the header as row 1.
+	Row int
+
+	// ID is the ID of the created or updated record.
+	ID string
+
+	// Created is false if an upsert updated an existing record.
+	Created bool
+}
+
+// ImportRowError represents a row that could not be imported.
+type ImportRowError struct {
+	// Row is the 1-based position of the row in the CSV file, counting the header as row 1.
+	Row int
+
+	// Column is the CSV header of the invalid value, if the error relates to a single value.
+	Column string
+
+	// Field is the field API name of the invalid value, if the error relates to a single field.
+	Field string
+
+	// Message describes the error.
+	Message string
+}
+
+// Error returns the row, column and message of an ImportRowError.
+func (e *ImportRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Message)
+	}
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// NewImporter returns an Importer writing to the SObject described by the metadata.
+func NewImporter(client *Client, meta *SObjectMetadata, opts ...ImporterOption) (*Importer, error) {
+	if meta == nil || meta.GetName() == "" {
+		return nil, fmt.Errorf("sobject metadata must be defined")
+	}
+
+	i := &Importer{
+		client:    client,
+		meta:      meta,
+		operation: ImportOperations.Create,
+		batchSize: MaxCollectionSize,
+		columns:   map[string]string{},
+	}
+	for _, opt := range opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.operation == ImportOperations.Upsert && i.field(i.externalIDField) == nil {
+		return nil, fmt.Errorf("external ID field %s does not exist on %s", i.externalIDField, meta.GetName())
+	}
+	return i, nil
+}
+
+// importRow is a coerced row waiting to be written.
+type importRow struct {
+	line   int
+	record SObject
+}
+
+// Import reads the CSV and writes every valid row. An error is only returned if the CSV cannot be read or a
+// request fails outright; row level problems are reported in ImportResult.Errors.
+func (i *Importer) Import(r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, readErr := reader.Read()
+	if readErr != nil {
+		return nil, fmt.Errorf("unable to read CSV header: %v", readErr)
+	}
+
+	result := &ImportResult{Records: []*ImportRecordResult{}, Errors: []*ImportRowError{}, UnmappedColumns: []string{}}
+	fields := make([]*SObjectFieldMetadata, len(header))
+	for idx, h := range header {
+		// Strip any UTF-8 byte order mark left by spreadsheet exports.
+		h = strings.TrimPrefix(h, "\ufeff")
+		header[idx] = h
+
+		fields[idx] = i.mapColumn(h)
+		if fields[idx] == nil {
+			result.UnmappedColumns = append(result.UnmappedColumns, h)
+		}
+	}
+
+	batch := make([]*importRow, 0, i.batchSize)
+	line := 1
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, &ImportRowError{Row: line, Message: err.Error()})
+			continue
+		}
+		result.Processed++
+
+		record, rowErrs := i.coerceRow(line, header, fields, values)
+		if len(rowErrs) > 0 {
+			result.Errors = append(result.Errors, rowErrs...)
+			continue
+		}
+
+		batch = append(batch, &importRow{line: line, record: record})
+		if len(batch) == i.batchSize {
+			if err := i.write(batch, result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := i.write(batch, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// mapColumn returns the field matching a header by explicit mapping, API name or label.
+func (i *Importer) mapColumn(header string) *SObjectFieldMetadata {
+	key := strings.ToLower(strings.TrimSpace(header))
+	if name, ok := i.columns[key]; ok {
+		return i.field(name)
+	}
+	if f := i.field(key); f != nil {
+		return f
+	}
+	for _, f := range i.meta.Fields {
+		if strings.EqualFold(strings.TrimSpace(f.GetLabel()), key) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (i *Importer) field(name string) *SObjectFieldMetadata {
+	return i.meta.FindField(name)
+}
+
+// coerceRow converts a CSV row into a record, collecting an error for every invalid value.
+func (i *Importer) coerceRow(line int, header []string, fields []*SObjectFieldMetadata, values []string) (SObject, []*ImportRowError) {
+	record := SObject{}
+	errs := make([]*ImportRowError, 0)
+
+	if len(values) != len(header) {
+		errs = append(errs, &ImportRowError{Row: line,
+			Message: fmt.Sprintf("expected %d columns but found %d", len(header), len(values))})
+		return nil, errs
+	}
+
+	for idx, raw := range values {
+		f := fields[idx]
+		if f == nil {
+			continue
+		}
+		v, err := CoerceFieldValue(f, raw, i.layouts...)
+		if err != nil {
+			errs = append(errs, &ImportRowError{Row: line, Column: header[idx], Field: f.GetName(), Message: err.Error()})
+			continue
+		}
+		if v != nil {
+			record[f.GetName()] = v
+		}
+	}
+
+	if i.operation == ImportOperations.Upsert && record[i.field(i.externalIDField).GetName()] == nil {
+		errs = append(errs, &ImportRowError{Row: line, Field: i.externalIDField,
+			Message: fmt.Sprintf("external ID field %s is empty", i.externalIDField)})
+	}
+	return record, errs
+}
+
+// write sends a batch and records each row's outcome.
+func (i *Importer) write(batch []*importRow, result *ImportResult) error {
+	records := make([]SObject, 0, len(batch))
+	for _, row := range batch {
+		records = append(records, row.record)
+	}
+
+	var results []*SObjectCollectionResult
+	var err error
+	switch i.operation {
+	case ImportOperations.Upsert:
+		results, _, err = i.client.UpsertCollection(i.meta.GetName(), i.externalIDField, records, i.allOrNone)
+	default:
+		results, _, err = i.client.CreateCollection(i.meta.GetName(), records, i.allOrNone)
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) != len(batch) {
+		return fmt.Errorf("expected %d results but received %d", len(batch), len(results))
+	}
+
+	for idx, r := range results {
+		row := batch[idx]
+		if !r.Success {
+			for _, e := range r.Errors {
+				result.Errors = append(result.Errors, &ImportRowError{Row: row.line,
+					Field: strings.Join(e.Fields, ","), Message: e.Error()})
+			}
+			continue
+		}
+
+		created := i.operation == ImportOperations.Create || r.GetCreated()
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.Records = append(result.Records, &ImportRecordResult{Row: row.line, ID: r.ID, Created: created})
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+)
+
+// Limit represents the maximum and remaining allocation of an org limit.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_limits.htm
+type Limit struct {
+	Max       int `json:"Max"`
+	Remaining int `json:"Remaining"`
+}
+
+// Limits lists the org's limits keyed by limit name, such as `DailyApiRequests`.
+func (c *Client) Limits() (map[string]*Limit, *simpleresty.Response, error) {
+	var result map[string]*Limit
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/limits", c.apiVersion))
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
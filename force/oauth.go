@@ -1,5 +1,7 @@
 package force
 
+import "fmt"
+
 // OAuthCredentials represents the credentials needed to initiate an OAuth request.
 type OAuthCredentials struct {
 	ClientID      string
@@ -40,6 +42,11 @@ var TokenErrorCodes = struct {
 	InvalidGrant:    "invalid_grant",
 }
 
+// Error returns the error code and description.
+func (e *TokenErrorResponse) Error() string {
+	return fmt.Sprintf("%s: %s", e.ErrorCode, e.Description)
+}
+
 // ToString is a helper method to return the string of a TokenErrorCode.
 func (s TokenErrorCode) ToString() string {
 	return string(s)
@@ -0,0 +1,171 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDeviceInterval is the delay between token requests of the device flow if the authorization response
+	// has no interval.
+	DefaultDeviceInterval = 5 * time.Second
+
+	// DefaultDeviceSlowDown is added to the delay between token requests of the device flow each time the token
+	// endpoint responds with slow_down.
+	DefaultDeviceSlowDown = 5 * time.Second
+)
+
+// DeviceTokenErrorCodes represents the error codes returned while polling the token endpoint of the device flow.
+var DeviceTokenErrorCodes = struct {
+	AuthorizationPending TokenErrorCode
+	SlowDown             TokenErrorCode
+	AccessDenied         TokenErrorCode
+	ExpiredToken         TokenErrorCode
+}{
+	AuthorizationPending: "authorization_pending",
+	SlowDown:             "slow_down",
+	AccessDenied:         "access_denied",
+	ExpiredToken:         "expired_token",
+}
+
+// DeviceAuthorization represents the response of a device authorization request. The user approves access by
+// entering UserCode at VerificationURI on another device.
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+
+	// Interval is the minimum number of seconds between token requests.
+	Interval int `json:"interval"`
+}
+
+// DeviceFlow implements the OAuth 2.0 device flow, used by command-line tools and devices with limited input.
+// The user approves access in a browser on another device while the token endpoint is polled.
+//
+// Reference: https://help.salesforce.com/articleView?id=remoteaccess_oauth_device_flow.htm&type=5
+type DeviceFlow struct {
+	// LoginURL defaults to DefaultLoginURL.
+	LoginURL string
+
+	ClientID string
+
+	// Scopes requested, such as `api` and `refresh_token`. Defaults to the scopes of the connected app.
+	Scopes []string
+
+	// Interval overrides the delay between token requests returned by the authorization request.
+	Interval time.Duration
+
+	// SlowDown is added to the delay between token requests on slow_down. Defaults to DefaultDeviceSlowDown.
+	SlowDown time.Duration
+
+	// Timeout is how long to poll before giving up. Defaults to DefaultPollTimeout.
+	Timeout time.Duration
+
+	// Transport, if set, is used for token requests.
+	Transport http.RoundTripper
+}
+
+// Authorize requests a device code and the user code to present to the user.
+func (f *DeviceFlow) Authorize() (*DeviceAuthorization, *TokenErrorResponse, error) {
+	params := map[string]string{"response_type": "device_code", "client_id": f.ClientID}
+	if len(f.Scopes) > 0 {
+		params["scope"] = strings.Join(f.Scopes, " ")
+	}
+
+	var result *DeviceAuthorization
+	var errResult *TokenErrorResponse
+	oClient := f.oauthClient()
+	_, err := oClient.R().
+		SetFormData(params).
+		SetHeaders(map[string]string{"Accept": MediaTypeJSON, "Content-Type": FormURLEncodedHeader}).
+		SetResult(&result).
+		SetError(&errResult).
+		Post(oClient.RequestURL("/services/oauth2/token"))
+	if err != nil || errResult != nil {
+		return nil, errResult, err
+	}
+	return result, nil, nil
+}
+
+// Poll requests a token until the user approves or denies access, the device code expires or the timeout elapses.
+// The delay between requests grows each time the token endpoint responds with slow_down.
+func (f *DeviceFlow) Poll(auth *DeviceAuthorization) (*TokenResponse, error) {
+	interval := f.Interval
+	if interval == 0 {
+		interval = time.Duration(auth.Interval) * time.Second
+	}
+	if interval == 0 {
+		interval = DefaultDeviceInterval
+	}
+	timeout := f.Timeout
+	if timeout == 0 {
+		timeout = DefaultPollTimeout
+	}
+	slowDown := f.SlowDown
+	if slowDown == 0 {
+		slowDown = DefaultDeviceSlowDown
+	}
+
+	poller, err := NewPoller(PollInterval(interval), PollTimeout(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{"grant_type": "device", "client_id": f.ClientID, "code": auth.DeviceCode}
+	var token *TokenResponse
+	err = poller.Poll(func() (bool, error) {
+		result, errResult, err := requestToken(f.oauthClient(), params)
+		switch {
+		case err != nil:
+			return false, err
+		case errResult == nil:
+			token = result
+			return true, nil
+		case errResult.ErrorCode == DeviceTokenErrorCodes.AuthorizationPending:
+			return false, nil
+		case errResult.ErrorCode == DeviceTokenErrorCodes.SlowDown:
+			poller.Interval += slowDown
+			return false, nil
+		}
+		return false, errResult
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Run requests a device code, calls prompt to present the user code and polls until the user approves access.
+func (f *DeviceFlow) Run(prompt func(*DeviceAuthorization) error) (*TokenResponse, error) {
+	auth, errResult, err := f.Authorize()
+	if err != nil {
+		return nil, err
+	}
+	if errResult != nil {
+		return nil, errResult
+	}
+	if err := prompt(auth); err != nil {
+		return nil, err
+	}
+	return f.Poll(auth)
+}
+
+func (f *DeviceFlow) oauthClient() *simpleresty.Client {
+	loginURL := DefaultLoginURL
+	if f.LoginURL != "" {
+		loginURL = strings.TrimSuffix(f.LoginURL, "/")
+	}
+	oClient := simpleresty.NewWithBaseURL(loginURL)
+	if f.Transport != nil {
+		oClient.SetTransport(f.Transport)
+	}
+	return oClient
+}
+
+// String returns the instructions to present to the user.
+func (a *DeviceAuthorization) String() string {
+	return fmt.Sprintf("To approve access, open %s and enter the code %s", a.VerificationURI, a.UserCode)
+}
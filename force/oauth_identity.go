@@ -0,0 +1,200 @@
+package force
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"time"
+)
+
+// Identity represents the user and org a token was issued for, as returned by the identity URL in TokenResponse.ID.
+//
+// Reference: https://help.salesforce.com/articleView?id=remoteaccess_using_openid.htm&type=5
+type Identity struct {
+	ID               string         `json:"id"`
+	AssertedUser     bool           `json:"asserted_user"`
+	UserID           string         `json:"user_id"`
+	OrganizationID   string         `json:"organization_id"`
+	Username         string         `json:"username"`
+	NickName         string         `json:"nick_name"`
+	DisplayName      string         `json:"display_name"`
+	Email            string         `json:"email"`
+	EmailVerified    bool           `json:"email_verified"`
+	FirstName        string         `json:"first_name"`
+	LastName         string         `json:"last_name"`
+	Timezone         string         `json:"timezone"`
+	Photos           IdentityPhotos `json:"photos"`
+	URLs             IdentityURLs   `json:"urls"`
+	Active           bool           `json:"active"`
+	UserType         string         `json:"user_type"`
+	Language         string         `json:"language"`
+	Locale           string         `json:"locale"`
+	UTCOffset        int            `json:"utcOffset"`
+	LastModifiedDate string         `json:"last_modified_date"`
+}
+
+// UserInfo represents the OpenID Connect claims of the authenticated user returned by the UserInfo endpoint.
+type UserInfo struct {
+	Sub               string         `json:"sub"`
+	UserID            string         `json:"user_id"`
+	OrganizationID    string         `json:"organization_id"`
+	PreferredUsername string         `json:"preferred_username"`
+	Nickname          string         `json:"nickname"`
+	Name              string         `json:"name"`
+	Email             string         `json:"email"`
+	EmailVerified     bool           `json:"email_verified"`
+	GivenName         string         `json:"given_name"`
+	FamilyName        string         `json:"family_name"`
+	Zoneinfo          string         `json:"zoneinfo"`
+	Photos            IdentityPhotos `json:"photos"`
+	Profile           string         `json:"profile"`
+	Picture           string         `json:"picture"`
+	URLs              IdentityURLs   `json:"urls"`
+	Active            bool           `json:"active"`
+	UserType          string         `json:"user_type"`
+	Language          string         `json:"language"`
+	Locale            string         `json:"locale"`
+	UTCOffset         int            `json:"utcOffset"`
+	UpdatedAt         string         `json:"updated_at"`
+}
+
+// IdentityPhotos holds the URLs of the user's profile photos.
+type IdentityPhotos struct {
+	Picture   string `json:"picture"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+// IdentityURLs holds the API endpoints of the org. The API endpoints contain a `{version}` placeholder for the
+// API version number.
+type IdentityURLs struct {
+	Enterprise   string `json:"enterprise"`
+	Metadata     string `json:"metadata"`
+	Partner      string `json:"partner"`
+	REST         string `json:"rest"`
+	SObjects     string `json:"sobjects"`
+	Search       string `json:"search"`
+	Query        string `json:"query"`
+	Recent       string `json:"recent"`
+	ToolingSOAP  string `json:"tooling_soap"`
+	ToolingREST  string `json:"tooling_rest"`
+	Profile      string `json:"profile"`
+	Feeds        string `json:"feeds"`
+	Groups       string `json:"groups"`
+	Users        string `json:"users"`
+	FeedItems    string `json:"feed_items"`
+	FeedElements string `json:"feed_elements"`
+	CustomDomain string `json:"custom_domain"`
+}
+
+// TokenIntrospection represents the state of a token as returned by the introspection endpoint. Only Active is set
+// for tokens that are expired, revoked or unknown.
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope"`
+	ClientID  string `json:"client_id"`
+	Username  string `json:"username"`
+	Sub       string `json:"sub"`
+	TokenType string `json:"token_type"`
+
+	// Exp, Iat and Nbf are the expiry, issue and not-before times in seconds since the Unix epoch.
+	Exp int64 `json:"exp"`
+	Iat int64 `json:"iat"`
+	Nbf int64 `json:"nbf"`
+}
+
+// ExpiresAt returns the expiry time of the token, or the zero time if it is unknown.
+func (t *TokenIntrospection) ExpiresAt() time.Time {
+	if t.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(t.Exp, 0)
+}
+
+// VerifySignature checks the signature of the token response, a base64 HMAC-SHA256 of the ID and issue time keyed
+// with the client secret of the connected app, which proves the response came from Salesforce unaltered.
+func (t *TokenResponse) VerifySignature(clientSecret string) error {
+	if t.GetSignature() == "" {
+		return fmt.Errorf("token response has no signature")
+	}
+	signature, err := base64.StdEncoding.DecodeString(t.GetSignature())
+	if err != nil {
+		return fmt.Errorf("unable to decode token response signature: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(clientSecret))
+	mac.Write([]byte(t.GetID() + t.GetIssuedAt()))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("token response signature does not match the client secret")
+	}
+	return nil
+}
+
+// Identity returns the user and org the client authenticated as, fetched from the identity URL of the token
+// response. Clients created with an access token alone have no identity URL and should use UserInfo instead.
+func (c *Client) Identity() (*Identity, *simpleresty.Response, error) {
+	if c.token.GetID() == "" {
+		return nil, nil, fmt.Errorf("the client has no token response with an identity URL")
+	}
+
+	var result *Identity
+	response, getErr := c.http.Get(c.token.GetID(), &result, nil)
+	if getErr != nil {
+		return nil, response, getErr
+	}
+
+	return result, response, nil
+}
+
+// UserInfo returns the OpenID Connect claims of the user the client authenticated as.
+func (c *Client) UserInfo() (*UserInfo, *simpleresty.Response, error) {
+	var result *UserInfo
+	urlStr := c.http.RequestURL("/services/oauth2/userinfo")
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, response, getErr
+	}
+
+	return result, response, nil
+}
+
+// Revoke revokes an access or refresh token. Revoking a refresh token also revokes the access tokens issued with it,
+// and revoking the client's own access token logs the client out.
+func (c *Client) Revoke(token string) (*simpleresty.Response, error) {
+	req := c.http.R().
+		SetFormData(map[string]string{"token": token}).
+		SetHeader("Content-Type", FormURLEncodedHeader)
+	req.Method = simpleresty.PostMethod
+	req.URL = c.http.RequestURL("/services/oauth2/revoke")
+
+	return c.http.Dispatch(req)
+}
+
+// Introspect returns the state of an access or refresh token. The introspection endpoint authenticates the connected
+// app, so the client must be configured with its client ID and secret.
+func (c *Client) Introspect(token string) (*TokenIntrospection, *simpleresty.Response, error) {
+	if c.oauthCred == nil || c.oauthCred.ClientID == "" || c.oauthCred.ClientSecret == "" {
+		return nil, nil, fmt.Errorf("token introspection requires the client ID and secret of the connected app")
+	}
+
+	var result *TokenIntrospection
+	req := c.http.R().
+		SetFormData(map[string]string{
+			"token":         token,
+			"client_id":     c.oauthCred.ClientID,
+			"client_secret": c.oauthCred.ClientSecret,
+		}).
+		SetHeader("Content-Type", FormURLEncodedHeader).
+		SetResult(&result)
+	req.Method = simpleresty.PostMethod
+	req.URL = c.http.RequestURL("/services/oauth2/introspect")
+
+	response, err := c.http.Dispatch(req)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return result, response, nil
+}
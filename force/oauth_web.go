@@ -0,0 +1,166 @@
+package force
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PKCEMethodS256 is the code challenge method of a PKCE challenge derived with SHA-256.
+const PKCEMethodS256 = "S256"
+
+// PKCE holds a Proof Key for Code Exchange, which binds an authorization code to the party that requested it.
+// The challenge is sent with the authorization request and the verifier when exchanging the code.
+//
+// Reference: https://tools.ietf.org/html/rfc7636
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE returns a PKCE with a random verifier and its S256 challenge.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := randomURLSafeString(32)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		Method:    PKCEMethodS256,
+	}, nil
+}
+
+// NewOAuthState returns a random value for the state parameter of an authorization request, which the callback
+// must return unchanged to guard against cross-site request forgery.
+func NewOAuthState() (string, error) {
+	return randomURLSafeString(24)
+}
+
+// WebServerFlow implements the OAuth 2.0 web server flow, in which a user approves access in the browser and
+// Salesforce redirects back with an authorization code that is exchanged for a token.
+//
+//	flow := &force.WebServerFlow{ClientID: id, ClientSecret: secret, RedirectURI: "https://tool.example.com/callback"}
+//	http.Redirect(w, r, flow.AuthorizeURL(state, pkce), http.StatusFound)
+//
+//	// In the callback handler:
+//	token, err := flow.HandleCallback(r, state, pkce)
+//	client, err := force.New(force.OAuthToken(token))
+//
+// Reference: https://help.salesforce.com/articleView?id=remoteaccess_oauth_web_server_flow.htm&type=5
+type WebServerFlow struct {
+	// LoginURL defaults to DefaultLoginURL.
+	LoginURL string
+
+	ClientID string
+
+	// ClientSecret may be empty if the connected app doesn't require a secret for the web server flow, in which
+	// case a PKCE should be used.
+	ClientSecret string
+
+	// RedirectURI must match a callback URL of the connected app.
+	RedirectURI string
+
+	// Scopes requested, such as `api` and `refresh_token`. Defaults to the scopes of the connected app.
+	Scopes []string
+
+	// Transport, if set, is used for token requests.
+	Transport http.RoundTripper
+}
+
+// AuthorizeURL returns the URL to send the user to in order to approve access. The PKCE may be nil.
+func (f *WebServerFlow) AuthorizeURL(state string, pkce *PKCE) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", f.ClientID)
+	params.Set("redirect_uri", f.RedirectURI)
+	if state != "" {
+		params.Set("state", state)
+	}
+	if len(f.Scopes) > 0 {
+		params.Set("scope", strings.Join(f.Scopes, " "))
+	}
+	if pkce != nil {
+		params.Set("code_challenge", pkce.Challenge)
+		params.Set("code_challenge_method", pkce.Method)
+	}
+	return f.loginURL() + "/services/oauth2/authorize?" + params.Encode()
+}
+
+// Exchange exchanges an authorization code for a token. The PKCE must be the one the code was requested with, or
+// nil if none was used.
+func (f *WebServerFlow) Exchange(code string, pkce *PKCE) (*TokenResponse, *TokenErrorResponse, error) {
+	params := map[string]string{
+		"grant_type":   "authorization_code",
+		"code":         code,
+		"client_id":    f.ClientID,
+		"redirect_uri": f.RedirectURI,
+	}
+	if f.ClientSecret != "" {
+		params["client_secret"] = f.ClientSecret
+	}
+	if pkce != nil {
+		params["code_verifier"] = pkce.Verifier
+	}
+	return requestToken(f.oauthClient(), params)
+}
+
+// HandleCallback checks the state of a request to the redirect URI and exchanges its authorization code for a
+// token. The state is required, as it guards against cross-site request forgery. A denied authorization or failed
+// exchange is returned as a *TokenErrorResponse.
+func (f *WebServerFlow) HandleCallback(r *http.Request, state string, pkce *PKCE) (*TokenResponse, error) {
+	if state == "" {
+		return nil, fmt.Errorf("the state of the authorization request is required to check the OAuth callback")
+	}
+	query := r.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+		return nil, fmt.Errorf("state of the OAuth callback does not match the authorization request")
+	}
+	if code := query.Get("error"); code != "" {
+		return nil, &TokenErrorResponse{ErrorCode: TokenErrorCode(code), Description: query.Get("error_description")}
+	}
+	if query.Get("code") == "" {
+		return nil, fmt.Errorf("OAuth callback has no authorization code")
+	}
+
+	token, tokenErr, err := f.Exchange(query.Get("code"), pkce)
+	if err != nil {
+		return nil, err
+	}
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	return token, nil
+}
+
+func (f *WebServerFlow) loginURL() string {
+	if f.LoginURL == "" {
+		return DefaultLoginURL
+	}
+	return strings.TrimSuffix(f.LoginURL, "/")
+}
+
+func (f *WebServerFlow) oauthClient() *simpleresty.Client {
+	oClient := simpleresty.NewWithBaseURL(f.loginURL())
+	if f.Transport != nil {
+		oClient.SetTransport(f.Transport)
+	}
+	return oClient
+}
+
+// randomURLSafeString returns n random bytes encoded as unpadded URL-safe base64.
+func randomURLSafeString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+package force
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ValidForIndexes decodes the validFor bitmap of a dependent picklist entry and returns the indexes of the
+// controlling values the entry is valid for. For a picklist controller, an index is the position of a value in
+// the controller's PicklistValues. For a checkbox controller, index 0 is false and 1 is true.
+func (e *SObjectFieldPicklistEntryMetadata) ValidForIndexes() ([]int, error) {
+	bitmap, err := base64.StdEncoding.DecodeString(e.GetValidFor())
+	if err != nil {
+		return nil, fmt.Errorf("invalid validFor of picklist value %s: %v", e.GetValue(), err)
+	}
+
+	// Bits are read from the most significant bit of the first byte.
+	indexes := make([]int, 0)
+	for i := 0; i < len(bitmap)*8; i++ {
+		if bitmap[i/8]&(0x80>>uint(i%8)) != 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// IsValidFor reports whether a dependent picklist entry is valid for the controlling value at index.
+// See ValidForIndexes.
+func (e *SObjectFieldPicklistEntryMetadata) IsValidFor(index int) (bool, error) {
+	indexes, err := e.ValidForIndexes()
+	if err != nil {
+		return false, err
+	}
+	for _, i := range indexes {
+		if i == index {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ControllingIndex returns the index of a controlling value in the validFor bitmaps of the field's dependent
+// picklists: the position of a picklist value, or 0 for false and 1 for true if the field is a checkbox.
+func (f *SObjectFieldMetadata) ControllingIndex(value interface{}) (int, error) {
+	if f.Type == FieldDataTypes.Boolean {
+		b, ok := value.(bool)
+		if !ok && value != nil {
+			return -1, fmt.Errorf("%v is not a valid value of checkbox %s", value, f.GetName())
+		}
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	s, _ := value.(string)
+	for i, entry := range f.PicklistValues {
+		if entry.GetValue() == s {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%v is not a value of picklist %s", value, f.GetName())
+}
+
+// DependentPicklistValues returns the values of a dependent picklist field that are valid for a value of its
+// controlling field, which is a string for a picklist controller or a bool for a checkbox controller.
+// Inactive values are omitted.
+func (s *SObjectMetadata) DependentPicklistValues(fieldName string, controllingValue interface{}) ([]string, error) {
+	field := s.FindField(fieldName)
+	if field == nil {
+		return nil, fmt.Errorf("field %s does not exist on %s", fieldName, s.GetName())
+	}
+	if !field.GetDependentPicklist() || field.GetControllerName() == "" {
+		return nil, fmt.Errorf("field %s on %s is not a dependent picklist", field.GetName(), s.GetName())
+	}
+	controller := s.FindField(field.GetControllerName())
+	if controller == nil {
+		return nil, fmt.Errorf("controlling field %s does not exist on %s", field.GetControllerName(), s.GetName())
+	}
+
+	index, err := controller.ControllingIndex(controllingValue)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0)
+	for _, entry := range field.PicklistValues {
+		if entry.Active != nil && !entry.GetActive() {
+			continue
+		}
+		valid, err := entry.IsValidFor(index)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			values = append(values, entry.GetValue())
+		}
+	}
+	return values, nil
+}
+
+// validateDependentPicklists checks that the dependent picklist values of a record are valid for the values of
+// their controlling fields. On create, a controlling field that isn't supplied takes its default value. On update,
+// it is left unchecked. Invalid validFor bitmaps are returned as an error.
+func (v *Validator) validateDependentPicklists(fields SObject, create bool) ([]*SObjectError, error) {
+	supplied := map[string]interface{}{}
+	for name, value := range fields {
+		supplied[strings.ToLower(name)] = value
+	}
+
+	errs := make([]*SObjectError, 0)
+	for _, f := range v.meta.Fields {
+		value, ok := supplied[strings.ToLower(f.GetName())]
+		s, _ := value.(string)
+		if !ok || s == "" || !f.GetDependentPicklist() || f.GetControllerName() == "" {
+			continue
+		}
+		controller := v.meta.FindField(f.GetControllerName())
+		if controller == nil {
+			continue
+		}
+		controllingValue, ok := supplied[strings.ToLower(controller.GetName())]
+		if !ok {
+			if !create {
+				continue
+			}
+			if controllingValue, ok = controllerDefault(controller); !ok {
+				continue
+			}
+		}
+
+		// No dependent value is valid for a controlling value that isn't one of the controller's values.
+		allowed := map[string]bool{}
+		if _, err := controller.ControllingIndex(controllingValue); err == nil {
+			valid, err := v.meta.DependentPicklistValues(f.GetName(), controllingValue)
+			if err != nil {
+				return nil, err
+			}
+			for _, value := range valid {
+				allowed[value] = true
+			}
+		}
+
+		values := []string{s}
+		if f.Type == FieldDataTypes.Multipicklist {
+			values = strings.Split(s, ";")
+		}
+		for _, value := range values {
+			if !allowed[value] {
+				errs = append(errs, &SObjectError{StatusCode: "FIELD_INTEGRITY_EXCEPTION",
+					Message: fmt.Sprintf("%s: value %s is not valid for %s = %v", f.GetName(), value,
+						controller.GetName(), controllingValue),
+					Fields: []string{f.GetName()}})
+				break
+			}
+		}
+	}
+	return errs, nil
+}
+
+// controllerDefault returns the value a controlling field takes when a record is created without it: the default
+// of a checkbox, or the default value of a picklist, if any. It returns false if the default is a formula.
+func controllerDefault(controller *SObjectFieldMetadata) (interface{}, bool) {
+	if controller.GetDefaultValueFormula() != "" {
+		return nil, false
+	}
+	if controller.Type == FieldDataTypes.Boolean {
+		b, _ := controller.DefaultValue.(bool)
+		return b, true
+	}
+	for _, entry := range controller.PicklistValues {
+		if entry.GetDefaultValue() && (entry.Active == nil || entry.GetActive()) {
+			return entry.GetValue(), true
+		}
+	}
+	return nil, true
+}
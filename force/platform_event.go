@@ -0,0 +1,149 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"strings"
+)
+
+// PlatformEventSuffix is the API name suffix of platform event objects.
+const PlatformEventSuffix = "__e"
+
+// PublishResult represents the result of publishing a single platform event.
+type PublishResult struct {
+	// ID is the ID of the published event.
+	ID string `json:"id,omitempty"`
+
+	// Success indicates whether the event was accepted for publishing.
+	Success bool `json:"success"`
+
+	// ReplayID is the replay ID of the event, if the server includes it in the response. Salesforce's REST API
+	// doesn't, in which case the replay ID is only available to subscribers of the event channel.
+	ReplayID *int64 `json:"replayId,omitempty"`
+
+	// Errors holds any errors. Salesforce may report an `OPERATION_ENQUEUED` status on successful publishes.
+	Errors []*SObjectError `json:"errors,omitempty"`
+}
+
+// PublishEvent publishes a platform event. The payload can be a SObject, a map or a struct with json tags.
+//
+// The event name must be a platform event object, such as `Order_Placed__e`, and every payload field must exist
+// on it. Both are validated against the object's describe metadata, which is cached by the Client.
+func (c *Client) PublishEvent(eventName string, payload interface{}) (*PublishResult, *simpleresty.Response, error) {
+	meta, record, err := c.platformEvent(eventName, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result *PublishResult
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/sobjects/%s", c.apiVersion, meta.GetName()))
+
+	response, err := c.http.Post(urlStr, &result, record)
+	return result, response, err
+}
+
+// PublishEvents publishes multiple platform events of the same type using sObject collections. Payloads are
+// sent in batches of up to MaxCollectionSize and a result is returned for each payload in order.
+//
+// Batches are published independently, so an error from a later batch doesn't undo earlier ones. The Response
+// of the last batch is returned.
+func (c *Client) PublishEvents(eventName string, payloads ...interface{}) ([]*PublishResult, *simpleresty.Response, error) {
+	records := make([]SObject, 0, len(payloads))
+	var meta *SObjectMetadata
+	for _, payload := range payloads {
+		m, record, err := c.platformEvent(eventName, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta = m
+		records = append(records, withTypeAttribute(m.GetName(), []SObject{record})[0])
+	}
+	if meta == nil {
+		return []*PublishResult{}, nil, nil
+	}
+
+	results := make([]*PublishResult, 0, len(records))
+	var response *simpleresty.Response
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/composite/sobjects", c.apiVersion))
+	for start := 0; start < len(records); start += MaxCollectionSize {
+		end := start + MaxCollectionSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		var batch []*PublishResult
+		var err error
+		response, err = c.http.Post(urlStr, &batch, &SObjectCollectionRequest{Records: records[start:end]})
+		if err != nil {
+			return results, response, err
+		}
+		results = append(results, batch...)
+	}
+	return results, response, nil
+}
+
+// platformEvent validates the event against its describe metadata and converts the payload to a SObject.
+func (c *Client) platformEvent(eventName string, payload interface{}) (*SObjectMetadata, SObject, error) {
+	if !strings.HasSuffix(strings.ToLower(eventName), PlatformEventSuffix) {
+		return nil, nil, fmt.Errorf("%s is not a platform event: name must end with %s", eventName, PlatformEventSuffix)
+	}
+
+	meta, err := c.describeEvent(eventName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err := toSObject(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode %s payload: %v", eventName, err)
+	}
+	delete(record, "attributes")
+
+	for name := range record {
+		f := meta.FindField(name)
+		if f == nil {
+			return nil, nil, fmt.Errorf("field %s does not exist on %s", name, meta.GetName())
+		}
+		if !f.GetCreateable() {
+			return nil, nil, fmt.Errorf("field %s on %s cannot be published", name, meta.GetName())
+		}
+	}
+	return meta, record, nil
+}
+
+// describeEvent returns the cached describe metadata of a platform event, describing it on first use.
+func (c *Client) describeEvent(eventName string) (*SObjectMetadata, error) {
+	meta, err := c.describeCached(eventName)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(strings.ToLower(meta.GetName()), PlatformEventSuffix) {
+		return nil, fmt.Errorf("%s is not a platform event", meta.GetName())
+	}
+	return meta, nil
+}
+
+// toSObject converts a SObject, map or struct to a SObject using its JSON representation.
+func toSObject(v interface{}) (SObject, error) {
+	if s, ok := v.(SObject); ok {
+		out := make(SObject, len(s))
+		for k, val := range s {
+			out[k] = val
+		}
+		return out, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out SObject
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = SObject{}
+	}
+	return out, nil
+}
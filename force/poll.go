@@ -0,0 +1,77 @@
+package force
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is the delay between checks of a long running operation.
+	DefaultPollInterval = 2 * time.Second
+
+	// DefaultPollTimeout is how long a long running operation is polled before giving up.
+	DefaultPollTimeout = 10 * time.Minute
+)
+
+// Poller repeatedly checks a long running operation, such as an asynchronous Apex test run, until it's done.
+type Poller struct {
+	// Interval is the delay between checks.
+	Interval time.Duration
+
+	// Timeout is how long to poll before giving up. Zero polls forever.
+	Timeout time.Duration
+}
+
+// PollOption is a functional option for configuring a Poller.
+type PollOption func(*Poller) error
+
+// PollInterval sets the delay between checks.
+func PollInterval(interval time.Duration) PollOption {
+	return func(p *Poller) error {
+		if interval < 0 {
+			return fmt.Errorf("poll interval cannot be negative")
+		}
+		p.Interval = interval
+		return nil
+	}
+}
+
+// PollTimeout sets how long to poll before giving up. Zero polls forever.
+func PollTimeout(timeout time.Duration) PollOption {
+	return func(p *Poller) error {
+		if timeout < 0 {
+			return fmt.Errorf("poll timeout cannot be negative")
+		}
+		p.Timeout = timeout
+		return nil
+	}
+}
+
+// NewPoller returns a Poller using DefaultPollInterval and DefaultPollTimeout unless overridden.
+func NewPoller(opts ...PollOption) (*Poller, error) {
+	p := &Poller{Interval: DefaultPollInterval, Timeout: DefaultPollTimeout}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Poll calls check until it reports the operation is done, returns an error or the timeout elapses.
+func (p *Poller) Poll(check func() (bool, error)) error {
+	start := time.Now()
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if p.Timeout > 0 && time.Since(start)+p.Interval > p.Timeout {
+			return fmt.Errorf("operation did not complete within %s", p.Timeout)
+		}
+		time.Sleep(p.Interval)
+	}
+}
@@ -0,0 +1,98 @@
+package force
+
+// QueryIterator iterates over every record of a SOQL query, fetching additional pages as needed.
+//
+//	iter := client.Iterate(&force.QueryRequest{SOQL: "select Id, Name from Account"})
+//	for iter.Next() {
+//		record := iter.Record()
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type QueryIterator struct {
+	client  *Client
+	request *QueryRequest
+
+	// page is the current page of results.
+	page *QueryResult
+
+	// index is the position of the current record within page.
+	index int
+
+	// err is the first error encountered while fetching pages.
+	err error
+}
+
+// Iterate returns a QueryIterator for the query. No request is made until Next is called.
+func (c *Client) Iterate(q *QueryRequest) *QueryIterator {
+	return &QueryIterator{client: c, request: q, index: -1}
+}
+
+// Next advances to the next record, fetching the next page when the current one is exhausted.
+// It returns false when there are no more records or an error occurred.
+func (i *QueryIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+
+	i.index++
+	for i.page == nil || i.index >= len(i.page.Records) {
+		if !i.NextPage() {
+			return false
+		}
+		i.index = 0
+	}
+
+	return true
+}
+
+// NextPage fetches the next page of results, discarding any unread records of the current page.
+// It returns false when there are no more pages or an error occurred.
+func (i *QueryIterator) NextPage() bool {
+	if i.err != nil {
+		return false
+	}
+
+	var result *QueryResult
+	switch {
+	case i.page == nil:
+		result, _, i.err = i.client.Query(i.request)
+	case !i.page.Done && i.page.NextRecordsURL != "":
+		result, _, i.err = i.client.QueryMore(i.page.NextRecordsURL)
+	default:
+		return false
+	}
+	if i.err != nil {
+		return false
+	}
+
+	i.page = result
+	i.index = -1
+	return true
+}
+
+// Record returns the current record.
+func (i *QueryIterator) Record() SObject {
+	if i.page == nil || i.index < 0 || i.index >= len(i.page.Records) {
+		return nil
+	}
+	return i.page.Records[i.index]
+}
+
+// Page returns the current page of results.
+func (i *QueryIterator) Page() *QueryResult {
+	return i.page
+}
+
+// TotalSize returns the total number of records matched by the query once the first page has been fetched.
+func (i *QueryIterator) TotalSize() int {
+	if i.page == nil {
+		return 0
+	}
+	return i.page.TotalSize
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (i *QueryIterator) Err() error {
+	return i.err
+}
@@ -0,0 +1,205 @@
+package force
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MasterRecordTypeID is the ID of the master record type, used by objects without record types.
+const MasterRecordTypeID = "012000000000000AAA"
+
+// UIPicklistValues represents the values of a picklist field available to a record type, as returned by the
+// User Interface API.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.uiapi.meta/uiapi/ui_api_resources_picklist_values.htm
+type UIPicklistValues struct {
+	// ControllerValues maps the values of the controlling field to the indexes used by ValidFor.
+	ControllerValues map[string]int     `json:"controllerValues"`
+	DefaultValue     *UIPicklistValue   `json:"defaultValue"`
+	URL              string             `json:"url,omitempty"`
+	Values           []*UIPicklistValue `json:"values"`
+}
+
+// UIPicklistValue represents a single picklist value available to a record type.
+type UIPicklistValue struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+
+	// ValidFor holds the indexes of the controlling values the value is valid for. See ControllerValues.
+	ValidFor []int `json:"validFor"`
+}
+
+// UIPicklistFieldValues represents the values of every picklist field of a record type.
+type UIPicklistFieldValues struct {
+	PicklistFieldValues map[string]*UIPicklistValues `json:"picklistFieldValues"`
+}
+
+// FindValue returns the picklist value, or nil if it isn't available.
+func (p *UIPicklistValues) FindValue(value string) *UIPicklistValue {
+	for _, v := range p.Values {
+		if v.Value == value {
+			return v
+		}
+	}
+	return nil
+}
+
+// FindRecordType returns the record type with the developer name, ignoring case, or nil if there is none.
+func (s *SObjectMetadata) FindRecordType(developerName string) *SObjectRecordTypeInfoMetadata {
+	for _, rt := range s.RecordTypeInfos {
+		if strings.EqualFold(rt.GetDeveloperName(), developerName) {
+			return rt
+		}
+	}
+	return nil
+}
+
+// DefaultRecordType returns the default record type of the current user, or nil if there is none.
+func (s *SObjectMetadata) DefaultRecordType() *SObjectRecordTypeInfoMetadata {
+	for _, rt := range s.RecordTypeInfos {
+		if rt.GetDefaultRecordTypeMapping() {
+			return rt
+		}
+	}
+	return nil
+}
+
+// MasterRecordType returns the master record type, or nil if there is none.
+func (s *SObjectMetadata) MasterRecordType() *SObjectRecordTypeInfoMetadata {
+	for _, rt := range s.RecordTypeInfos {
+		if rt.GetMaster() {
+			return rt
+		}
+	}
+	return nil
+}
+
+// RecordTypeID returns the ID of a SObject's record type from its developer name, such as `Enterprise`.
+// The SObject is described on first use and cached by the client.
+func (c *Client) RecordTypeID(objectName, developerName string) (string, error) {
+	meta, err := c.describeCached(objectName)
+	if err != nil {
+		return "", err
+	}
+
+	rt := meta.FindRecordType(developerName)
+	if rt == nil {
+		return "", fmt.Errorf("record type %s does not exist on %s", developerName, meta.GetName())
+	}
+	if !rt.GetAvailable() {
+		return "", fmt.Errorf("record type %s on %s is not available to the current user", developerName,
+			meta.GetName())
+	}
+	return rt.GetRecordTypeId(), nil
+}
+
+// DefaultRecordTypeID returns the ID of the current user's default record type of a SObject, which is the
+// master record type if the SObject has no other record types.
+func (c *Client) DefaultRecordTypeID(objectName string) (string, error) {
+	meta, err := c.describeCached(objectName)
+	if err != nil {
+		return "", err
+	}
+
+	if rt := meta.DefaultRecordType(); rt != nil {
+		return rt.GetRecordTypeId(), nil
+	}
+	if rt := meta.MasterRecordType(); rt != nil {
+		return rt.GetRecordTypeId(), nil
+	}
+	return MasterRecordTypeID, nil
+}
+
+// ResolveRecordType replaces a record type referenced by developer name, such as
+// `"RecordType": {"DeveloperName": "Enterprise"}`, with the record type's `RecordTypeId` so the record can be
+// written. Records without such a reference, or whose record type can't be resolved, are left unchanged.
+func (c *Client) ResolveRecordType(objectName string, record SObject) error {
+	var key string
+	var reference interface{}
+	for k, v := range record {
+		if strings.EqualFold(k, "RecordType") {
+			key, reference = k, v
+			break
+		}
+	}
+	if reference == nil {
+		return nil
+	}
+
+	var developerName string
+	switch r := reference.(type) {
+	case SObject:
+		developerName, _ = r["DeveloperName"].(string)
+	case map[string]interface{}:
+		developerName, _ = r["DeveloperName"].(string)
+	}
+	if developerName == "" {
+		return fmt.Errorf("record type of %s must be referenced by DeveloperName", objectName)
+	}
+
+	id, err := c.RecordTypeID(objectName, developerName)
+	if err != nil {
+		return err
+	}
+	delete(record, key)
+	record["RecordTypeId"] = id
+	return nil
+}
+
+// RecordTypePicklistValues returns the values of every picklist field of a SObject available to a record type,
+// keyed by field name. The record type is a developer name or an ID. Values are cached by the client.
+func (c *Client) RecordTypePicklistValues(objectName, recordType string) (map[string]*UIPicklistValues, error) {
+	recordTypeID := recordType
+	if !ValidID(recordType) {
+		id, err := c.RecordTypeID(objectName, recordType)
+		if err != nil {
+			return nil, err
+		}
+		recordTypeID = id
+	}
+
+	key := strings.ToLower(objectName) + "/" + recordTypeID
+	c.cacheMu.Lock()
+	values, ok := c.picklistCache[key]
+	c.cacheMu.Unlock()
+	if ok {
+		return values, nil
+	}
+
+	var result *UIPicklistFieldValues
+	urlStr := c.http.RequestURL(fmt.Sprintf("/services/data/%s/ui-api/object-info/%s/picklist-values/%s",
+		c.apiVersion, objectName, recordTypeID))
+	if _, err := c.http.Get(urlStr, &result, nil); err != nil {
+		return nil, err
+	}
+
+	c.cacheMu.Lock()
+	c.picklistCache[key] = result.PicklistFieldValues
+	c.cacheMu.Unlock()
+	return result.PicklistFieldValues, nil
+}
+
+// RecordTypePicklistField returns the values of a picklist field available to a record type.
+// See RecordTypePicklistValues.
+func (c *Client) RecordTypePicklistField(objectName, recordType, fieldName string) (*UIPicklistValues, error) {
+	values, err := c.RecordTypePicklistValues(objectName, recordType)
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range values {
+		if strings.EqualFold(name, fieldName) {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("picklist field %s does not exist on %s", fieldName, objectName)
+}
+
+// ClearMetadataCache discards the describes, record types, picklist values and key prefixes cached by the client,
+// such as after deploying metadata changes.
+func (c *Client) ClearMetadataCache() {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.describeCache = map[string]*SObjectMetadata{}
+	c.picklistCache = map[string]map[string]*UIPicklistValues{}
+	c.keyPrefixes = nil
+}
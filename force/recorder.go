@@ -0,0 +1,396 @@
+package force
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecorderMode determines whether a Recorder captures live traffic or replays a cassette.
+type RecorderMode int
+
+const (
+	// RecorderModeRecord sends requests to Salesforce and saves every interaction to the cassette.
+	RecorderModeRecord RecorderMode = iota
+
+	// RecorderModeReplay serves responses from the cassette without any network access.
+	RecorderModeReplay
+)
+
+// ScrubbedValue replaces sensitive values stored in a cassette.
+const ScrubbedValue = "REDACTED"
+
+// BodyEncodingBase64 marks a recorded body that isn't valid UTF-8, such as a downloaded file or gzip data, as
+// base64 encoded. Such bodies are stored as is, without scrubbing.
+const BodyEncodingBase64 = "base64"
+
+// DefaultScrubFields are the JSON keys and form parameters scrubbed from every cassette: credentials, and the contact
+// details of records and identity responses.
+var DefaultScrubFields = []string{
+	"access_token", "refresh_token", "signature", "client_id", "client_secret", "username", "password",
+	"email", "phone", "mobilephone", "homephone", "otherphone", "fax", "preferred_username", "nickname", "nick_name",
+	"display_name", "first_name", "last_name", "given_name", "family_name", "user_id", "organization_id",
+}
+
+// identityURLPattern matches the org and user IDs of an identity URL, such as the `id` of a token response.
+var identityURLPattern = regexp.MustCompile(`/id/00D[0-9A-Za-z]{12,15}/005[0-9A-Za-z]{12,15}`)
+
+// orgIDPattern matches 15 and 18 character org IDs, such as in the SOAP endpoints of an identity response.
+var orgIDPattern = regexp.MustCompile(`\b00D[0-9A-Za-z]{12}(?:[0-9A-Za-z]{3})?\b`)
+
+// Cassette is a recorded series of HTTP interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded HTTP request and its response.
+type Interaction struct {
+	Request  *RecordedRequest  `json:"request"`
+	Response *RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the scrubbed and normalized form of a request used for matching during replay.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+
+	// BodyEncoding is BodyEncodingBase64 if the body isn't text, and empty otherwise.
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+}
+
+// RecordedResponse is the scrubbed form of a response.
+type RecordedResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+
+	// BodyEncoding is BodyEncodingBase64 if the body isn't text, such as a downloaded file, and empty otherwise.
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+}
+
+// Recorder is a http.RoundTripper that records interactions to, or replays them from, a cassette file.
+//
+// Requests are matched by method, path, normalized query string and normalized body. Identical requests
+// are replayed in the order they were recorded, with the last matching response reused once exhausted.
+type Recorder struct {
+	// mu protects the cassette and used interactions.
+	mu sync.Mutex
+
+	// mode is either record or replay.
+	mode RecorderMode
+
+	// path is the cassette file location.
+	path string
+
+	// transport sends requests while recording.
+	transport http.RoundTripper
+
+	// cassette holds the recorded interactions.
+	cassette *Cassette
+
+	// used tracks which interactions have been replayed.
+	used map[int]bool
+
+	// scrubFields are the lower-cased JSON keys and form parameters to scrub.
+	scrubFields map[string]bool
+
+	// scrubPatterns are applied to URLs and bodies after field scrubbing.
+	scrubPatterns []*scrubPattern
+}
+
+type scrubPattern struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// RecorderOption is a functional option for configuring a Recorder.
+type RecorderOption func(*Recorder)
+
+// ScrubFields scrubs the values of additional JSON keys and form parameters, such as 'MailingStreet'.
+// Names are matched case-insensitively.
+func ScrubFields(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, n := range names {
+			r.scrubFields[strings.ToLower(n)] = true
+		}
+	}
+}
+
+// ScrubPattern replaces all matches of a regular expression in recorded URLs and bodies.
+func ScrubPattern(pattern *regexp.Regexp, replacement string) RecorderOption {
+	return func(r *Recorder) {
+		r.scrubPatterns = append(r.scrubPatterns, &scrubPattern{pattern: pattern, replacement: replacement})
+	}
+}
+
+// RecorderTransport sets the transport used to send requests while recording. Defaults to http.DefaultTransport.
+func RecorderTransport(t http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = t
+	}
+}
+
+// NewRecorder returns a Recorder for the cassette file at path.
+//
+// In record mode any existing cassette is overwritten. In replay mode the cassette must exist.
+//
+// Cassettes are scrubbed of DefaultScrubFields, the org ID and the user ID of identity URLs. Other personal data,
+// such as the names and addresses of records, and record IDs, including user IDs such as OwnerId, are kept unless
+// scrubbed with ScrubFields or ScrubPattern.
+func NewRecorder(path string, mode RecorderMode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		mode:        mode,
+		path:        path,
+		transport:   http.DefaultTransport,
+		cassette:    &Cassette{Interactions: []*Interaction{}},
+		used:        map[int]bool{},
+		scrubFields: map[string]bool{},
+	}
+	ScrubFields(DefaultScrubFields...)(r)
+	ScrubPattern(identityURLPattern, "/id/"+ScrubbedValue+"/"+ScrubbedValue)(r)
+	ScrubPattern(orgIDPattern, ScrubbedValue)(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	switch mode {
+	case RecorderModeRecord:
+		if err := r.save(); err != nil {
+			return nil, err
+		}
+	case RecorderModeReplay:
+		data, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			return nil, fmt.Errorf("unable to read cassette: %v", readErr)
+		}
+		if err := json.Unmarshal(data, r.cassette); err != nil {
+			return nil, fmt.Errorf("unable to parse cassette %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid recorder mode %d", mode)
+	}
+
+	return r, nil
+}
+
+// Cassette returns the recorded interactions.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, readErr := readBody(req)
+	if readErr != nil {
+		return nil, readErr
+	}
+	recorded := &RecordedRequest{Method: req.Method, URL: r.scrubURL(req.URL)}
+	recorded.Body, recorded.BodyEncoding = r.recordBody(req.Header.Get("Content-Type"), body)
+
+	if r.mode == RecorderModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, sendErr := r.transport.RoundTrip(req)
+	if sendErr != nil {
+		return nil, sendErr
+	}
+	respBody, respReadErr := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if respReadErr != nil {
+		return nil, respReadErr
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	headers := map[string]string{}
+	for k := range resp.Header {
+		if !strings.EqualFold(k, "Set-Cookie") {
+			headers[k] = resp.Header.Get(k)
+		}
+	}
+
+	recordedResp := &RecordedResponse{StatusCode: resp.StatusCode, Headers: headers}
+	recordedResp.Body, recordedResp.BodyEncoding = r.recordBody(resp.Header.Get("Content-Type"), respBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{Request: recorded, Response: recordedResp})
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replay returns the recorded response matching the request.
+func (r *Recorder) replay(req *http.Request, recorded *RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if !in.Request.matches(recorded) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded interaction matches %s %s", recorded.Method, recorded.URL)
+	}
+	r.used[match] = true
+
+	in := r.cassette.Interactions[match]
+	body := []byte(in.Response.Body)
+	if in.Response.BodyEncoding == BodyEncodingBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(in.Response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body recorded for %s %s: %v", recorded.Method, recorded.URL, err)
+		}
+		body = decoded
+	}
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	for k, v := range in.Response.Headers {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
+
+func (q *RecordedRequest) matches(other *RecordedRequest) bool {
+	return q.Method == other.Method && q.URL == other.URL && q.Body == other.Body &&
+		q.BodyEncoding == other.BodyEncoding
+}
+
+// save writes the cassette to disk. The caller must hold r.mu or have exclusive access.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(r.path, data, 0644)
+}
+
+// scrubURL returns the request path and scrubbed, sorted query string. The host is dropped so cassettes
+// replay against any instance.
+func (r *Recorder) scrubURL(u *url.URL) string {
+	out := u.Path
+	if u.RawQuery != "" {
+		query := u.Query()
+		r.scrubValues(query)
+		out += "?" + query.Encode()
+	}
+	return r.applyPatterns(out)
+}
+
+// recordBody returns the form of a body stored in the cassette and its encoding. Bodies that aren't valid UTF-8
+// can't be held by a JSON string, so they are base64 encoded.
+func (r *Recorder) recordBody(contentType string, body []byte) (string, string) {
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), BodyEncodingBase64
+	}
+	return r.scrubBody(contentType, body), ""
+}
+
+// scrubBody normalizes and scrubs JSON and form encoded bodies. Other bodies only have patterns applied.
+func (r *Recorder) scrubBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	switch {
+	case strings.Contains(contentType, FormURLEncodedHeader):
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			r.scrubValues(values)
+			return r.applyPatterns(values.Encode())
+		}
+	case strings.Contains(contentType, "json") || json.Valid(body):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			// Marshaling maps sorts their keys, normalizing the body.
+			normalized, marshalErr := json.Marshal(r.scrubJSON(v))
+			if marshalErr == nil {
+				return r.applyPatterns(string(normalized))
+			}
+		}
+	}
+
+	return r.applyPatterns(string(body))
+}
+
+func (r *Recorder) scrubValues(values url.Values) {
+	for k := range values {
+		if r.scrubFields[strings.ToLower(k)] {
+			values.Set(k, ScrubbedValue)
+		}
+	}
+}
+
+func (r *Recorder) scrubJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if r.scrubFields[strings.ToLower(k)] && child != nil {
+				t[k] = ScrubbedValue
+				continue
+			}
+			t[k] = r.scrubJSON(child)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = r.scrubJSON(child)
+		}
+	}
+	return v
+}
+
+func (r *Recorder) applyPatterns(s string) string {
+	for _, p := range r.scrubPatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// readBody reads and restores a request body.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
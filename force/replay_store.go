@@ -0,0 +1,147 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ReplayStore persists the replay ID of the last processed event per streaming channel so a consumer can resume
+// where it left off after a restart.
+type ReplayStore interface {
+	// Load returns the saved replay ID of a channel. The bool is false if nothing has been saved.
+	Load(channel string) (int64, bool, error)
+
+	// Save records the replay ID of the last processed event of a channel.
+	Save(channel string, replayID int64) error
+}
+
+// MemoryReplayStore is a ReplayStore that keeps replay IDs in memory. It's safe for concurrent use.
+type MemoryReplayStore struct {
+	mu      sync.Mutex
+	replays map[string]int64
+}
+
+// NewMemoryReplayStore returns an empty MemoryReplayStore.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{replays: map[string]int64{}}
+}
+
+// Load returns the saved replay ID of a channel.
+func (m *MemoryReplayStore) Load(channel string) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.replays[channel]
+	return id, ok, nil
+}
+
+// Save records the replay ID of a channel.
+func (m *MemoryReplayStore) Save(channel string, replayID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replays[channel] = replayID
+	return nil
+}
+
+// FileReplayStore is a ReplayStore that keeps replay IDs in a JSON file keyed by channel.
+//
+// Every Save rewrites the file by writing a temporary file and renaming it over the original, so a crash never
+// leaves a partially written checkpoint. It's safe for concurrent use within a process.
+type FileReplayStore struct {
+	path string
+
+	mu      sync.Mutex
+	replays map[string]int64
+}
+
+// NewFileReplayStore returns a FileReplayStore backed by the file at path, loading any saved replay IDs.
+// The file is created on the first Save if it doesn't exist.
+func NewFileReplayStore(path string) (*FileReplayStore, error) {
+	f := &FileReplayStore{path: path, replays: map[string]int64{}}
+
+	data, readErr := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(readErr):
+		return f, nil
+	case readErr != nil:
+		return nil, readErr
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f.replays); err != nil {
+			return nil, fmt.Errorf("unable to parse replay store %s: %v", path, err)
+		}
+	}
+	return f, nil
+}
+
+// Load returns the saved replay ID of a channel.
+func (f *FileReplayStore) Load(channel string) (int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, ok := f.replays[channel]
+	return id, ok, nil
+}
+
+// Save records the replay ID of a channel and writes the file.
+func (f *FileReplayStore) Save(channel string, replayID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous, existed := f.replays[channel]
+	f.replays[channel] = replayID
+	if err := f.write(); err != nil {
+		if existed {
+			f.replays[channel] = previous
+		} else {
+			delete(f.replays, channel)
+		}
+		return err
+	}
+	return nil
+}
+
+// write atomically replaces the file with the current replay IDs. The caller must hold f.mu.
+func (f *FileReplayStore) write() error {
+	data, err := json.MarshalIndent(f.replays, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+// writeFileAtomic replaces the file at path by writing a temporary file in the same directory and renaming it
+// over the original, creating the directory if needed.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"time"
+)
+
+// ReplicationRetention is how far back GetUpdated and GetDeleted can look. Salesforce rejects an older start.
+const ReplicationRetention = 30 * 24 * time.Hour
+
+// ReplicationRequest represents the time window of a GetUpdated or GetDeleted request.
+type ReplicationRequest struct {
+	Start string `url:"start"`
+	End   string `url:"end"`
+}
+
+// UpdatedResult represents the records of a SObject created or updated within a time window.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_getupdated.htm
+type UpdatedResult struct {
+	IDs               []string `json:"ids"`
+	LatestDateCovered string   `json:"latestDateCovered"`
+}
+
+// DeletedResult represents the records of a SObject deleted within a time window.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_getdeleted.htm
+type DeletedResult struct {
+	DeletedRecords        []*DeletedRecord `json:"deletedRecords"`
+	EarliestDateAvailable string           `json:"earliestDateAvailable"`
+	LatestDateCovered     string           `json:"latestDateCovered"`
+}
+
+// DeletedRecord represents a deleted record.
+type DeletedRecord struct {
+	ID          string `json:"id"`
+	DeletedDate string `json:"deletedDate"`
+}
+
+// GetUpdated returns the IDs of the records of a SObject created or updated between start and end.
+// Changes made after LatestDateCovered aren't included and should be requested from it next time.
+func (c *Client) GetUpdated(objectName string, start, end time.Time) (*UpdatedResult, *simpleresty.Response, error) {
+	var result *UpdatedResult
+	urlStr, urlStrErr := c.replicationURL(objectName, "updated", start, end)
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// GetDeleted returns the records of a SObject deleted between start and end. Changes made after
+// LatestDateCovered aren't included and should be requested from it next time.
+func (c *Client) GetDeleted(objectName string, start, end time.Time) (*DeletedResult, *simpleresty.Response, error) {
+	var result *DeletedResult
+	urlStr, urlStrErr := c.replicationURL(objectName, "deleted", start, end)
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := c.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+func (c *Client) replicationURL(objectName, resource string, start, end time.Time) (string, error) {
+	if !end.After(start) {
+		return "", fmt.Errorf("end %s must be after start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+
+	return c.http.RequestURLWithQueryParams(
+		fmt.Sprintf("/services/data/%s/sobjects/%s/%s/", c.apiVersion, objectName, resource),
+		&ReplicationRequest{Start: start.UTC().Format(time.RFC3339), End: end.UTC().Format(time.RFC3339)})
+}
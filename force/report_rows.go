@@ -0,0 +1,225 @@
+package force
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ReportCell represents a typed value of a flattened report row.
+type ReportCell struct {
+	// Column is the name of the column, grouping or aggregate, such as `AMOUNT` or `s!AMOUNT`.
+	Column string
+
+	// DataType is the report data type of the column, such as `currency`.
+	DataType string
+
+	// Label is the formatted value as displayed in Salesforce.
+	Label string
+
+	// Value is the value converted by data type: float64 for numbers, percents and currency amounts, bool for
+	// booleans, time.Time for dates and datetimes, nil for empty values and string otherwise.
+	Value interface{}
+}
+
+// ReportRow represents a row of a flattened report.
+type ReportRow struct {
+	// Groupings holds the values of the groupings the row belongs to, down groupings first and outermost first.
+	Groupings []*ReportCell
+
+	// Cells holds the detail columns of the row, or the aggregates of its grouping if the report has no detail rows.
+	Cells []*ReportCell
+}
+
+// Get returns the grouping or cell of a column, or nil if there is none.
+func (r *ReportRow) Get(column string) *ReportCell {
+	for _, cells := range [][]*ReportCell{r.Groupings, r.Cells} {
+		for _, c := range cells {
+			if strings.EqualFold(c.Column, column) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// Values returns the typed values of the row keyed by column.
+func (r *ReportRow) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(r.Groupings)+len(r.Cells))
+	for _, cells := range [][]*ReportCell{r.Groupings, r.Cells} {
+		for _, c := range cells {
+			values[c.Column] = c.Value
+		}
+	}
+	return values
+}
+
+// reportLeaf is the innermost value of a grouping along with the values of its parents.
+type reportLeaf struct {
+	key    string
+	values []*ReportCell
+}
+
+// Rows flattens the factMap into rows with typed values.
+//
+// Tabular and summary reports run with detail rows produce a row per record, carrying the values of the groupings
+// it belongs to. Otherwise, including every matrix report, a row is produced per innermost grouping with its
+// aggregates, such as the record count.
+func (r *ReportResult) Rows() ([]*ReportRow, error) {
+	meta := r.ReportMetadata
+	if meta == nil {
+		meta = &ReportMetadata{}
+	}
+	info := r.ReportExtendedMetadata
+	if info == nil {
+		info = &ReportExtendedMetadata{}
+	}
+
+	down, err := reportLeaves(r.GroupingsDown, meta.GroupingsDown, info)
+	if err != nil {
+		return nil, err
+	}
+	across, err := reportLeaves(r.GroupingsAcross, meta.GroupingsAcross, info)
+	if err != nil {
+		return nil, err
+	}
+	details := r.HasDetailRows && len(meta.GroupingsAcross) == 0
+
+	rows := make([]*ReportRow, 0)
+	for _, d := range down {
+		for _, a := range across {
+			fact, ok := r.FactMap[d.key+"!"+a.key]
+			if !ok {
+				continue
+			}
+			groupings := append(append([]*ReportCell{}, d.values...), a.values...)
+
+			if !details {
+				cells, err := reportCells(fact.Aggregates, meta.Aggregates, info.AggregateColumnInfo)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, &ReportRow{Groupings: groupings, Cells: cells})
+				continue
+			}
+
+			for _, fr := range fact.Rows {
+				cells, err := reportCells(fr.DataCells, meta.DetailColumns, info.DetailColumnInfo)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, &ReportRow{Groupings: groupings, Cells: cells})
+			}
+		}
+	}
+	return rows, nil
+}
+
+// reportLeaves returns the innermost grouping values, or the `T` total key if the report has no groupings.
+func reportLeaves(groupings *ReportGroupings, defs []*ReportGrouping, info *ReportExtendedMetadata) ([]*reportLeaf, error) {
+	if groupings == nil || len(groupings.Groupings) == 0 || len(defs) == 0 {
+		return []*reportLeaf{{key: "T"}}, nil
+	}
+
+	leaves := make([]*reportLeaf, 0)
+	var walk func(values []*ReportGroupingValue, level int, parents []*ReportCell) error
+	walk = func(values []*ReportGroupingValue, level int, parents []*ReportCell) error {
+		for _, v := range values {
+			column := defs[level].Name
+			dataType := ""
+			if ci, ok := info.GroupingColumnInfo[column]; ok {
+				dataType = ci.DataType
+			}
+			value, err := decodeReportValue(v.Value, dataType)
+			if err != nil {
+				return err
+			}
+			path := append(append([]*ReportCell{}, parents...),
+				&ReportCell{Column: column, DataType: dataType, Label: v.Label, Value: value})
+
+			if len(v.Groupings) == 0 || level+1 >= len(defs) {
+				leaves = append(leaves, &reportLeaf{key: v.Key, values: path})
+				continue
+			}
+			if err := walk(v.Groupings, level+1, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(groupings.Groupings, 0, nil); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// reportCells converts fact values to cells of the named columns.
+func reportCells(values []*ReportFactValue, columns []string, info map[string]*ReportColumnInfo) ([]*ReportCell, error) {
+	cells := make([]*ReportCell, 0, len(values))
+	for i, v := range values {
+		cell := &ReportCell{Label: v.Label}
+		if i < len(columns) {
+			cell.Column = columns[i]
+		}
+		if ci, ok := info[cell.Column]; ok {
+			cell.DataType = ci.DataType
+		}
+
+		value, err := decodeReportValue(v.Value, cell.DataType)
+		if err != nil {
+			return nil, err
+		}
+		cell.Value = value
+		cells = append(cells, cell)
+	}
+	return cells, nil
+}
+
+// decodeReportValue converts a raw report value to a Go value according to the column's data type.
+func decodeReportValue(raw json.RawMessage, dataType string) (interface{}, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	switch strings.ToLower(dataType) {
+	case "currency":
+		if raw[0] == '{' {
+			var amount struct {
+				Amount *float64 `json:"amount"`
+			}
+			if err := json.Unmarshal(raw, &amount); err != nil {
+				return nil, err
+			}
+			if amount.Amount == nil {
+				return nil, nil
+			}
+			return *amount.Amount, nil
+		}
+		var f float64
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	case "int", "double", "percent":
+		var f float64
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	case "boolean":
+		var b bool
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	case "date", "datetime":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		if t, err := parseReportTime(s); err == nil {
+			return t, nil
+		}
+		return s, nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
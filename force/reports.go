@@ -0,0 +1,375 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"time"
+)
+
+// ReportsService handles communication with the report resources of the Analytics REST API.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_analytics.meta/api_analytics/sforce_analytics_rest_api_intro.htm
+type ReportsService service
+
+// DashboardsService handles communication with the dashboard resources of the Analytics REST API.
+type DashboardsService service
+
+// ReportFormats represents all formats of a report.
+var ReportFormats = struct {
+	Tabular string
+	Summary string
+	Matrix  string
+}{
+	Tabular: "TABULAR",
+	Summary: "SUMMARY",
+	Matrix:  "MATRIX",
+}
+
+// ReportFilterOperators represents the operators of a report filter.
+var ReportFilterOperators = struct {
+	Equals         string
+	NotEqual       string
+	LessThan       string
+	GreaterThan    string
+	LessOrEqual    string
+	GreaterOrEqual string
+	Contains       string
+	NotContain     string
+	StartsWith     string
+	Includes       string
+	Excludes       string
+}{
+	Equals:         "equals",
+	NotEqual:       "notEqual",
+	LessThan:       "lessThan",
+	GreaterThan:    "greaterThan",
+	LessOrEqual:    "lessOrEqual",
+	GreaterOrEqual: "greaterOrEqual",
+	Contains:       "contains",
+	NotContain:     "notContain",
+	StartsWith:     "startsWith",
+	Includes:       "includes",
+	Excludes:       "excludes",
+}
+
+// ReportInstanceStatuses represents all statuses of an asynchronous report run.
+var ReportInstanceStatuses = struct {
+	New     string
+	Running string
+	Success string
+	Error   string
+}{
+	New:     "New",
+	Running: "Running",
+	Success: "Success",
+	Error:   "Error",
+}
+
+// Report represents a report returned when listing reports.
+type Report struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	URL          string `json:"url,omitempty"`
+	DescribeURL  string `json:"describeUrl,omitempty"`
+	InstancesURL string `json:"instancesUrl,omitempty"`
+}
+
+// ReportMetadata represents the definition of a report. When running a report, it can be sent to override the
+// filters, groupings and columns of the saved report.
+type ReportMetadata struct {
+	ID                  string                    `json:"id,omitempty"`
+	Name                string                    `json:"name,omitempty"`
+	DeveloperName       string                    `json:"developerName,omitempty"`
+	ReportFormat        string                    `json:"reportFormat,omitempty"`
+	ReportType          *ReportType               `json:"reportType,omitempty"`
+	Currency            *string                   `json:"currency,omitempty"`
+	DetailColumns       []string                  `json:"detailColumns,omitempty"`
+	Aggregates          []string                  `json:"aggregates,omitempty"`
+	GroupingsDown       []*ReportGrouping         `json:"groupingsDown,omitempty"`
+	GroupingsAcross     []*ReportGrouping         `json:"groupingsAcross,omitempty"`
+	ReportFilters       []*ReportFilter           `json:"reportFilters,omitempty"`
+	ReportBooleanFilter *string                   `json:"reportBooleanFilter,omitempty"`
+	StandardDateFilter  *ReportStandardDateFilter `json:"standardDateFilter,omitempty"`
+	HasDetailRows       bool                      `json:"hasDetailRows,omitempty"`
+}
+
+// ReportType represents the report type a report is built on.
+type ReportType struct {
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+}
+
+// ReportGrouping represents a grouping of a summary or matrix report.
+type ReportGrouping struct {
+	Name            string `json:"name"`
+	SortOrder       string `json:"sortOrder,omitempty"`
+	DateGranularity string `json:"dateGranularity,omitempty"`
+}
+
+// ReportFilter represents a filter of a report. Operator is one of the ReportFilterOperators.
+type ReportFilter struct {
+	Column   string `json:"column"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// ReportStandardDateFilter represents the standard date filter of a report.
+type ReportStandardDateFilter struct {
+	Column        string  `json:"column"`
+	DurationValue string  `json:"durationValue,omitempty"`
+	StartDate     *string `json:"startDate,omitempty"`
+	EndDate       *string `json:"endDate,omitempty"`
+}
+
+// ReportExtendedMetadata represents the labels and data types of a report's columns, keyed by column name.
+type ReportExtendedMetadata struct {
+	DetailColumnInfo    map[string]*ReportColumnInfo `json:"detailColumnInfo,omitempty"`
+	AggregateColumnInfo map[string]*ReportColumnInfo `json:"aggregateColumnInfo,omitempty"`
+	GroupingColumnInfo  map[string]*ReportColumnInfo `json:"groupingColumnInfo,omitempty"`
+}
+
+// ReportColumnInfo represents the label and data type of a report column, such as `currency` or `date`.
+type ReportColumnInfo struct {
+	Label         string `json:"label"`
+	DataType      string `json:"dataType"`
+	GroupingLevel *int   `json:"groupingLevel,omitempty"`
+}
+
+// ReportDescribe represents the metadata of a report.
+type ReportDescribe struct {
+	ReportMetadata         *ReportMetadata         `json:"reportMetadata"`
+	ReportExtendedMetadata *ReportExtendedMetadata `json:"reportExtendedMetadata"`
+	ReportTypeMetadata     map[string]interface{}  `json:"reportTypeMetadata,omitempty"`
+}
+
+// ReportRunRequest represents the options of a report run.
+type ReportRunRequest struct {
+	// IncludeDetails includes the detail rows of the report and not only its summaries.
+	IncludeDetails bool `url:"includeDetails" json:"-"`
+
+	// ReportMetadata, if set, overrides the saved report's metadata, such as its ReportFilters.
+	ReportMetadata *ReportMetadata `url:"-" json:"reportMetadata,omitempty"`
+}
+
+// ReportInstance represents an asynchronous run of a report.
+type ReportInstance struct {
+	ID             string  `json:"id"`
+	Status         string  `json:"status"`
+	RequestDate    string  `json:"requestDate,omitempty"`
+	CompletionDate *string `json:"completionDate,omitempty"`
+	URL            string  `json:"url,omitempty"`
+	OwnerID        string  `json:"ownerId,omitempty"`
+	HasDetailRows  bool    `json:"hasDetailRows"`
+}
+
+// ReportResult represents the results of running a report.
+type ReportResult struct {
+	// Attributes holds the instance of an asynchronous run, such as its status.
+	Attributes             *ReportInstance         `json:"attributes,omitempty"`
+	AllData                bool                    `json:"allData"`
+	HasDetailRows          bool                    `json:"hasDetailRows"`
+	FactMap                map[string]*ReportFact  `json:"factMap"`
+	GroupingsDown          *ReportGroupings        `json:"groupingsDown,omitempty"`
+	GroupingsAcross        *ReportGroupings        `json:"groupingsAcross,omitempty"`
+	ReportMetadata         *ReportMetadata         `json:"reportMetadata"`
+	ReportExtendedMetadata *ReportExtendedMetadata `json:"reportExtendedMetadata"`
+}
+
+// ReportFact represents the detail rows and aggregates of a grouping, keyed in the factMap by grouping keys such
+// as `T!T` for the grand total or `0_1!T` for the second subgrouping of the first grouping.
+type ReportFact struct {
+	Rows       []*ReportFactRow   `json:"rows,omitempty"`
+	Aggregates []*ReportFactValue `json:"aggregates,omitempty"`
+}
+
+// ReportFactRow represents a detail row.
+type ReportFactRow struct {
+	DataCells []*ReportFactValue `json:"dataCells"`
+}
+
+// ReportFactValue represents a cell or aggregate. The value is left undecoded as its JSON type depends on the
+// column's data type; a currency is an object holding an amount and currency code.
+type ReportFactValue struct {
+	Label string          `json:"label"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ReportGroupings represents the groupings of a summary or matrix report.
+type ReportGroupings struct {
+	Groupings []*ReportGroupingValue `json:"groupings"`
+}
+
+// ReportGroupingValue represents a single value of a grouping along with its subgroupings.
+type ReportGroupingValue struct {
+	Key       string                 `json:"key"`
+	Label     string                 `json:"label"`
+	Value     json.RawMessage        `json:"value"`
+	Groupings []*ReportGroupingValue `json:"groupings,omitempty"`
+}
+
+// Dashboard represents a dashboard returned when listing dashboards.
+type Dashboard struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	StatusURL string `json:"statusUrl,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+func (s *ReportsService) reportsURL(resource string) string {
+	return s.client.http.RequestURL(fmt.Sprintf("/services/data/%s/analytics/reports%s", s.client.apiVersion, resource))
+}
+
+// List returns the reports recently viewed by the user.
+func (s *ReportsService) List() ([]*Report, *simpleresty.Response, error) {
+	var result []*Report
+
+	response, getErr := s.client.http.Get(s.reportsURL(""), &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// Describe returns the metadata of a report, including the labels and data types of its columns.
+func (s *ReportsService) Describe(reportID string) (*ReportDescribe, *simpleresty.Response, error) {
+	var result *ReportDescribe
+
+	response, getErr := s.client.http.Get(s.reportsURL(fmt.Sprintf("/%s/describe", reportID)), &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// Run runs a report synchronously and returns its results.
+func (s *ReportsService) Run(reportID string, opts *ReportRunRequest) (*ReportResult, *simpleresty.Response, error) {
+	if opts == nil {
+		opts = &ReportRunRequest{}
+	}
+
+	var result *ReportResult
+	urlStr, urlStrErr := simpleresty.AddQueryParams(s.reportsURL("/"+reportID), opts)
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	var response *simpleresty.Response
+	var err error
+	if opts.ReportMetadata != nil {
+		response, err = s.client.http.Post(urlStr, &result, opts)
+	} else {
+		response, err = s.client.http.Get(urlStr, &result, nil)
+	}
+	if err != nil {
+		return nil, response, err
+	}
+
+	return result, response, nil
+}
+
+// RunAsync starts running a report asynchronously and returns the new instance.
+func (s *ReportsService) RunAsync(reportID string, opts *ReportRunRequest) (*ReportInstance, *simpleresty.Response, error) {
+	if opts == nil {
+		opts = &ReportRunRequest{}
+	}
+
+	var result *ReportInstance
+	urlStr, urlStrErr := simpleresty.AddQueryParams(s.reportsURL(fmt.Sprintf("/%s/instances", reportID)), opts)
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, postErr := s.client.http.Post(urlStr, &result, opts)
+	if postErr != nil {
+		return nil, nil, postErr
+	}
+
+	return result, response, nil
+}
+
+// Instances lists the asynchronous runs of a report.
+func (s *ReportsService) Instances(reportID string) ([]*ReportInstance, *simpleresty.Response, error) {
+	var result []*ReportInstance
+
+	response, getErr := s.client.http.Get(s.reportsURL(fmt.Sprintf("/%s/instances", reportID)), &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// Instance returns an asynchronous run of a report. Its results are only populated once the Attributes report
+// a Success status.
+func (s *ReportsService) Instance(reportID, instanceID string) (*ReportResult, *simpleresty.Response, error) {
+	var result *ReportResult
+	urlStr := s.reportsURL(fmt.Sprintf("/%s/instances/%s", reportID, instanceID))
+
+	response, getErr := s.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// RunAndWait runs a report asynchronously and polls its instance until it finishes.
+func (s *ReportsService) RunAndWait(reportID string, opts *ReportRunRequest, pollOpts ...PollOption) (*ReportResult, error) {
+	poller, err := NewPoller(pollOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, _, err := s.RunAsync(reportID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *ReportResult
+	pollErr := poller.Poll(func() (bool, error) {
+		var getErr error
+		if result, _, getErr = s.Instance(reportID, instance.ID); getErr != nil {
+			return false, getErr
+		}
+		if result.Attributes == nil {
+			return false, nil
+		}
+		switch result.Attributes.Status {
+		case ReportInstanceStatuses.Success:
+			return true, nil
+		case ReportInstanceStatuses.Error:
+			return false, fmt.Errorf("report instance %s of %s failed", instance.ID, reportID)
+		}
+		return false, nil
+	})
+	if pollErr != nil {
+		return nil, pollErr
+	}
+	return result, nil
+}
+
+// List returns the dashboards recently viewed by the user.
+func (s *DashboardsService) List() ([]*Dashboard, *simpleresty.Response, error) {
+	var result []*Dashboard
+	urlStr := s.client.http.RequestURL(fmt.Sprintf("/services/data/%s/analytics/dashboards", s.client.apiVersion))
+
+	response, getErr := s.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// parseReportTime parses a report date or datetime value.
+func parseReportTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-0700", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid report date %q", s)
+}
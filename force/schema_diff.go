@@ -0,0 +1,459 @@
+package force
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaChanges lists the kinds of difference found when comparing schemas. Added and removed are relative to
+// the source, so an object only in the target is added.
+var SchemaChanges = struct {
+	Added   string
+	Removed string
+	Changed string
+}{
+	Added:   "added",
+	Removed: "removed",
+	Changed: "changed",
+}
+
+// SchemaDiff represents the differences between the SObjects of a source and a target schema, such as a sandbox
+// and production or two API versions. SObjects that are the same in both are omitted.
+type SchemaDiff struct {
+	Source  string        `json:"source,omitempty"`
+	Target  string        `json:"target,omitempty"`
+	Objects []*ObjectDiff `json:"objects"`
+}
+
+// ObjectDiff represents the differences of a SObject. Fields and relationships are only compared for SObjects
+// that exist in both schemas.
+type ObjectDiff struct {
+	Name          string              `json:"name"`
+	Change        string              `json:"change"`
+	Properties    []*PropertyChange   `json:"properties,omitempty"`
+	Fields        []*FieldDiff        `json:"fields,omitempty"`
+	Relationships []*RelationshipDiff `json:"relationships,omitempty"`
+}
+
+// FieldDiff represents the differences of a field.
+type FieldDiff struct {
+	Name       string            `json:"name"`
+	Change     string            `json:"change"`
+	Properties []*PropertyChange `json:"properties,omitempty"`
+
+	// PicklistValuesAdded and PicklistValuesRemoved hold the active picklist values only in the target or only in
+	// the source.
+	PicklistValuesAdded   []string `json:"picklistValuesAdded,omitempty"`
+	PicklistValuesRemoved []string `json:"picklistValuesRemoved,omitempty"`
+}
+
+// RelationshipDiff represents the differences of a child relationship, named by its relationship name.
+type RelationshipDiff struct {
+	Name       string            `json:"name"`
+	Change     string            `json:"change"`
+	Properties []*PropertyChange `json:"properties,omitempty"`
+}
+
+// PropertyChange represents a property whose value differs between the source and the target.
+type PropertyChange struct {
+	Name   string      `json:"name"`
+	Source interface{} `json:"source"`
+	Target interface{} `json:"target"`
+}
+
+// CompareSchemas describes the SObjects with the source and target clients and returns their differences.
+// If no SObject names are given, every SObject listed by DescribeGlobal in either org is compared, which
+// describes each SObject found in both.
+func CompareSchemas(source, target *Client, objectNames ...string) (*SchemaDiff, error) {
+	diff := &SchemaDiff{
+		Source:  source.InstanceURL() + " " + source.APIVersion(),
+		Target:  target.InstanceURL() + " " + target.APIVersion(),
+		Objects: []*ObjectDiff{},
+	}
+
+	if len(objectNames) == 0 {
+		sourceNames, err := describeGlobalNames(source)
+		if err != nil {
+			return nil, err
+		}
+		targetNames, err := describeGlobalNames(target)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, name := range sourceNames {
+			if _, ok := targetNames[key]; ok {
+				objectNames = append(objectNames, name)
+			} else {
+				diff.Objects = append(diff.Objects, &ObjectDiff{Name: name, Change: SchemaChanges.Removed})
+			}
+		}
+		for key, name := range targetNames {
+			if _, ok := sourceNames[key]; !ok {
+				diff.Objects = append(diff.Objects, &ObjectDiff{Name: name, Change: SchemaChanges.Added})
+			}
+		}
+	}
+
+	for _, name := range objectNames {
+		sourceMeta, err := describeIfExists(source, name)
+		if err != nil {
+			return nil, err
+		}
+		targetMeta, err := describeIfExists(target, name)
+		if err != nil {
+			return nil, err
+		}
+		if d := DiffSObjects(sourceMeta, targetMeta); d != nil {
+			diff.Objects = append(diff.Objects, d)
+		}
+	}
+
+	sort.Slice(diff.Objects, func(i, j int) bool { return diff.Objects[i].Name < diff.Objects[j].Name })
+	return diff, nil
+}
+
+// DiffSchemas returns the differences between two sets of SObjects, matched by name.
+func DiffSchemas(source, target []*SObjectMetadata) *SchemaDiff {
+	sourceByName := map[string]*SObjectMetadata{}
+	targetByName := map[string]*SObjectMetadata{}
+	names := map[string]bool{}
+	for _, meta := range source {
+		sourceByName[strings.ToLower(meta.GetName())] = meta
+		names[meta.GetName()] = true
+	}
+	for _, meta := range target {
+		targetByName[strings.ToLower(meta.GetName())] = meta
+		names[meta.GetName()] = true
+	}
+
+	diff := &SchemaDiff{Objects: []*ObjectDiff{}}
+	seen := map[string]bool{}
+	for _, name := range sortedKeys(names) {
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if d := DiffSObjects(sourceByName[key], targetByName[key]); d != nil {
+			diff.Objects = append(diff.Objects, d)
+		}
+	}
+	return diff
+}
+
+// DiffSObjects returns the differences between the source and target metadata of a SObject, either of which
+// is nil if the SObject doesn't exist. It returns nil if there are none.
+func DiffSObjects(source, target *SObjectMetadata) *ObjectDiff {
+	switch {
+	case source == nil && target == nil:
+		return nil
+	case source == nil:
+		return &ObjectDiff{Name: target.GetName(), Change: SchemaChanges.Added}
+	case target == nil:
+		return &ObjectDiff{Name: source.GetName(), Change: SchemaChanges.Removed}
+	}
+
+	d := &ObjectDiff{Name: source.GetName(), Change: SchemaChanges.Changed}
+	d.Properties = diffProperties(objectProperties(source), objectProperties(target))
+
+	sourceFields := map[string]*SObjectFieldMetadata{}
+	targetFields := map[string]*SObjectFieldMetadata{}
+	names := map[string]bool{}
+	for _, f := range source.Fields {
+		sourceFields[strings.ToLower(f.GetName())] = f
+		names[f.GetName()] = true
+	}
+	for _, f := range target.Fields {
+		targetFields[strings.ToLower(f.GetName())] = f
+		if _, ok := sourceFields[strings.ToLower(f.GetName())]; !ok {
+			names[f.GetName()] = true
+		}
+	}
+	for _, name := range sortedKeys(names) {
+		key := strings.ToLower(name)
+		if fd := diffField(name, sourceFields[key], targetFields[key]); fd != nil {
+			d.Fields = append(d.Fields, fd)
+		}
+	}
+
+	sourceRels := childRelationships(source)
+	targetRels := childRelationships(target)
+	for _, name := range unionKeys(relationshipNames(sourceRels), relationshipNames(targetRels)) {
+		key := strings.ToLower(name)
+		sourceRel, inSource := sourceRels[key]
+		targetRel, inTarget := targetRels[key]
+		switch {
+		case !inSource:
+			d.Relationships = append(d.Relationships, &RelationshipDiff{Name: name, Change: SchemaChanges.Added})
+		case !inTarget:
+			d.Relationships = append(d.Relationships, &RelationshipDiff{Name: name, Change: SchemaChanges.Removed})
+		default:
+			props := diffProperties(relationshipProperties(sourceRel), relationshipProperties(targetRel))
+			if len(props) > 0 {
+				d.Relationships = append(d.Relationships, &RelationshipDiff{Name: name,
+					Change: SchemaChanges.Changed, Properties: props})
+			}
+		}
+	}
+
+	if len(d.Properties) == 0 && len(d.Fields) == 0 && len(d.Relationships) == 0 {
+		return nil
+	}
+	return d
+}
+
+// IsEmpty reports whether the schemas are the same.
+func (d *SchemaDiff) IsEmpty() bool {
+	return len(d.Objects) == 0
+}
+
+// WriteReport writes a human readable report of the differences, one line per change:
+//
+//	~ Account
+//	    + field Tier__c
+//	    ~ field Industry: length 40 -> 255, picklist values +Mining -Other
+//	    - relationship Invoices__r
+func (d *SchemaDiff) WriteReport(w io.Writer) error {
+	if d.Source != "" || d.Target != "" {
+		if _, err := fmt.Fprintf(w, "Comparing %s with %s\n\n", d.Source, d.Target); err != nil {
+			return err
+		}
+	}
+	if d.IsEmpty() {
+		_, err := fmt.Fprintln(w, "No differences found.")
+		return err
+	}
+
+	for _, o := range d.Objects {
+		lines := []string{fmt.Sprintf("%s %s%s", changeSymbol(o.Change), o.Name, formatProperties(o.Properties))}
+		for _, f := range o.Fields {
+			line := fmt.Sprintf("    %s field %s%s", changeSymbol(f.Change), f.Name, formatProperties(f.Properties))
+			if len(f.PicklistValuesAdded) > 0 || len(f.PicklistValuesRemoved) > 0 {
+				values := make([]string, 0, len(f.PicklistValuesAdded)+len(f.PicklistValuesRemoved))
+				for _, v := range f.PicklistValuesAdded {
+					values = append(values, "+"+v)
+				}
+				for _, v := range f.PicklistValuesRemoved {
+					values = append(values, "-"+v)
+				}
+				separator := ", "
+				if len(f.Properties) == 0 {
+					separator = ": "
+				}
+				line += separator + "picklist values " + strings.Join(values, " ")
+			}
+			lines = append(lines, line)
+		}
+		for _, r := range o.Relationships {
+			lines = append(lines, fmt.Sprintf("    %s relationship %s%s", changeSymbol(r.Change), r.Name,
+				formatProperties(r.Properties)))
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffField returns the differences between the source and target metadata of a field, or nil if there are none.
+func diffField(name string, source, target *SObjectFieldMetadata) *FieldDiff {
+	switch {
+	case source == nil:
+		return &FieldDiff{Name: name, Change: SchemaChanges.Added}
+	case target == nil:
+		return &FieldDiff{Name: name, Change: SchemaChanges.Removed}
+	}
+
+	fd := &FieldDiff{Name: name, Change: SchemaChanges.Changed,
+		Properties: diffProperties(fieldProperties(source), fieldProperties(target))}
+
+	sourceValues := activePicklistValues(source)
+	targetValues := activePicklistValues(target)
+	for _, v := range sortedKeys(targetValues) {
+		if !sourceValues[v] {
+			fd.PicklistValuesAdded = append(fd.PicklistValuesAdded, v)
+		}
+	}
+	for _, v := range sortedKeys(sourceValues) {
+		if !targetValues[v] {
+			fd.PicklistValuesRemoved = append(fd.PicklistValuesRemoved, v)
+		}
+	}
+
+	if len(fd.Properties) == 0 && len(fd.PicklistValuesAdded) == 0 && len(fd.PicklistValuesRemoved) == 0 {
+		return nil
+	}
+	return fd
+}
+
+// objectProperties returns the compared properties of a SObject, in report order. Key prefixes are not compared,
+// as custom objects get a different key prefix in every org.
+func objectProperties(meta *SObjectMetadata) []*PropertyChange {
+	return []*PropertyChange{
+		{Name: "createable", Source: meta.GetCreateable()},
+		{Name: "updateable", Source: meta.GetUpdateable()},
+		{Name: "deletable", Source: meta.GetDeletable()},
+		{Name: "queryable", Source: meta.GetQueryable()},
+	}
+}
+
+// fieldProperties returns the compared properties of a field, in report order.
+func fieldProperties(f *SObjectFieldMetadata) []*PropertyChange {
+	referenceTo := append([]string{}, f.ReferenceTo...)
+	sort.Strings(referenceTo)
+
+	return []*PropertyChange{
+		{Name: "type", Source: f.Type.ToString()},
+		{Name: "length", Source: f.GetLength()},
+		{Name: "precision", Source: f.GetPrecision()},
+		{Name: "scale", Source: f.GetScale()},
+		{Name: "digits", Source: f.GetDigits()},
+		{Name: "required", Source: !f.GetNillable() && !f.GetDefaultedOnCreate() && f.GetCreateable()},
+		{Name: "unique", Source: f.GetUnique()},
+		{Name: "externalId", Source: f.GetExternalID()},
+		{Name: "formula", Source: f.GetCalculatedFormula()},
+		{Name: "referenceTo", Source: strings.Join(referenceTo, ",")},
+		{Name: "relationshipName", Source: f.GetRelationshipName()},
+		{Name: "restrictedPicklist", Source: f.GetRestrictedPicklist()},
+		{Name: "controllerName", Source: f.GetControllerName()},
+	}
+}
+
+// relationshipProperties returns the compared properties of a child relationship, in report order.
+func relationshipProperties(r *SObjectChildRelationshipMetadata) []*PropertyChange {
+	return []*PropertyChange{
+		{Name: "childSObject", Source: r.GetChildSObject()},
+		{Name: "field", Source: r.GetField()},
+		{Name: "cascadeDelete", Source: r.GetCascadeDelete()},
+		{Name: "restrictedDelete", Source: r.GetRestrictedDelete()},
+	}
+}
+
+// diffProperties pairs the properties of the source and target, which hold their values in Source, and returns
+// those that differ.
+func diffProperties(source, target []*PropertyChange) []*PropertyChange {
+	changes := make([]*PropertyChange, 0)
+	for i, p := range source {
+		if !reflect.DeepEqual(p.Source, target[i].Source) {
+			changes = append(changes, &PropertyChange{Name: p.Name, Source: p.Source, Target: target[i].Source})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// childRelationships returns the named child relationships of a SObject keyed by lower-cased relationship name.
+func childRelationships(meta *SObjectMetadata) map[string]*SObjectChildRelationshipMetadata {
+	rels := map[string]*SObjectChildRelationshipMetadata{}
+	for _, r := range meta.ChildRelationships {
+		if r.GetRelationshipName() != "" {
+			rels[strings.ToLower(r.GetRelationshipName())] = r
+		}
+	}
+	return rels
+}
+
+func relationshipNames(rels map[string]*SObjectChildRelationshipMetadata) map[string]bool {
+	names := map[string]bool{}
+	for _, r := range rels {
+		names[r.GetRelationshipName()] = true
+	}
+	return names
+}
+
+func activePicklistValues(f *SObjectFieldMetadata) map[string]bool {
+	values := map[string]bool{}
+	for _, entry := range f.PicklistValues {
+		if entry.Active == nil || entry.GetActive() {
+			values[entry.GetValue()] = true
+		}
+	}
+	return values
+}
+
+// describeGlobalNames returns the SObject names of an org keyed by lower-cased name.
+func describeGlobalNames(c *Client) (map[string]string, error) {
+	result, _, err := c.DescribeGlobal()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, meta := range result.SObjects {
+		names[strings.ToLower(meta.GetName())] = meta.GetName()
+	}
+	return names, nil
+}
+
+// describeIfExists describes a SObject, returning nil if the org has no such SObject.
+func describeIfExists(c *Client, objectName string) (*SObjectMetadata, error) {
+	meta, _, err := c.Describe(objectName)
+	if err != nil && strings.Contains(err.Error(), "NOT_FOUND") {
+		return nil, nil
+	}
+	return meta, err
+}
+
+// unionKeys returns the keys of both maps, sorted and without duplicates ignoring case.
+func unionKeys(a, b map[string]bool) []string {
+	union := map[string]bool{}
+	seen := map[string]bool{}
+	for _, m := range []map[string]bool{a, b} {
+		for k := range m {
+			if !seen[strings.ToLower(k)] {
+				seen[strings.ToLower(k)] = true
+				union[k] = true
+			}
+		}
+	}
+	return sortedKeys(union)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func changeSymbol(change string) string {
+	switch change {
+	case SchemaChanges.Added:
+		return "+"
+	case SchemaChanges.Removed:
+		return "-"
+	}
+	return "~"
+}
+
+func formatProperties(props []*PropertyChange) string {
+	if len(props) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(props))
+	for _, p := range props {
+		parts = append(parts, fmt.Sprintf("%s %s -> %s", p.Name, formatPropertyValue(p.Source),
+			formatPropertyValue(p.Target)))
+	}
+	return ": " + strings.Join(parts, ", ")
+}
+
+func formatPropertyValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if s == "" {
+			return `""`
+		}
+		if strings.ContainsAny(s, " \n") {
+			return fmt.Sprintf("%q", s)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,211 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotFormatVersion is the version of the snapshot directory layout written by Snapshot.Save.
+	SnapshotFormatVersion = 1
+
+	// snapshotManifestFile is the name of the manifest file of a snapshot directory.
+	snapshotManifestFile = "manifest.json"
+
+	// snapshotSObjectsDir is the directory of a snapshot holding one describe file per SObject.
+	snapshotSObjectsDir = "sobjects"
+)
+
+// SnapshotManifest describes the contents of a snapshot directory.
+type SnapshotManifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	APIVersion    string    `json:"apiVersion"`
+	InstanceURL   string    `json:"instanceUrl,omitempty"`
+	CreatedDate   time.Time `json:"createdDate"`
+	SObjects      []string  `json:"sobjects"`
+}
+
+// Snapshot holds the describe metadata of a set of SObjects so that validation, query building and code generation
+// can run without an org. Snapshots are saved to and loaded from a directory laid out as:
+//
+//	manifest.json         the SnapshotManifest
+//	sobjects/Account.json the describe of each SObject
+type Snapshot struct {
+	Manifest *SnapshotManifest
+	sobjects map[string]*SObjectMetadata
+}
+
+// NewSnapshot returns a snapshot of the SObjects' metadata for an API version.
+func NewSnapshot(apiVersion string, sobjects ...*SObjectMetadata) *Snapshot {
+	s := &Snapshot{
+		Manifest: &SnapshotManifest{FormatVersion: SnapshotFormatVersion, APIVersion: apiVersion,
+			CreatedDate: time.Now().UTC(), SObjects: []string{}},
+		sobjects: map[string]*SObjectMetadata{},
+	}
+	for _, meta := range sobjects {
+		s.Add(meta)
+	}
+	return s
+}
+
+// Snapshot describes the SObjects and returns a snapshot of their metadata. If no SObject names are given, every
+// SObject listed by DescribeGlobal is described.
+func (c *Client) Snapshot(objectNames ...string) (*Snapshot, error) {
+	if len(objectNames) == 0 {
+		result, _, err := c.DescribeGlobal()
+		if err != nil {
+			return nil, err
+		}
+		for _, meta := range result.SObjects {
+			objectNames = append(objectNames, meta.GetName())
+		}
+	}
+
+	s := NewSnapshot(c.apiVersion)
+	s.Manifest.InstanceURL = c.instanceURL
+	for _, name := range objectNames {
+		meta, _, err := c.Describe(name)
+		if err != nil {
+			return nil, err
+		}
+		s.Add(meta)
+	}
+	return s, nil
+}
+
+// LoadSnapshot loads a snapshot saved to a directory.
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, snapshotManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest *SnapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest in %s: %v", dir, err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > SnapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %d in %s", manifest.FormatVersion, dir)
+	}
+
+	s := &Snapshot{Manifest: manifest, sobjects: map[string]*SObjectMetadata{}}
+	for _, name := range manifest.SObjects {
+		path := filepath.Join(dir, snapshotSObjectsDir, name+".json")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var meta *SObjectMetadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("invalid describe in %s: %v", path, err)
+		}
+		s.sobjects[strings.ToLower(name)] = meta
+	}
+	return s, nil
+}
+
+// Save writes the snapshot to a directory, replacing any snapshot already in it.
+func (s *Snapshot) Save(dir string) error {
+	// The old manifest is removed before its files, and the new one written last, so an interrupted save leaves no
+	// manifest pointing at missing or partially written files.
+	manifestPath := filepath.Join(dir, snapshotManifestFile)
+	if err := os.Remove(manifestPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	sobjectsDir := filepath.Join(dir, snapshotSObjectsDir)
+	if err := os.RemoveAll(sobjectsDir); err != nil {
+		return err
+	}
+
+	for _, name := range s.Manifest.SObjects {
+		data, err := json.MarshalIndent(s.sobjects[strings.ToLower(name)], "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(sobjectsDir, name+".json"), data); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(s.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(manifestPath, data)
+}
+
+// Add adds or replaces the metadata of a SObject.
+func (s *Snapshot) Add(meta *SObjectMetadata) {
+	key := strings.ToLower(meta.GetName())
+	if _, ok := s.sobjects[key]; !ok {
+		s.Manifest.SObjects = append(s.Manifest.SObjects, meta.GetName())
+		sort.Strings(s.Manifest.SObjects)
+	}
+	s.sobjects[key] = meta
+}
+
+// ObjectNames returns the names of the SObjects in the snapshot, sorted.
+func (s *Snapshot) ObjectNames() []string {
+	return append([]string{}, s.Manifest.SObjects...)
+}
+
+// SObjects returns the metadata of every SObject in the snapshot, sorted by name.
+func (s *Snapshot) SObjects() []*SObjectMetadata {
+	sobjects := make([]*SObjectMetadata, 0, len(s.Manifest.SObjects))
+	for _, name := range s.Manifest.SObjects {
+		sobjects = append(sobjects, s.sobjects[strings.ToLower(name)])
+	}
+	return sobjects
+}
+
+// Describe returns the metadata of a SObject in the snapshot.
+func (s *Snapshot) Describe(objectName string) (*SObjectMetadata, error) {
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		return nil, fmt.Errorf("sobject %s is not in the snapshot", objectName)
+	}
+	return meta, nil
+}
+
+// Validator returns a Validator of a SObject in the snapshot. References are checked against the key prefixes of
+// the other SObjects in the snapshot.
+func (s *Snapshot) Validator(objectName string) (*Validator, error) {
+	meta, err := s.Describe(objectName)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidator(meta, s.SObjects()...), nil
+}
+
+// BaseSObjectQuery returns a query selecting every field of a SObject in the snapshot. See
+// Client.GetBaseSObjectQuery.
+func (s *Snapshot) BaseSObjectQuery(objectName string) (string, error) {
+	meta, err := s.Describe(objectName)
+	if err != nil {
+		return "", err
+	}
+	return baseSObjectQuery(meta, objectName), nil
+}
+
+// GenerateStructs generates Go structs for SObjects in the snapshot. See GenerateStructs.
+func (s *Snapshot) GenerateStructs(packageName string, objectNames ...string) ([]byte, error) {
+	sobjects := make([]*SObjectMetadata, 0, len(objectNames))
+	for _, name := range objectNames {
+		meta, err := s.Describe(name)
+		if err != nil {
+			return nil, err
+		}
+		sobjects = append(sobjects, meta)
+	}
+	return GenerateStructs(packageName, sobjects...)
+}
+
+// KeyPrefixes returns a registry of the key prefixes of the SObjects in the snapshot.
+func (s *Snapshot) KeyPrefixes() *KeyPrefixRegistry {
+	return NewKeyPrefixRegistry(s.SObjects()...)
+}
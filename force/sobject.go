@@ -546,3 +546,14 @@ func (s *SObjectMetadata) GetFieldNames() []string {
 func (s *SObjectMetadata) GetFieldNamesString() string {
 	return strings.Join(s.GetFieldNames(), ",")
 }
+
+// FindField returns the field with the API name, matched case-insensitively, or nil if the SObject has no such
+// field.
+func (s *SObjectMetadata) FindField(name string) *SObjectFieldMetadata {
+	for _, field := range s.Fields {
+		if strings.EqualFold(field.GetName(), name) {
+			return field
+		}
+	}
+	return nil
+}
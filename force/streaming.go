@@ -0,0 +1,667 @@
+package force
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ReplayNew subscribes to events published after the subscription is made.
+	ReplayNew int64 = -1
+
+	// ReplayAll subscribes to every event retained by Salesforce for the channel.
+	ReplayAll int64 = -2
+
+	// DefaultStreamingBufferSize is the number of events buffered per Subscription.
+	DefaultStreamingBufferSize = 100
+
+	// DefaultStreamingMaxRetries is the number of consecutive failed requests before a StreamingClient gives up.
+	DefaultStreamingMaxRetries = 5
+
+	// DefaultStreamingRetryInterval is the delay before retrying a failed request.
+	DefaultStreamingRetryInterval = time.Second
+
+	bayeuxVersion      = "1.0"
+	bayeuxLongPolling  = "long-polling"
+	bayeuxHandshake    = "/meta/handshake"
+	bayeuxConnect      = "/meta/connect"
+	bayeuxSubscribe    = "/meta/subscribe"
+	bayeuxUnsubscribe  = "/meta/unsubscribe"
+	bayeuxDisconnect   = "/meta/disconnect"
+	bayeuxMetaPrefix   = "/meta/"
+	streamingReplayExt = "replay"
+)
+
+// StreamingReconnects represents the reconnect advice values a Bayeux server may send.
+var StreamingReconnects = struct {
+	Retry     string
+	Handshake string
+	None      string
+}{
+	Retry:     "retry",
+	Handshake: "handshake",
+	None:      "none",
+}
+
+// StreamingMessage represents a Bayeux message sent to or received from the Streaming API.
+//
+// Reference: https://docs.cometd.org/current/reference/#_bayeux
+type StreamingMessage struct {
+	Channel                  string                 `json:"channel"`
+	ID                       string                 `json:"id,omitempty"`
+	ClientID                 string                 `json:"clientId,omitempty"`
+	Successful               bool                   `json:"successful,omitempty"`
+	Error                    string                 `json:"error,omitempty"`
+	Subscription             string                 `json:"subscription,omitempty"`
+	Version                  string                 `json:"version,omitempty"`
+	MinimumVersion           string                 `json:"minimumVersion,omitempty"`
+	SupportedConnectionTypes []string               `json:"supportedConnectionTypes,omitempty"`
+	ConnectionType           string                 `json:"connectionType,omitempty"`
+	Advice                   *StreamingAdvice       `json:"advice,omitempty"`
+	Ext                      map[string]interface{} `json:"ext,omitempty"`
+	Data                     json.RawMessage        `json:"data,omitempty"`
+}
+
+// StreamingAdvice represents how the server wants the client to reconnect.
+type StreamingAdvice struct {
+	// Reconnect is one of the StreamingReconnects values.
+	Reconnect string `json:"reconnect,omitempty"`
+
+	// Interval is the number of milliseconds to wait before the next connect request.
+	Interval int `json:"interval,omitempty"`
+
+	// Timeout is the number of milliseconds the server holds a connect request open.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// StreamingEvent represents an event delivered on a subscribed channel.
+//
+// PushTopic events populate Type and SObject. Generic streaming, platform event and change data capture events
+// populate Payload instead.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_streaming.meta/api_streaming/using_streaming_api_durability.htm
+type StreamingEvent struct {
+	// Channel is the channel the event was published to, such as `/topic/AccountUpdates`.
+	Channel string
+
+	// ReplayID identifies the event within the channel and can be used to resume a subscription.
+	ReplayID int64
+
+	// CreatedDate is when the event was created, if sent by the server.
+	CreatedDate string
+
+	// Type is the PushTopic event type, such as `created` or `updated`.
+	Type string
+
+	// SObject holds the fields of the record that triggered a PushTopic event.
+	SObject SObject
+
+	// Schema is the ID of the schema describing the Payload of platform and change data capture events.
+	Schema string
+
+	// Payload is the raw event payload.
+	Payload json.RawMessage
+
+	// Data is the raw data of the Bayeux message.
+	Data json.RawMessage
+}
+
+// DecodePayload unmarshals the event payload into v.
+func (e *StreamingEvent) DecodePayload(v interface{}) error {
+	if len(e.Payload) == 0 {
+		return fmt.Errorf("event on %s has no payload", e.Channel)
+	}
+	return json.Unmarshal(e.Payload, v)
+}
+
+// streamingEventData is the common shape of Streaming API message data.
+type streamingEventData struct {
+	Event struct {
+		CreatedDate string `json:"createdDate"`
+		ReplayID    int64  `json:"replayId"`
+		Type        string `json:"type"`
+	} `json:"event"`
+	SObject SObject         `json:"sobject"`
+	Schema  string          `json:"schema"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// StreamingClient is a CometD long-polling client for the Streaming API.
+//
+// A StreamingClient handshakes when created and keeps a connect request open in the background, delivering
+// events to each Subscription's channel. If the server advises a new handshake, for example because the session
+// was dropped, the client handshakes again and resubscribes every channel from the last replay ID it received
+// so no events are missed.
+//
+//	stream, err := client.Streaming()
+//	sub, err := stream.Subscribe("/topic/AccountUpdates", force.ReplayNew)
+//	for event := range sub.Events() {
+//		...
+//	}
+type StreamingClient struct {
+	http *simpleresty.Client
+	url  string
+
+	bufferSize    int
+	maxRetries    int
+	retryInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// mu protects all fields below.
+	mu sync.Mutex
+
+	clientID      string
+	messageID     int
+	advice        StreamingAdvice
+	subscriptions map[string]*Subscription
+	replay        map[string]int64
+	err           error
+}
+
+// StreamingOption is a functional option for configuring a StreamingClient.
+type StreamingOption func(*StreamingClient) error
+
+// StreamingBufferSize sets the number of events buffered per Subscription before delivery blocks.
+func StreamingBufferSize(size int) StreamingOption {
+	return func(s *StreamingClient) error {
+		if size < 0 {
+			return fmt.Errorf("buffer size cannot be negative")
+		}
+		s.bufferSize = size
+		return nil
+	}
+}
+
+// StreamingMaxRetries sets the number of consecutive failed requests tolerated before the client stops.
+func StreamingMaxRetries(retries int) StreamingOption {
+	return func(s *StreamingClient) error {
+		if retries < 0 {
+			return fmt.Errorf("max retries cannot be negative")
+		}
+		s.maxRetries = retries
+		return nil
+	}
+}
+
+// StreamingRetryInterval sets the delay before retrying a failed request.
+func StreamingRetryInterval(interval time.Duration) StreamingOption {
+	return func(s *StreamingClient) error {
+		s.retryInterval = interval
+		return nil
+	}
+}
+
+// Streaming handshakes with the Streaming API and returns a connected StreamingClient.
+// Callers should Close it when finished.
+func (c *Client) Streaming(opts ...StreamingOption) (*StreamingClient, error) {
+	h := simpleresty.NewWithBaseURL(c.instanceURL)
+	h.SetHeader("Authorization", "Bearer "+c.accessToken).
+		SetHeader("Content-type", MediaTypeJSON).
+		SetHeader("Accept", MediaTypeJSON).
+		SetHeader("User-Agent", c.userAgent).
+		SetHeaders(c.customHTTPHeaders).
+		SetTimeout(3 * time.Minute)
+	if c.transport != nil {
+		h.SetTransport(c.transport)
+	}
+
+	s := &StreamingClient{
+		http:          h,
+		url:           h.RequestURL(fmt.Sprintf("/cometd/%s", strings.TrimPrefix(c.apiVersion, "v"))),
+		bufferSize:    DefaultStreamingBufferSize,
+		maxRetries:    DefaultStreamingMaxRetries,
+		retryInterval: DefaultStreamingRetryInterval,
+		done:          make(chan struct{}),
+		subscriptions: map[string]*Subscription{},
+		replay:        map[string]int64{},
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	if err := s.handshake(); err != nil {
+		s.cancel()
+		return nil, err
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// ClientID returns the Bayeux client ID assigned by the last handshake.
+func (s *StreamingClient) ClientID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientID
+}
+
+// ReplayID returns the replay ID of the last event received on a channel, or the replay ID the channel was
+// subscribed with if no events have been received.
+func (s *StreamingClient) ReplayID(channel string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.replay[channel]
+	return id, ok
+}
+
+// Done is closed once the client stops, either because it was closed or it could not reconnect.
+func (s *StreamingClient) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that stopped the client, if any.
+func (s *StreamingClient) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Subscribe subscribes to a channel, such as `/topic/AccountUpdates` or `/u/Notifications`, starting after the
+// replay ID. Use ReplayNew to only receive new events or ReplayAll to receive every retained event.
+func (s *StreamingClient) Subscribe(channel string, replayID int64) (*Subscription, error) {
+	s.mu.Lock()
+	if s.err != nil || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("streaming client is closed")
+	}
+	if _, ok := s.subscriptions[channel]; ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("already subscribed to %s", channel)
+	}
+
+	// Register the subscription before subscribing as events can arrive on a pending connect request.
+	sub := &Subscription{
+		channel: channel,
+		stream:  s,
+		events:  make(chan *StreamingEvent, s.bufferSize),
+		done:    make(chan struct{}),
+	}
+	s.subscriptions[channel] = sub
+	s.replay[channel] = replayID
+	s.mu.Unlock()
+
+	if err := s.subscribe(channel, replayID); err != nil {
+		s.mu.Lock()
+		delete(s.subscriptions, channel)
+		delete(s.replay, channel)
+		s.mu.Unlock()
+		sub.close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Close disconnects from the server, stops the client and closes every Subscription's events channel.
+func (s *StreamingClient) Close() error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	s.cancel()
+	<-s.done
+
+	_, err := s.send(context.Background(), &StreamingMessage{Channel: bayeuxDisconnect, ClientID: s.ClientID()})
+	return err
+}
+
+// run keeps a connect request open until the client is closed or reconnecting fails.
+func (s *StreamingClient) run() {
+	defer close(s.done)
+	defer s.closeSubscriptions()
+
+	failures := 0
+	needHandshake := false
+	for s.ctx.Err() == nil {
+		if needHandshake {
+			if err := s.handshake(); err != nil {
+				if !s.retry(&failures, err) {
+					return
+				}
+				continue
+			}
+			if err := s.resubscribe(); err != nil {
+				if !s.retry(&failures, err) {
+					return
+				}
+				continue
+			}
+			needHandshake = false
+		}
+
+		reply, err := s.connect()
+		if err != nil {
+			if !s.retry(&failures, err) {
+				return
+			}
+			needHandshake = true
+			continue
+		}
+
+		advice := s.updateAdvice(reply.Advice)
+		if !reply.Successful {
+			if advice.Reconnect == StreamingReconnects.None {
+				s.stop(fmt.Errorf("server refused to reconnect: %s", reply.Error))
+				return
+			}
+			if !s.retry(&failures, fmt.Errorf("connect failed: %s", reply.Error)) {
+				return
+			}
+			needHandshake = advice.Reconnect == StreamingReconnects.Handshake
+			continue
+		}
+
+		failures = 0
+		switch advice.Reconnect {
+		case StreamingReconnects.None:
+			s.stop(fmt.Errorf("server advised not to reconnect"))
+			return
+		case StreamingReconnects.Handshake:
+			needHandshake = true
+		}
+		if !s.sleep(time.Duration(advice.Interval) * time.Millisecond) {
+			return
+		}
+	}
+}
+
+// retry records a failure and waits before the next attempt. It returns false if the client should stop.
+func (s *StreamingClient) retry(failures *int, err error) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	*failures++
+	if *failures > s.maxRetries {
+		s.stop(err)
+		return false
+	}
+	return s.sleep(s.retryInterval)
+}
+
+func (s *StreamingClient) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return s.ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *StreamingClient) stop(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *StreamingClient) closeSubscriptions() {
+	s.mu.Lock()
+	subs := make([]*Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+func (s *StreamingClient) updateAdvice(advice *StreamingAdvice) StreamingAdvice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if advice != nil {
+		s.advice = *advice
+	}
+	return s.advice
+}
+
+func (s *StreamingClient) handshake() error {
+	reply, err := s.send(s.ctx, &StreamingMessage{
+		Channel:                  bayeuxHandshake,
+		Version:                  bayeuxVersion,
+		MinimumVersion:           bayeuxVersion,
+		SupportedConnectionTypes: []string{bayeuxLongPolling},
+		Ext:                      map[string]interface{}{streamingReplayExt: true},
+	})
+	if err != nil {
+		return err
+	}
+	if !reply.Successful {
+		return fmt.Errorf("handshake failed: %s", reply.Error)
+	}
+
+	s.mu.Lock()
+	s.clientID = reply.ClientID
+	s.mu.Unlock()
+	s.updateAdvice(reply.Advice)
+	return nil
+}
+
+// resubscribe subscribes every channel again from its last replay ID after a new handshake.
+func (s *StreamingClient) resubscribe() error {
+	s.mu.Lock()
+	replay := make(map[string]int64, len(s.subscriptions))
+	for channel := range s.subscriptions {
+		replay[channel] = s.replay[channel]
+	}
+	s.mu.Unlock()
+
+	for channel, replayID := range replay {
+		if err := s.subscribe(channel, replayID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *StreamingClient) subscribe(channel string, replayID int64) error {
+	reply, err := s.send(s.ctx, &StreamingMessage{
+		Channel:      bayeuxSubscribe,
+		ClientID:     s.ClientID(),
+		Subscription: channel,
+		Ext:          map[string]interface{}{streamingReplayExt: map[string]int64{channel: replayID}},
+	})
+	if err != nil {
+		return err
+	}
+	if !reply.Successful {
+		return fmt.Errorf("unable to subscribe to %s: %s", channel, reply.Error)
+	}
+	return nil
+}
+
+func (s *StreamingClient) unsubscribe(sub *Subscription) error {
+	s.mu.Lock()
+	if s.subscriptions[sub.channel] != sub {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.subscriptions, sub.channel)
+	delete(s.replay, sub.channel)
+	s.mu.Unlock()
+	sub.close()
+
+	reply, err := s.send(s.ctx, &StreamingMessage{
+		Channel:      bayeuxUnsubscribe,
+		ClientID:     s.ClientID(),
+		Subscription: sub.channel,
+	})
+	if err != nil {
+		return err
+	}
+	if !reply.Successful {
+		return fmt.Errorf("unable to unsubscribe from %s: %s", sub.channel, reply.Error)
+	}
+	return nil
+}
+
+// connect sends a connect request, delivers any events in the response and returns the connect reply.
+func (s *StreamingClient) connect() (*StreamingMessage, error) {
+	messages, err := s.exchange(s.ctx, &StreamingMessage{
+		Channel:        bayeuxConnect,
+		ClientID:       s.ClientID(),
+		ConnectionType: bayeuxLongPolling,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var reply *StreamingMessage
+	for _, m := range messages {
+		switch {
+		case m.Channel == bayeuxConnect:
+			reply = m
+		case !strings.HasPrefix(m.Channel, bayeuxMetaPrefix):
+			s.deliver(m)
+		}
+	}
+	if reply == nil {
+		return nil, fmt.Errorf("no reply received for %s", bayeuxConnect)
+	}
+	return reply, nil
+}
+
+// deliver decodes an event message and sends it to the channel's Subscription.
+func (s *StreamingClient) deliver(m *StreamingMessage) {
+	var data streamingEventData
+	if len(m.Data) > 0 {
+		// Generic streaming events may carry data that doesn't match the common shape.
+		_ = json.Unmarshal(m.Data, &data)
+	}
+
+	event := &StreamingEvent{
+		Channel:     m.Channel,
+		ReplayID:    data.Event.ReplayID,
+		CreatedDate: data.Event.CreatedDate,
+		Type:        data.Event.Type,
+		SObject:     data.SObject,
+		Schema:      data.Schema,
+		Payload:     data.Payload,
+		Data:        m.Data,
+	}
+
+	s.mu.Lock()
+	sub, ok := s.subscriptions[m.Channel]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if sub.send(s.ctx, event) && event.ReplayID > 0 {
+		s.mu.Lock()
+		if s.subscriptions[m.Channel] == sub {
+			s.replay[m.Channel] = event.ReplayID
+		}
+		s.mu.Unlock()
+	}
+}
+
+// send sends a single meta message and returns the reply on the same channel.
+func (s *StreamingClient) send(ctx context.Context, msg *StreamingMessage) (*StreamingMessage, error) {
+	messages, err := s.exchange(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range messages {
+		if m.Channel == msg.Channel && m.ID == msg.ID {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no reply received for %s", msg.Channel)
+}
+
+// exchange posts a message and returns every message in the response.
+func (s *StreamingClient) exchange(ctx context.Context, msg *StreamingMessage) ([]*StreamingMessage, error) {
+	s.mu.Lock()
+	s.messageID++
+	msg.ID = strconv.Itoa(s.messageID)
+	s.mu.Unlock()
+
+	var messages []*StreamingMessage
+	req := s.http.ConstructRequest(&messages, []*StreamingMessage{msg}).SetContext(ctx)
+	req.Method = http.MethodPost
+	req.URL = s.url
+
+	if _, err := s.http.Dispatch(req); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Subscription represents a subscribed Streaming API channel.
+type Subscription struct {
+	channel string
+	stream  *StreamingClient
+	events  chan *StreamingEvent
+
+	// done is closed when the subscription is closed, unblocking any pending send.
+	done chan struct{}
+
+	// mu serializes sends with closing the events channel.
+	mu     sync.Mutex
+	closed bool
+	once   sync.Once
+}
+
+// Channel returns the subscribed channel name.
+func (sub *Subscription) Channel() string {
+	return sub.channel
+}
+
+// Events returns the channel events are delivered on. It is closed when the Subscription is unsubscribed or the
+// StreamingClient stops.
+func (sub *Subscription) Events() <-chan *StreamingEvent {
+	return sub.events
+}
+
+// Unsubscribe stops delivery of events and closes the events channel.
+func (sub *Subscription) Unsubscribe() error {
+	return sub.stream.unsubscribe(sub)
+}
+
+// send delivers an event, blocking until it's received, the subscription closes or ctx is done.
+func (sub *Subscription) send(ctx context.Context, event *StreamingEvent) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return false
+	}
+	select {
+	case sub.events <- event:
+		return true
+	case <-sub.done:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (sub *Subscription) close() {
+	sub.once.Do(func() {
+		close(sub.done)
+		sub.mu.Lock()
+		sub.closed = true
+		close(sub.events)
+		sub.mu.Unlock()
+	})
+}
@@ -0,0 +1,224 @@
+package force
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultSyncBatchSize is the number of records fetched and passed to SyncSink.Upsert at once.
+const DefaultSyncBatchSize = 200
+
+// SyncSink receives the changes found by a Syncer, such as a warehouse table per SObject.
+//
+// A window can be synced more than once if a Syncer fails before saving its watermark, so sinks should be
+// idempotent.
+type SyncSink interface {
+	// Upsert writes created or updated records of a SObject.
+	Upsert(objectName string, records []SObject) error
+
+	// Delete removes deleted records of a SObject.
+	Delete(objectName string, records []*DeletedRecord) error
+}
+
+// SyncResult represents the changes synced for a SObject.
+type SyncResult struct {
+	ObjectName string
+	Upserted   int
+	Deleted    int
+
+	// Watermark is the point in time up to which changes were synced. The next sync starts from it.
+	Watermark time.Time
+}
+
+// SyncOption is a functional option for configuring a Syncer.
+type SyncOption func(*Syncer) error
+
+// SyncFields sets the fields fetched for the created or updated records of a SObject.
+// By default, every field is fetched.
+func SyncFields(objectName string, fields ...string) SyncOption {
+	return func(s *Syncer) error {
+		if len(fields) == 0 {
+			return fmt.Errorf("at least one field is required to sync %s", objectName)
+		}
+		s.fields[strings.ToLower(objectName)] = fields
+		return nil
+	}
+}
+
+// SyncStart sets where a SObject with no saved watermark starts syncing from. Defaults to as far back as
+// ReplicationRetention allows.
+func SyncStart(start time.Time) SyncOption {
+	return func(s *Syncer) error {
+		s.start = start
+		return nil
+	}
+}
+
+// SyncBatchSize sets the number of records fetched and passed to SyncSink.Upsert at once.
+func SyncBatchSize(size int) SyncOption {
+	return func(s *Syncer) error {
+		if size < 1 {
+			return fmt.Errorf("sync batch size must be positive")
+		}
+		s.batchSize = size
+		return nil
+	}
+}
+
+// Syncer incrementally syncs the changes of SObjects to a SyncSink using GetUpdated and GetDeleted.
+//
+// Each sync covers the window from the SObject's saved watermark to now. Created or updated records are fetched
+// and upserted, deleted records are deleted, and only then is the new watermark saved.
+type Syncer struct {
+	client    *Client
+	store     WatermarkStore
+	sink      SyncSink
+	fields    map[string][]string
+	start     time.Time
+	batchSize int
+}
+
+// NewSyncer returns a Syncer writing changes to sink and keeping watermarks in store.
+func NewSyncer(client *Client, store WatermarkStore, sink SyncSink, opts ...SyncOption) (*Syncer, error) {
+	switch {
+	case client == nil:
+		return nil, fmt.Errorf("client must be defined")
+	case store == nil:
+		return nil, fmt.Errorf("watermark store must be defined")
+	case sink == nil:
+		return nil, fmt.Errorf("sync sink must be defined")
+	}
+
+	s := &Syncer{client: client, store: store, sink: sink, fields: map[string][]string{},
+		batchSize: DefaultSyncBatchSize}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Sync syncs the changes of each SObject in turn, stopping at the first failure.
+func (s *Syncer) Sync(objectNames ...string) ([]*SyncResult, error) {
+	results := make([]*SyncResult, 0, len(objectNames))
+	for _, name := range objectNames {
+		result, err := s.SyncObject(name)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SyncObject syncs the changes of a SObject made since its watermark.
+func (s *Syncer) SyncObject(objectName string) (*SyncResult, error) {
+	end := time.Now()
+	start, ok, err := s.store.Load(objectName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		start = s.start
+		if start.IsZero() {
+			start = end.Add(-ReplicationRetention).Add(time.Minute)
+		}
+	}
+
+	updated, _, err := s.client.GetUpdated(objectName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	deleted, _, err := s.client.GetDeleted(objectName, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resume from the earlier of the two so no change is skipped.
+	watermark, err := parseWatermark(updated.LatestDateCovered, end)
+	if err != nil {
+		return nil, err
+	}
+	deletedWatermark, err := parseWatermark(deleted.LatestDateCovered, end)
+	if err != nil {
+		return nil, err
+	}
+	if deletedWatermark.Before(watermark) {
+		watermark = deletedWatermark
+	}
+
+	result := &SyncResult{ObjectName: objectName, Watermark: watermark}
+	if result.Upserted, err = s.upsert(objectName, updated.IDs); err != nil {
+		return nil, err
+	}
+	if len(deleted.DeletedRecords) > 0 {
+		if err := s.sink.Delete(objectName, deleted.DeletedRecords); err != nil {
+			return nil, err
+		}
+		result.Deleted = len(deleted.DeletedRecords)
+	}
+
+	if err := s.store.Save(objectName, watermark); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// upsert fetches the records in batches and passes them to the sink. Records deleted since they were reported
+// as updated are skipped.
+func (s *Syncer) upsert(objectName string, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	fields, ok := s.fields[strings.ToLower(objectName)]
+	if !ok {
+		meta, _, err := s.client.Describe(objectName)
+		if err != nil {
+			return 0, err
+		}
+		fields = meta.GetFieldNames()
+		s.fields[strings.ToLower(objectName)] = fields
+	}
+
+	upserted := 0
+	for start := 0; start < len(ids); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		soql := fmt.Sprintf("select %s from %s where Id in ('%s')", strings.Join(fields, ", "), objectName,
+			strings.Join(ids[start:end], "', '"))
+		records := make([]SObject, 0, end-start)
+		iter := s.client.Iterate(&QueryRequest{SOQL: soql})
+		for iter.Next() {
+			records = append(records, iter.Record())
+		}
+		if err := iter.Err(); err != nil {
+			return upserted, err
+		}
+
+		if len(records) > 0 {
+			if err := s.sink.Upsert(objectName, records); err != nil {
+				return upserted, err
+			}
+		}
+		upserted += len(records)
+	}
+	return upserted, nil
+}
+
+// parseWatermark parses a latestDateCovered, which is empty if the window had no changes.
+func parseWatermark(latestDateCovered string, end time.Time) (time.Time, error) {
+	if latestDateCovered == "" {
+		return end, nil
+	}
+	t, err := time.Parse(DateTimeFormat, latestDateCovered)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid latestDateCovered %q: %v", latestDateCovered, err)
+	}
+	return t, nil
+}
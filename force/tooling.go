@@ -0,0 +1,219 @@
+package force
+
+import (
+	"fmt"
+	"github.com/davidji99/simpleresty"
+	"strings"
+)
+
+// ToolingService handles communication with the Tooling API, which exposes metadata such as ApexClass,
+// ApexTrigger and ApexCodeCoverageAggregate along with the ability to execute anonymous Apex and run tests.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_tooling.meta/api_tooling/intro_rest_resources.htm
+type ToolingService service
+
+// toolingURL returns the request URL of a Tooling API resource.
+func (t *ToolingService) toolingURL(resource string) string {
+	return t.client.http.RequestURL(fmt.Sprintf("/services/data/%s/tooling/%s", t.client.apiVersion, resource))
+}
+
+// Query executes a SOQL query against Tooling API objects.
+func (t *ToolingService) Query(q *QueryRequest) (*QueryResult, *simpleresty.Response, error) {
+	var result *QueryResult
+	urlStr, urlStrErr := simpleresty.AddQueryParams(t.toolingURL("query"), q)
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := t.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// QueryMore retrieves the next page of results using the NextRecordsURL of a previous QueryResult.
+func (t *ToolingService) QueryMore(nextRecordsURL string) (*QueryResult, *simpleresty.Response, error) {
+	return t.client.QueryMore(nextRecordsURL)
+}
+
+// QueryAll executes a query and retrieves every page of results.
+func (t *ToolingService) QueryAll(q *QueryRequest) ([]SObject, error) {
+	result, _, err := t.Query(q)
+	if err != nil {
+		return nil, err
+	}
+
+	records := result.Records
+	for !result.Done && result.NextRecordsURL != "" {
+		if result, _, err = t.QueryMore(result.NextRecordsURL); err != nil {
+			return nil, err
+		}
+		records = append(records, result.Records...)
+	}
+	if records == nil {
+		records = []SObject{}
+	}
+	return records, nil
+}
+
+// Describe gets the metadata regarding a Tooling API object.
+func (t *ToolingService) Describe(objectName string) (*SObjectMetadata, *simpleresty.Response, error) {
+	var result *SObjectMetadata
+	urlStr := t.toolingURL(fmt.Sprintf("sobjects/%s/describe", objectName))
+
+	response, getErr := t.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// Create a new Tooling API object, such as an ApexClass.
+func (t *ToolingService) Create(objectName string, opts interface{}) (*SObjectCreateResult, *simpleresty.Response, error) {
+	var result *SObjectCreateResult
+	urlStr := t.toolingURL(fmt.Sprintf("sobjects/%s", objectName))
+
+	response, err := t.client.http.Post(urlStr, &result, opts)
+	return result, response, err
+}
+
+// Get retrieves an existing Tooling API object by its ID.
+//
+// If any fields are specified, only those fields are returned. Otherwise, all fields are returned.
+func (t *ToolingService) Get(objectName, objectId string, fields ...string) (SObject, *simpleresty.Response, error) {
+	var result SObject
+	urlStr, urlStrErr := simpleresty.AddQueryParams(t.toolingURL(fmt.Sprintf("sobjects/%s/%s", objectName, objectId)),
+		&SObjectGetRequest{Fields: strings.Join(fields, ",")})
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := t.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, response, getErr
+	}
+
+	return result, response, nil
+}
+
+// Update an existing Tooling API object.
+func (t *ToolingService) Update(objectName, objectId string, opts interface{}) (*simpleresty.Response, error) {
+	urlStr := t.toolingURL(fmt.Sprintf("sobjects/%s/%s", objectName, objectId))
+
+	response, err := t.client.http.Patch(urlStr, nil, opts)
+	return response, err
+}
+
+// Destroy deletes an existing Tooling API object.
+func (t *ToolingService) Destroy(objectName, objectId string) (*simpleresty.Response, error) {
+	urlStr := t.toolingURL(fmt.Sprintf("sobjects/%s/%s", objectName, objectId))
+
+	response, err := t.client.http.Delete(urlStr, nil, nil)
+	return response, err
+}
+
+// ExecuteAnonymousRequest represents the query parameters of an execute anonymous request.
+type ExecuteAnonymousRequest struct {
+	AnonymousBody string `url:"anonymousBody"`
+}
+
+// ExecuteAnonymousResult represents the result of executing anonymous Apex.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_tooling.meta/api_tooling/intro_rest_resources.htm
+type ExecuteAnonymousResult struct {
+	// Line is the line of the compile problem or exception, or -1 if there is none.
+	Line int `json:"line"`
+
+	// Column is the column of the compile problem or exception, or -1 if there is none.
+	Column int `json:"column"`
+
+	// Compiled indicates whether the Apex compiled.
+	Compiled bool `json:"compiled"`
+
+	// Success indicates whether the Apex compiled and ran without an unhandled exception.
+	Success bool `json:"success"`
+
+	// CompileProblem describes why the Apex didn't compile.
+	CompileProblem *string `json:"compileProblem"`
+
+	// ExceptionMessage is the message of the unhandled exception thrown by the Apex.
+	ExceptionMessage *string `json:"exceptionMessage"`
+
+	// ExceptionStackTrace is the stack trace of the unhandled exception thrown by the Apex.
+	ExceptionStackTrace *string `json:"exceptionStackTrace"`
+}
+
+// ExecuteAnonymous compiles and executes the Apex in a single transaction.
+//
+// A compile problem or unhandled exception isn't returned as an error; check the Compiled and Success fields
+// of the result instead.
+func (t *ToolingService) ExecuteAnonymous(apex string) (*ExecuteAnonymousResult, *simpleresty.Response, error) {
+	var result *ExecuteAnonymousResult
+	urlStr, urlStrErr := simpleresty.AddQueryParams(t.toolingURL("executeAnonymous"),
+		&ExecuteAnonymousRequest{AnonymousBody: apex})
+	if urlStrErr != nil {
+		return nil, nil, urlStrErr
+	}
+
+	response, getErr := t.client.http.Get(urlStr, &result, nil)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	return result, response, nil
+}
+
+// RunTestsRequest represents the body of a request to run Apex tests.
+//
+// Tests are selected by ClassIDs, SuiteIDs or Tests, which may also limit a class to specific methods. Reference:
+// https://developer.salesforce.com/docs/atlas.en-us.api_tooling.meta/api_tooling/intro_rest_resources_runtestsasynchronous.htm
+type RunTestsRequest struct {
+	// ClassIDs is a comma separated list of test class IDs.
+	ClassIDs string `json:"classids,omitempty"`
+
+	// SuiteIDs is a comma separated list of test suite IDs.
+	SuiteIDs string `json:"suiteids,omitempty"`
+
+	// MaxFailedTests stops the run after the number of failures. Defaults to running every test.
+	MaxFailedTests *int `json:"maxFailedTests,omitempty"`
+
+	// TestLevel is one of the TestLevels, such as `RunSpecifiedTests`.
+	TestLevel string `json:"testLevel,omitempty"`
+
+	// Tests lists the classes, and optionally methods, to run.
+	Tests []*TestItem `json:"tests,omitempty"`
+
+	// SkipCodeCoverage disables code coverage calculation to speed up the run.
+	SkipCodeCoverage *bool `json:"skipCodeCoverage,omitempty"`
+}
+
+// TestItem represents a test class, and optionally some of its methods, to run.
+type TestItem struct {
+	ClassID     string   `json:"classId,omitempty"`
+	ClassName   string   `json:"className,omitempty"`
+	TestMethods []string `json:"testMethods,omitempty"`
+}
+
+// TestLevels represents all test levels of an Apex test run.
+var TestLevels = struct {
+	RunSpecifiedTests string
+	RunLocalTests     string
+	RunAllTestsInOrg  string
+}{
+	RunSpecifiedTests: "RunSpecifiedTests",
+	RunLocalTests:     "RunLocalTests",
+	RunAllTestsInOrg:  "RunAllTestsInOrg",
+}
+
+// RunTestsAsynchronous queues Apex tests and returns the ID of the AsyncApexJob running them. Progress can be
+// followed by querying ApexTestQueueItem and ApexTestResult records with the job ID.
+func (t *ToolingService) RunTestsAsynchronous(opts *RunTestsRequest) (string, *simpleresty.Response, error) {
+	var jobID string
+	urlStr := t.toolingURL("runTestsAsynchronous")
+
+	response, err := t.client.http.Post(urlStr, &jobID, opts)
+	return jobID, response, err
+}
@@ -0,0 +1,406 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError represents a record that failed validation against its SObject's describe metadata.
+// Like the errors Salesforce returns, each violation is a SObjectError.
+type ValidationError struct {
+	// ObjectName is the name of the validated SObject.
+	ObjectName string
+
+	// Errors holds every violation found.
+	Errors []*SObjectError
+}
+
+// Error summarizes the violations.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid %s record: %s", e.ObjectName, strings.Join(messages, "; "))
+}
+
+// Validator checks records against the describe metadata of a SObject before they are written, reporting
+// unknown, read-only and missing required fields, strings that are too long, numbers out of range, values of the
+// wrong type, bad restricted picklist values, dependent picklist values not valid for their controlling value and
+// references to the wrong kind of record.
+type Validator struct {
+	meta *SObjectMetadata
+
+	// keyPrefixes holds the key prefixes of reference targets keyed by lower-cased SObject name.
+	keyPrefixes map[string]string
+}
+
+// NewValidator returns a Validator for the SObject described by meta. The metadata of the SObjects its
+// references point to can be supplied to check that referenced IDs have the right key prefix; otherwise only
+// the format of referenced IDs is checked.
+func NewValidator(meta *SObjectMetadata, references ...*SObjectMetadata) *Validator {
+	v := &Validator{meta: meta, keyPrefixes: map[string]string{}}
+	for _, r := range append([]*SObjectMetadata{meta}, references...) {
+		if r.GetKeyPrefix() != "" {
+			v.keyPrefixes[strings.ToLower(r.GetName())] = r.GetKeyPrefix()
+		}
+	}
+	return v
+}
+
+// ValidateCreate validates a record about to be created, which can be a SObject, a map or a struct with json
+// tags. It returns a *ValidationError listing every violation, or nil if there are none.
+func (v *Validator) ValidateCreate(record interface{}) error {
+	return v.validate(record, true)
+}
+
+// ValidateUpdate validates the fields of a record about to be updated. Unlike ValidateCreate, required fields
+// are only checked if they are being cleared.
+func (v *Validator) ValidateUpdate(record interface{}) error {
+	return v.validate(record, false)
+}
+
+func (v *Validator) validate(record interface{}, create bool) error {
+	fields, err := toSObject(record)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s record: %v", v.meta.GetName(), err)
+	}
+	delete(fields, "attributes")
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]*SObjectError, 0)
+	for _, name := range names {
+		if !create && strings.EqualFold(name, "Id") {
+			continue
+		}
+
+		field := v.meta.FindField(name)
+		if field == nil {
+			if v.isRelationship(name, fields[name]) {
+				continue
+			}
+			errs = append(errs, &SObjectError{StatusCode: "INVALID_FIELD",
+				Message: fmt.Sprintf("No such column '%s' on sobject of type %s", name, v.meta.GetName()),
+				Fields:  []string{name}})
+			continue
+		}
+
+		if (create && !field.GetCreateable()) || (!create && !field.GetUpdateable()) {
+			errs = append(errs, &SObjectError{StatusCode: "INVALID_FIELD_FOR_INSERT_UPDATE",
+				Message: fmt.Sprintf("Unable to create/update fields: %s", field.GetName()),
+				Fields:  []string{field.GetName()}})
+			continue
+		}
+
+		if err := v.validateValue(field, fields[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	dependentErrs, err := v.validateDependentPicklists(fields, create)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, dependentErrs...)
+
+	if missing := v.missingFields(fields, create); len(missing) > 0 {
+		errs = append(errs, &SObjectError{StatusCode: "REQUIRED_FIELD_MISSING",
+			Message: fmt.Sprintf("Required fields are missing: [%s]", strings.Join(missing, ", ")), Fields: missing})
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{ObjectName: v.meta.GetName(), Errors: errs}
+	}
+	return nil
+}
+
+// isRelationship reports whether name is the relationship of a reference field, such as `Account` set to
+// an external ID to resolve `AccountId`.
+func (v *Validator) isRelationship(name string, value interface{}) bool {
+	if _, ok := value.(map[string]interface{}); !ok {
+		if _, ok := value.(SObject); !ok {
+			return false
+		}
+	}
+	for _, f := range v.meta.Fields {
+		if strings.EqualFold(f.GetRelationshipName(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingFields returns the required fields that are absent on create or cleared on either write.
+func (v *Validator) missingFields(fields SObject, create bool) []string {
+	supplied := map[string]interface{}{}
+	for name, value := range fields {
+		supplied[strings.ToLower(name)] = value
+	}
+
+	missing := make([]string, 0)
+	for _, f := range v.meta.Fields {
+		if f.GetNillable() || f.GetDefaultedOnCreate() || f.Type == FieldDataTypes.Boolean {
+			continue
+		}
+		if (create && !f.GetCreateable()) || (!create && !f.GetUpdateable()) {
+			continue
+		}
+
+		value, ok := supplied[strings.ToLower(f.GetName())]
+		if !ok && v.relationshipSupplied(f, supplied) {
+			continue
+		}
+		if (create && !ok) || (ok && (value == nil || value == "")) {
+			missing = append(missing, f.GetName())
+		}
+	}
+	return missing
+}
+
+// relationshipSupplied reports whether a reference field is set through its relationship.
+func (v *Validator) relationshipSupplied(f *SObjectFieldMetadata, supplied map[string]interface{}) bool {
+	if f.GetRelationshipName() == "" {
+		return false
+	}
+	_, ok := supplied[strings.ToLower(f.GetRelationshipName())]
+	return ok
+}
+
+// validateValue checks a value against the field's type, length, range and allowed values.
+func (v *Validator) validateValue(field *SObjectFieldMetadata, value interface{}) *SObjectError {
+	name := field.GetName()
+	if value == nil {
+		return nil
+	}
+	invalidType := func(expected string) *SObjectError {
+		return &SObjectError{StatusCode: "INVALID_TYPE_ON_FIELD_IN_RECORD",
+			Message: fmt.Sprintf("%s: value not of required type: %v (expected %s)", name, value, expected),
+			Fields:  []string{name}}
+	}
+
+	switch field.Type {
+	case FieldDataTypes.AnyType, FieldDataTypes.Address, FieldDataTypes.Location:
+		return nil
+	case FieldDataTypes.Boolean:
+		if _, ok := value.(bool); !ok {
+			return invalidType("boolean")
+		}
+		return nil
+	case FieldDataTypes.Int, FieldDataTypes.Long, FieldDataTypes.Double, FieldDataTypes.Currency,
+		FieldDataTypes.Percent:
+		number, ok := numberString(value)
+		if !ok {
+			return invalidType("number")
+		}
+		if field.Type == FieldDataTypes.Int || field.Type == FieldDataTypes.Long {
+			if strings.ContainsAny(number, ".eE") {
+				return invalidType("integer")
+			}
+			if field.GetDigits() > 0 && integerDigits(number) > field.GetDigits() {
+				return outOfRange(name, number, fmt.Sprintf("max digits=%d", field.GetDigits()))
+			}
+			return nil
+		}
+		if field.GetPrecision() > 0 && integerDigits(number) > field.GetPrecision()-field.GetScale() {
+			return outOfRange(name, number, fmt.Sprintf("precision=%d, scale=%d", field.GetPrecision(),
+				field.GetScale()))
+		}
+		return nil
+	case FieldDataTypes.Date, FieldDataTypes.DateTime, FieldDataTypes.Time:
+		if _, ok := value.(time.Time); ok {
+			return nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			return invalidType(string(field.Type))
+		}
+		layouts := []string{DateFormat}
+		switch field.Type {
+		case FieldDataTypes.DateTime:
+			layouts = append(append([]string{}, DefaultDateTimeLayouts...), DateFormat)
+		case FieldDataTypes.Time:
+			layouts = []string{TimeFormat, "15:04:05.000", "15:04:05", "15:04:05Z"}
+		}
+		if _, err := parseTime(s, layouts); err != nil {
+			return invalidType(string(field.Type))
+		}
+		return nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return invalidType("string")
+	}
+	if field.GetLength() > 0 && utf8.RuneCountInString(s) > field.GetLength() {
+		return &SObjectError{StatusCode: "STRING_TOO_LONG",
+			Message: fmt.Sprintf("%s: data value too large: %s (max length=%d)", name, truncate(s, 40),
+				field.GetLength()),
+			Fields: []string{name}}
+	}
+
+	switch field.Type {
+	case FieldDataTypes.Picklist, FieldDataTypes.Multipicklist:
+		if !field.GetRestrictedPicklist() || s == "" {
+			return nil
+		}
+		values := []string{s}
+		if field.Type == FieldDataTypes.Multipicklist {
+			values = strings.Split(s, ";")
+		}
+		for _, value := range values {
+			if findPicklistEntry(field, value) == nil {
+				return &SObjectError{StatusCode: "INVALID_OR_NULL_FOR_RESTRICTED_PICKLIST",
+					Message: fmt.Sprintf("%s: bad value for restricted picklist field: %s", name, value),
+					Fields:  []string{name}}
+			}
+		}
+	case FieldDataTypes.Reference:
+		if s == "" {
+			return nil
+		}
+		if !ValidID(s) {
+			return &SObjectError{StatusCode: "MALFORMED_ID",
+				Message: fmt.Sprintf("%s: id value of incorrect type: %s", name, s), Fields: []string{name}}
+		}
+		if !v.referencesTarget(field, s) {
+			return &SObjectError{StatusCode: "FIELD_INTEGRITY_EXCEPTION",
+				Message: fmt.Sprintf("%s: id value of incorrect type: %s (expected %s)", name, s,
+					strings.Join(field.ReferenceTo, " or ")),
+				Fields: []string{name}}
+		}
+	}
+	return nil
+}
+
+// referencesTarget reports whether an ID has the key prefix of one of the field's targets. It returns true if
+// the key prefix of any target is unknown.
+func (v *Validator) referencesTarget(field *SObjectFieldMetadata, id string) bool {
+	for _, target := range field.ReferenceTo {
+		prefix, ok := v.keyPrefixes[strings.ToLower(target)]
+		if !ok || strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return len(field.ReferenceTo) == 0
+}
+
+// findPicklistEntry returns the active entry of a picklist with the value, or nil if there is none.
+func findPicklistEntry(field *SObjectFieldMetadata, value string) *SObjectFieldPicklistEntryMetadata {
+	for i, entry := range field.PicklistValues {
+		if entry.GetValue() == value && (entry.Active == nil || entry.GetActive()) {
+			return &field.PicklistValues[i]
+		}
+	}
+	return nil
+}
+
+func outOfRange(name, number, limits string) *SObjectError {
+	return &SObjectError{StatusCode: "NUMBER_OUTSIDE_VALID_RANGE",
+		Message: fmt.Sprintf("%s: value outside of valid range on numeric field: %s (%s)", name, number, limits),
+		Fields:  []string{name}}
+}
+
+// numberString returns the decimal representation of a number.
+func numberString(value interface{}) (string, bool) {
+	switch n := value.(type) {
+	case json.Number:
+		return n.String(), true
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(n), true
+	}
+	return "", false
+}
+
+// integerDigits returns the number of digits before the decimal point, ignoring the sign and leading zeros.
+func integerDigits(number string) int {
+	if f, err := strconv.ParseFloat(number, 64); err == nil && strings.ContainsAny(number, "eE") {
+		number = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	whole := strings.TrimLeft(strings.SplitN(strings.TrimLeft(number, "+-"), ".", 2)[0], "0")
+	return len(whole)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// Validator returns a Validator for a SObject. The SObject is described on first use and cached by the client, and
+// the key prefixes of its reference targets, including every target of polymorphic fields such as `WhatId`, come
+// from the client's KeyPrefixes registry.
+func (c *Client) Validator(objectName string) (*Validator, error) {
+	meta, err := c.describeCached(objectName)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := c.KeyPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	v := NewValidator(meta)
+	for _, f := range meta.Fields {
+		if f.Type != FieldDataTypes.Reference {
+			continue
+		}
+		for _, target := range f.ReferenceTo {
+			if prefix, ok := registry.KeyPrefix(target); ok {
+				v.keyPrefixes[strings.ToLower(target)] = prefix
+			}
+		}
+	}
+	return v, nil
+}
+
+// describeCached returns the cached describe metadata of a SObject, describing it on first use.
+func (c *Client) describeCached(objectName string) (*SObjectMetadata, error) {
+	key := strings.ToLower(objectName)
+
+	c.cacheMu.Lock()
+	meta, ok := c.describeCache[key]
+	c.cacheMu.Unlock()
+	if ok {
+		return meta, nil
+	}
+
+	meta, _, err := c.Describe(objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cacheMu.Lock()
+	c.describeCache[key] = meta
+	if c.keyPrefixes != nil {
+		c.keyPrefixes.RegisterSObject(meta)
+	}
+	c.cacheMu.Unlock()
+	return meta, nil
+}
+
+// validate validates a record with the SObject's Validator.
+func (c *Client) validate(objectName string, record interface{}, create bool) error {
+	v, err := c.Validator(objectName)
+	if err != nil {
+		return err
+	}
+	if create {
+		return v.ValidateCreate(record)
+	}
+	return v.ValidateUpdate(record)
+}
@@ -0,0 +1,108 @@
+package force
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatermarkStore persists the point in time up to which the changes of each SObject have been synced,
+// so a Syncer can resume where it left off.
+type WatermarkStore interface {
+	// Load returns the saved watermark of a SObject. The bool is false if nothing has been saved.
+	Load(objectName string) (time.Time, bool, error)
+
+	// Save records the watermark of a SObject.
+	Save(objectName string, watermark time.Time) error
+}
+
+// MemoryWatermarkStore is a WatermarkStore that keeps watermarks in memory. It's safe for concurrent use.
+type MemoryWatermarkStore struct {
+	mu         sync.Mutex
+	watermarks map[string]time.Time
+}
+
+// NewMemoryWatermarkStore returns an empty MemoryWatermarkStore.
+func NewMemoryWatermarkStore() *MemoryWatermarkStore {
+	return &MemoryWatermarkStore{watermarks: map[string]time.Time{}}
+}
+
+// Load returns the saved watermark of a SObject.
+func (m *MemoryWatermarkStore) Load(objectName string) (time.Time, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.watermarks[objectName]
+	return t, ok, nil
+}
+
+// Save records the watermark of a SObject.
+func (m *MemoryWatermarkStore) Save(objectName string, watermark time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watermarks[objectName] = watermark
+	return nil
+}
+
+// FileWatermarkStore is a WatermarkStore that keeps watermarks in a JSON file keyed by SObject.
+//
+// Like FileReplayStore, every Save atomically rewrites the file. It's safe for concurrent use within a process.
+type FileWatermarkStore struct {
+	path string
+
+	mu         sync.Mutex
+	watermarks map[string]time.Time
+}
+
+// NewFileWatermarkStore returns a FileWatermarkStore backed by the file at path, loading any saved watermarks.
+// The file is created on the first Save if it doesn't exist.
+func NewFileWatermarkStore(path string) (*FileWatermarkStore, error) {
+	f := &FileWatermarkStore{path: path, watermarks: map[string]time.Time{}}
+
+	data, readErr := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(readErr):
+		return f, nil
+	case readErr != nil:
+		return nil, readErr
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f.watermarks); err != nil {
+			return nil, fmt.Errorf("unable to parse watermark store %s: %v", path, err)
+		}
+	}
+	return f, nil
+}
+
+// Load returns the saved watermark of a SObject.
+func (f *FileWatermarkStore) Load(objectName string) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.watermarks[objectName]
+	return t, ok, nil
+}
+
+// Save records the watermark of a SObject and writes the file.
+func (f *FileWatermarkStore) Save(objectName string, watermark time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous, existed := f.watermarks[objectName]
+	f.watermarks[objectName] = watermark
+	data, err := json.MarshalIndent(f.watermarks, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(f.path, data)
+	}
+	if err != nil {
+		if existed {
+			f.watermarks[objectName] = previous
+		} else {
+			delete(f.watermarks, objectName)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package forcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ActionFunc emulates invoking an action with a single input. It returns the output values, or an error which is
+// reported as a failed input.
+type ActionFunc func(input force.SObject) (map[string]interface{}, error)
+
+// fakeAction is an action registered with the fake server.
+type fakeAction struct {
+	describe *force.ActionDescribe
+	fn       ActionFunc
+}
+
+// actionKey returns the key of an action, where actionType is empty for standard actions.
+func actionKey(actionType, name string) string {
+	if actionType == "" {
+		return "standard/" + strings.ToLower(name)
+	}
+	return "custom/" + strings.ToLower(actionType) + "/" + strings.ToLower(name)
+}
+
+// AddStandardAction registers a standard action, such as `emailSimple`, described by its name and inputs.
+// Required inputs are validated before fn is called.
+func (s *Server) AddStandardAction(describe *force.ActionDescribe, fn ActionFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	describe.Standard = true
+	s.actions[actionKey("", describe.Name)] = &fakeAction{describe: describe, fn: fn}
+}
+
+// AddCustomAction registers a custom action of one of the force.ActionTypes, such as an autolaunched Flow.
+// Required inputs are validated before fn is called.
+func (s *Server) AddCustomAction(actionType string, describe *force.ActionDescribe, fn ActionFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	describe.Type = actionType
+	s.actions[actionKey(actionType, describe.Name)] = &fakeAction{describe: describe, fn: fn}
+}
+
+// handleActions serves the invocable actions API.
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && segments[0] == "standard":
+		s.listActions(w, r, "standard/")
+	case len(segments) == 1 && segments[0] == "custom":
+		types := map[string]string{}
+		for _, t := range []string{force.ActionTypes.Apex, force.ActionTypes.Flow, force.ActionTypes.QuickAction,
+			force.ActionTypes.EmailAlert} {
+			types[t] = fmt.Sprintf("%s/actions/custom/%s", strings.TrimSuffix(r.URL.Path, "/actions/custom"), t)
+		}
+		writeJSON(w, http.StatusOK, types)
+	case len(segments) == 2 && segments[0] == "custom":
+		s.listActions(w, r, "custom/"+strings.ToLower(segments[1])+"/")
+	case len(segments) == 2 && segments[0] == "standard":
+		s.handleAction(w, r, actionKey("", segments[1]))
+	case len(segments) == 3 && segments[0] == "custom":
+		s.handleAction(w, r, actionKey(segments[1], segments[2]))
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+func (s *Server) listActions(w http.ResponseWriter, r *http.Request, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actions := make([]*force.Action, 0)
+	for key, a := range s.actions {
+		if strings.HasPrefix(key, prefix) {
+			actions = append(actions, &force.Action{Label: a.describe.Label, Name: a.describe.Name,
+				Type: a.describe.Type, URL: r.URL.Path + "/" + a.describe.Name})
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+	writeJSON(w, http.StatusOK, &force.ActionList{Actions: actions})
+}
+
+// handleAction describes or invokes an action. Like Salesforce, the response status is 400 if any input fails
+// but every result is still returned.
+func (s *Server) handleAction(w http.ResponseWriter, r *http.Request, key string) {
+	s.mu.Lock()
+	a, ok := s.actions[key]
+	s.mu.Unlock()
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, a.describe)
+	case http.MethodPost:
+		var body struct {
+			Inputs []force.SObject `json:"inputs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+			return
+		}
+		if len(body.Inputs) == 0 || len(body.Inputs) > force.MaxActionInputs {
+			writeErrors(w, http.StatusBadRequest, "INVALID_API_INPUT",
+				fmt.Sprintf("Action requires between 1 and %d inputs", force.MaxActionInputs))
+			return
+		}
+
+		status := http.StatusOK
+		results := make([]*force.ActionResult, 0, len(body.Inputs))
+		for _, input := range body.Inputs {
+			result := invoke(a, input)
+			if !result.IsSuccess {
+				status = http.StatusBadRequest
+			}
+			results = append(results, result)
+		}
+		writeJSON(w, status, results)
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+	}
+}
+
+// invoke validates an input against the action's describe and calls its ActionFunc.
+func invoke(a *fakeAction, input force.SObject) *force.ActionResult {
+	fail := func(code, message string, fields ...string) *force.ActionResult {
+		return &force.ActionResult{ActionName: a.describe.Name, Version: 1,
+			Errors: []*force.SObjectError{{StatusCode: code, Message: message, Fields: fields}}}
+	}
+
+	for name := range input {
+		if a.describe.FindInput(name) == nil {
+			return fail("INVALID_API_INPUT", fmt.Sprintf("Invalid parameter name: %s", name), name)
+		}
+	}
+	for _, p := range a.describe.Inputs {
+		if v, ok := input[p.Name]; p.Required && (!ok || v == nil || v == "") {
+			return fail("REQUIRED_FIELD_MISSING", fmt.Sprintf("Missing required input parameter: %s", p.Name), p.Name)
+		}
+	}
+
+	outputs := map[string]interface{}{}
+	if a.fn != nil {
+		out, err := a.fn(input)
+		if err != nil {
+			return fail("UNKNOWN_EXCEPTION", err.Error())
+		}
+		if out != nil {
+			outputs = out
+		}
+	}
+	return &force.ActionResult{ActionName: a.describe.Name, IsSuccess: true, OutputValues: outputs, Version: 1}
+}
@@ -0,0 +1,308 @@
+package forcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ApexTest is an Apex test method the fake server reports when its class is run.
+type ApexTest struct {
+	ClassName  string
+	MethodName string
+
+	// Outcome is one of the force.ApexTestOutcomes. A CompileFail outcome fails the whole class.
+	Outcome    string
+	Message    string
+	StackTrace string
+
+	// RunTime is the run time of the method in milliseconds.
+	RunTime int
+}
+
+// ApexCoverage is the code coverage the fake server reports for an Apex class.
+type ApexCoverage struct {
+	ClassName      string
+	CoveredLines   []int
+	UncoveredLines []int
+}
+
+// AddApexTests registers Apex test methods. The ApexClass, ApexTestQueueItem, ApexTestResult and
+// ApexCodeCoverageAggregate Tooling objects are registered automatically.
+func (s *Server) AddApexTests(tests ...*ApexTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureApexObjects()
+	for _, t := range tests {
+		s.apexClassID(t.ClassName)
+		s.apexTests = append(s.apexTests, t)
+	}
+}
+
+// SetApexCoverage sets the code coverage of Apex classes, stored as ApexCodeCoverageAggregate records.
+func (s *Server) SetApexCoverage(coverage ...*ApexCoverage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureApexObjects()
+	for _, c := range coverage {
+		classID := s.apexClassID(c.ClassName)
+		meta := s.sobjects["apexcodecoverageaggregate"]
+		for id, r := range s.records["apexcodecoverageaggregate"] {
+			if r["ApexClassOrTriggerId"] == classID {
+				delete(s.records["apexcodecoverageaggregate"], id)
+			}
+		}
+		s.insert(meta, force.SObject{
+			"ApexClassOrTriggerId": classID,
+			"NumLinesCovered":      len(c.CoveredLines),
+			"NumLinesUncovered":    len(c.UncoveredLines),
+			"Coverage":             map[string]interface{}{"coveredLines": c.CoveredLines, "uncoveredLines": c.UncoveredLines},
+		})
+		replaced := false
+		for i, existing := range s.apexCoverage {
+			if strings.EqualFold(existing.ClassName, c.ClassName) {
+				s.apexCoverage[i] = c
+				replaced = true
+			}
+		}
+		if !replaced {
+			s.apexCoverage = append(s.apexCoverage, c)
+		}
+	}
+}
+
+// ensureApexObjects registers the Tooling objects used by Apex test runs if they aren't already. The caller must
+// hold s.mu.
+func (s *Server) ensureApexObjects() {
+	objects := []*force.SObjectMetadata{
+		NewSObjectMetadata("ApexClass", "01p",
+			NewField("Name", force.FieldDataTypes.String),
+			NewField("Body", force.FieldDataTypes.Textarea),
+			NewField("Status", force.FieldDataTypes.Picklist),
+		),
+		NewSObjectMetadata("ApexTestQueueItem", "709",
+			NewReferenceField("ApexClassId", "ApexClass", "ApexClass"),
+			NewField("ParentJobId", force.FieldDataTypes.Reference),
+			NewField("Status", force.FieldDataTypes.Picklist),
+			NewField("ExtendedStatus", force.FieldDataTypes.String),
+		),
+		NewSObjectMetadata("ApexTestResult", "07M",
+			NewReferenceField("ApexClassId", "ApexClass", "ApexClass"),
+			NewField("AsyncApexJobId", force.FieldDataTypes.Reference),
+			NewField("QueueItemId", force.FieldDataTypes.Reference),
+			NewField("MethodName", force.FieldDataTypes.String),
+			NewField("Outcome", force.FieldDataTypes.Picklist),
+			NewField("Message", force.FieldDataTypes.String),
+			NewField("StackTrace", force.FieldDataTypes.String),
+			NewField("RunTime", force.FieldDataTypes.Int),
+		),
+		NewSObjectMetadata("ApexCodeCoverageAggregate", "715",
+			NewReferenceField("ApexClassOrTriggerId", "ApexClassOrTrigger", "ApexClass"),
+			NewField("NumLinesCovered", force.FieldDataTypes.Int),
+			NewField("NumLinesUncovered", force.FieldDataTypes.Int),
+			NewField("Coverage", force.FieldDataTypes.AnyType),
+		),
+	}
+	for _, meta := range objects {
+		key := strings.ToLower(meta.GetName())
+		if _, ok := s.sobjects[key]; ok {
+			continue
+		}
+		s.sobjects[key] = meta
+		s.records[key] = map[string]force.SObject{}
+	}
+}
+
+// apexClassID returns the ID of the named ApexClass, inserting it if needed. The caller must hold s.mu.
+func (s *Server) apexClassID(name string) string {
+	for id, r := range s.records["apexclass"] {
+		if strings.EqualFold(fmt.Sprint(r["Name"]), name) {
+			return id
+		}
+	}
+	return s.insert(s.sobjects["apexclass"], force.SObject{"Name": name, "Status": "Active"})
+}
+
+// selectApexTests returns the registered tests selected by a run request grouped by class, in class order.
+// The caller must hold s.mu.
+func (s *Server) selectApexTests(req *force.RunTestsRequest) ([]string, map[string][]*ApexTest) {
+	all := req.TestLevel == force.TestLevels.RunLocalTests || req.TestLevel == force.TestLevels.RunAllTestsInOrg
+	classes := map[string][]string{}
+	if req.ClassIDs != "" {
+		for _, id := range strings.Split(req.ClassIDs, ",") {
+			if r, ok := s.records["apexclass"][strings.TrimSpace(id)]; ok {
+				classes[strings.ToLower(fmt.Sprint(r["Name"]))] = nil
+			}
+		}
+	}
+	for _, item := range req.Tests {
+		name := item.ClassName
+		if r, ok := s.records["apexclass"][item.ClassID]; ok {
+			name = fmt.Sprint(r["Name"])
+		}
+		classes[strings.ToLower(name)] = item.TestMethods
+	}
+
+	order := make([]string, 0)
+	selected := map[string][]*ApexTest{}
+	for _, t := range s.apexTests {
+		methods, ok := classes[strings.ToLower(t.ClassName)]
+		if !all && !ok {
+			continue
+		}
+		if len(methods) > 0 && !containsFold(methods, t.MethodName) {
+			continue
+		}
+		if _, seen := selected[t.ClassName]; !seen {
+			order = append(order, t.ClassName)
+		}
+		selected[t.ClassName] = append(selected[t.ClassName], t)
+	}
+	return order, selected
+}
+
+// handleRunTestsAsynchronous queues the selected tests. Every class completes immediately, storing an
+// ApexTestQueueItem and its ApexTestResults under the returned job ID.
+func (s *Server) handleRunTestsAsynchronous(w http.ResponseWriter, r *http.Request) {
+	var body force.RunTestsRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+		return
+	}
+	if body.ClassIDs == "" && body.SuiteIDs == "" && len(body.Tests) == 0 && body.TestLevel == "" {
+		writeErrors(w, http.StatusBadRequest, "INVALID_INPUT", "No test classes, suites or test level specified")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureApexObjects()
+
+	s.sequence++
+	jobID := fmt.Sprintf("707%012s", strconv.FormatInt(int64(s.sequence), 36))
+	jobID += idSuffix(jobID)
+
+	classes, selected := s.selectApexTests(&body)
+	for _, class := range classes {
+		classID := s.apexClassID(class)
+		item := force.SObject{"ApexClassId": classID, "ParentJobId": jobID, "Status": "Completed"}
+		if failure := compileFailure(selected[class]); failure != nil {
+			item["Status"] = "Failed"
+			item["ExtendedStatus"] = failure.Message
+			s.insert(s.sobjects["apextestqueueitem"], item)
+			continue
+		}
+
+		itemID := s.insert(s.sobjects["apextestqueueitem"], item)
+		for _, t := range selected[class] {
+			s.insert(s.sobjects["apextestresult"], force.SObject{
+				"ApexClassId":    classID,
+				"AsyncApexJobId": jobID,
+				"QueueItemId":    itemID,
+				"MethodName":     t.MethodName,
+				"Outcome":        outcome(t),
+				"Message":        nullable(t.Message),
+				"StackTrace":     nullable(t.StackTrace),
+				"RunTime":        t.RunTime,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, jobID)
+}
+
+// handleRunTestsSynchronous runs the selected tests and reports them along with the configured coverage.
+func (s *Server) handleRunTestsSynchronous(w http.ResponseWriter, r *http.Request) {
+	var body force.RunTestsRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureApexObjects()
+
+	classes, selected := s.selectApexTests(&body)
+	if len(classes) > 1 {
+		writeErrors(w, http.StatusBadRequest, "INVALID_INPUT", "Only one test class can be run synchronously")
+		return
+	}
+
+	result := &force.RunTestsSynchronousResult{
+		Successes:            []*force.RunTestSuccess{},
+		Failures:             []*force.RunTestFailure{},
+		CodeCoverage:         []*force.RunTestCodeCoverage{},
+		CodeCoverageWarnings: []*force.RunTestCoverageWarning{},
+	}
+	for _, class := range classes {
+		classID := s.apexClassID(class)
+		if failure := compileFailure(selected[class]); failure != nil {
+			writeErrors(w, http.StatusBadRequest, "COMPILE_ERROR", failure.Message)
+			return
+		}
+		for _, t := range selected[class] {
+			result.NumTestsRun++
+			result.TotalTime += float64(t.RunTime)
+			if outcome(t) == force.ApexTestOutcomes.Pass {
+				result.Successes = append(result.Successes, &force.RunTestSuccess{ID: classID, Name: t.ClassName,
+					MethodName: t.MethodName, Time: float64(t.RunTime)})
+				continue
+			}
+			result.NumFailures++
+			result.Failures = append(result.Failures, &force.RunTestFailure{ID: classID, Name: t.ClassName,
+				MethodName: t.MethodName, Message: t.Message, StackTrace: t.StackTrace, Type: "Class",
+				Time: float64(t.RunTime)})
+		}
+	}
+
+	if !body.GetSkipCodeCoverage() {
+		for _, c := range s.apexCoverage {
+			cc := &force.RunTestCodeCoverage{ID: s.apexClassID(c.ClassName), Name: c.ClassName, Type: "Class",
+				NumLocations: len(c.CoveredLines) + len(c.UncoveredLines), NumLocationsNotCovered: len(c.UncoveredLines),
+				LocationsNotCovered: []*force.RunTestCodeLocation{}}
+			for _, line := range c.UncoveredLines {
+				cc.LocationsNotCovered = append(cc.LocationsNotCovered, &force.RunTestCodeLocation{Line: line, Time: -1})
+			}
+			result.CodeCoverage = append(result.CodeCoverage, cc)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// compileFailure returns the first test of a class with a CompileFail outcome.
+func compileFailure(tests []*ApexTest) *ApexTest {
+	for _, t := range tests {
+		if t.Outcome == force.ApexTestOutcomes.CompileFail {
+			return t
+		}
+	}
+	return nil
+}
+
+// outcome returns the test's outcome, defaulting to Pass.
+func outcome(t *ApexTest) string {
+	if t.Outcome == "" {
+		return force.ApexTestOutcomes.Pass
+	}
+	return t.Outcome
+}
+
+// nullable returns nil for empty strings so they are stored as JSON null like Salesforce does.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package forcetest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HandleApexREST registers a handler for a custom Apex REST endpoint. The URL mapping is relative to
+// /services/apexrest and, like @RestResource, may end with a `/*` wildcard, such as `/invoices/*`.
+func (s *Server) HandleApexREST(urlMapping string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apexREST["/"+strings.Trim(urlMapping, "/")] = handler
+}
+
+// handleApexREST dispatches a request to the handler with the most specific matching URL mapping.
+func (s *Server) handleApexREST(w http.ResponseWriter, r *http.Request, path string) {
+	s.mu.Lock()
+	var handler http.HandlerFunc
+	matched := ""
+	for mapping, h := range s.apexREST {
+		prefix := strings.TrimSuffix(mapping, "*")
+		ok := mapping == path || (prefix != mapping && strings.HasPrefix(path+"/", prefix))
+		if ok && len(mapping) > len(matched) {
+			handler, matched = h, mapping
+		}
+	}
+	s.mu.Unlock()
+
+	if handler == nil {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "Could not find a match for URL "+path)
+		return
+	}
+	handler(w, r)
+}
@@ -0,0 +1,400 @@
+package forcetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+)
+
+// referencePattern matches composite subrequest references such as '@{NewAccount.id}'.
+var referencePattern = regexp.MustCompile(`@\{([A-Za-z0-9_]+)\.([A-Za-z0-9_.\[\]]+)\}`)
+
+// collectionRequest is the body of a sObject collection create, update or upsert request.
+type collectionRequest struct {
+	AllOrNone bool            `json:"allOrNone"`
+	Records   []force.SObject `json:"records"`
+	IDs       []string        `json:"ids"`
+	Fields    []string        `json:"fields"`
+}
+
+// collectionResult is the per-record result of a sObject collection request.
+type collectionResult struct {
+	ID       string                   `json:"id,omitempty"`
+	Success  bool                     `json:"success"`
+	Created  *bool                    `json:"created,omitempty"`
+	ReplayID *int64                   `json:"replayId,omitempty"`
+	Errors   []map[string]interface{} `json:"errors"`
+}
+
+// compositeRequest is the body of a composite request.
+type compositeRequest struct {
+	AllOrNone        bool                   `json:"allOrNone"`
+	CompositeRequest []*compositeSubrequest `json:"compositeRequest"`
+}
+
+type compositeSubrequest struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	ReferenceID string            `json:"referenceId"`
+	Body        interface{}       `json:"body,omitempty"`
+	HTTPHeaders map[string]string `json:"httpHeaders,omitempty"`
+}
+
+type compositeSubresponse struct {
+	Body           interface{}       `json:"body"`
+	HTTPHeaders    map[string]string `json:"httpHeaders"`
+	HTTPStatusCode int               `json:"httpStatusCode"`
+	ReferenceID    string            `json:"referenceId"`
+}
+
+func (s *Server) handleComposite(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodPost {
+			writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+			return
+		}
+		s.handleCompositeRequest(w, r)
+		return
+	}
+
+	if segments[0] != "sobjects" {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	var body collectionRequest
+	if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		s.collectionWrite(w, body, func(record force.SObject) *collectionResult {
+			meta, errResult := s.collectionSObject(record)
+			if errResult != nil {
+				return errResult
+			}
+			if status, code, msg := s.validateFields(meta, withoutAttributes(record)); status != 0 {
+				return failure(code, msg)
+			}
+			if isPlatformEvent(meta) {
+				id, replayID := s.publishEvent(meta, withoutAttributes(record))
+				return &collectionResult{ID: id, Success: true, ReplayID: &replayID}
+			}
+			return &collectionResult{ID: s.insert(meta, record), Success: true}
+		})
+	case len(segments) == 1 && r.Method == http.MethodPatch:
+		s.collectionWrite(w, body, func(record force.SObject) *collectionResult {
+			meta, errResult := s.collectionSObject(record)
+			if errResult != nil {
+				return errResult
+			}
+			id, _ := fieldValue(record, "Id").(string)
+			existing, ok := s.records[strings.ToLower(meta.GetName())][id]
+			if !ok {
+				return failure("ENTITY_IS_DELETED", "entity is deleted")
+			}
+			if status, code, msg := s.validateFields(meta, withoutAttributes(record)); status != 0 {
+				return failure(code, msg)
+			}
+			s.update(meta, existing, record)
+			return &collectionResult{ID: id, Success: true}
+		})
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		body.AllOrNone = r.URL.Query().Get("allOrNone") == "true"
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			body.Records = append(body.Records, force.SObject{"Id": id})
+		}
+		s.collectionWrite(w, body, func(record force.SObject) *collectionResult {
+			id := record["Id"].(string)
+			meta, existing := s.find(id)
+			if existing == nil {
+				return failure("ENTITY_IS_DELETED", "entity is deleted")
+			}
+			s.remove(meta, id)
+			return &collectionResult{ID: id, Success: true}
+		})
+	case len(segments) == 2 && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+		s.collectionRetrieve(w, r, segments[1], body)
+	case len(segments) == 3 && r.Method == http.MethodPatch:
+		s.collectionUpsert(w, segments[1], segments[2], body)
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+// collectionWrite applies fn to every record, rolling back all changes when allOrNone is set and any
+// record fails. The caller must hold s.mu.
+func (s *Server) collectionWrite(w http.ResponseWriter, body collectionRequest, fn func(force.SObject) *collectionResult) {
+	if len(body.Records) > 200 {
+		writeErrors(w, http.StatusBadRequest, "EXCEEDED_ID_LIMIT", "record limit reached. cannot submit more than 200 records into this call")
+		return
+	}
+
+	snapshot := s.snapshot()
+	results := make([]*collectionResult, 0, len(body.Records))
+	failed := false
+	for _, record := range body.Records {
+		result := fn(record)
+		failed = failed || !result.Success
+		results = append(results, result)
+	}
+
+	if failed && body.AllOrNone {
+		s.restore(snapshot)
+		for i, result := range results {
+			if result.Success {
+				results[i] = failure("ALL_OR_NONE_OPERATION_ROLLED_BACK", "Record rolled back because not all records were valid and the request was using AllOrNone header")
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// collectionSObject returns the metadata named by a record's 'attributes.type'. The caller must hold s.mu.
+func (s *Server) collectionSObject(record force.SObject) (*force.SObjectMetadata, *collectionResult) {
+	attrs, _ := record["attributes"].(map[string]interface{})
+	objectName, _ := attrs["type"].(string)
+	if objectName == "" {
+		return nil, failure("INVALID_INPUT", "Each record must include attributes with a type")
+	}
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		return nil, failure("INVALID_TYPE", fmt.Sprintf("sObject type '%s' is not supported.", objectName))
+	}
+	return meta, nil
+}
+
+// collectionRetrieve returns multiple records of the same object. The caller must hold s.mu.
+func (s *Server) collectionRetrieve(w http.ResponseWriter, r *http.Request, objectName string, body collectionRequest) {
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	ids, fields := body.IDs, body.Fields
+	if r.Method == http.MethodGet {
+		ids = strings.Split(r.URL.Query().Get("ids"), ",")
+		fields = strings.Split(r.URL.Query().Get("fields"), ",")
+	}
+
+	results := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		record, ok := s.records[strings.ToLower(meta.GetName())][id]
+		if !ok {
+			results = append(results, nil)
+			continue
+		}
+		results = append(results, s.project(meta, record, fields))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// collectionUpsert creates or updates records matched on an external ID field. The caller must hold s.mu.
+func (s *Server) collectionUpsert(w http.ResponseWriter, objectName, externalIDField string, body collectionRequest) {
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	key := strings.ToLower(meta.GetName())
+	s.collectionWrite(w, body, func(record force.SObject) *collectionResult {
+		fields := withoutAttributes(record)
+		if status, code, msg := s.validateFields(meta, fields); status != 0 {
+			return failure(code, msg)
+		}
+
+		value := fieldValue(fields, externalIDField)
+		if value == nil {
+			return failure("MISSING_ARGUMENT", fmt.Sprintf("%s not specified", externalIDField))
+		}
+		for _, id := range s.order[key] {
+			existing, ok := s.records[key][id]
+			if ok && compare(fieldValue(existing, externalIDField), value) == 0 {
+				s.update(meta, existing, fields)
+				return &collectionResult{ID: id, Success: true, Created: force.Bool(false)}
+			}
+		}
+		return &collectionResult{ID: s.insert(meta, fields), Success: true, Created: force.Bool(true)}
+	})
+}
+
+func (s *Server) handleCompositeRequest(w http.ResponseWriter, r *http.Request) {
+	var body compositeRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+		return
+	}
+	if len(body.CompositeRequest) > 25 {
+		writeErrors(w, http.StatusBadRequest, "LIMIT_EXCEEDED", "Composite request can't have more than 25 subrequests")
+		return
+	}
+
+	s.mu.Lock()
+	snapshot := s.snapshot()
+	s.mu.Unlock()
+
+	responses := make([]*compositeSubresponse, 0, len(body.CompositeRequest))
+	bodies := map[string]interface{}{}
+	failed := false
+	for _, sub := range body.CompositeRequest {
+		if failed && body.AllOrNone {
+			responses = append(responses, haltedResponse(sub.ReferenceID))
+			continue
+		}
+
+		resp, err := s.dispatch(r, sub, bodies)
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, "INVALID_REFERENCE", err.Error())
+			return
+		}
+		if resp.HTTPStatusCode >= 400 {
+			failed = true
+		}
+		bodies[sub.ReferenceID] = resp.Body
+		responses = append(responses, resp)
+	}
+
+	if failed && body.AllOrNone {
+		s.mu.Lock()
+		s.restore(snapshot)
+		s.mu.Unlock()
+		for i, resp := range responses {
+			if resp.HTTPStatusCode < 400 {
+				responses[i] = haltedResponse(resp.ReferenceID)
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"compositeResponse": responses})
+}
+
+// dispatch executes a composite subrequest against the server, resolving references to earlier responses.
+func (s *Server) dispatch(parent *http.Request, sub *compositeSubrequest, bodies map[string]interface{}) (*compositeSubresponse, error) {
+	url, err := resolveReferences(sub.URL, bodies)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	if sub.Body != nil {
+		raw, marshalErr := json.Marshal(sub.Body)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		resolved, resolveErr := resolveReferences(string(raw), bodies)
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		payload = []byte(resolved)
+	}
+
+	req := httptest.NewRequest(sub.Method, url, bytes.NewReader(payload))
+	req.Header.Set("Authorization", parent.Header.Get("Authorization"))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range sub.HTTPHeaders {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	s.serveHTTP(rec, req)
+
+	resp := &compositeSubresponse{HTTPHeaders: map[string]string{}, HTTPStatusCode: rec.Code, ReferenceID: sub.ReferenceID}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// resolveReferences replaces '@{referenceId.path}' expressions with values from earlier subresponse bodies.
+func resolveReferences(s string, bodies map[string]interface{}) (string, error) {
+	var resolveErr error
+	out := referencePattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := referencePattern.FindStringSubmatch(m)
+		body, ok := bodies[parts[1]]
+		if !ok {
+			resolveErr = fmt.Errorf("invalid reference specified. No value for %s found", m)
+			return m
+		}
+
+		value := body
+		for _, key := range strings.Split(parts[2], ".") {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				resolveErr = fmt.Errorf("invalid reference specified. No value for %s found", m)
+				return m
+			}
+			value = obj[key]
+		}
+		return fmt.Sprint(value)
+	})
+	return out, resolveErr
+}
+
+func haltedResponse(referenceID string) *compositeSubresponse {
+	return &compositeSubresponse{
+		Body: []map[string]interface{}{{
+			"errorCode": "PROCESSING_HALTED",
+			"message":   "The transaction was rolled back since another operation in the same transaction failed.",
+		}},
+		HTTPHeaders:    map[string]string{},
+		HTTPStatusCode: http.StatusBadRequest,
+		ReferenceID:    referenceID,
+	}
+}
+
+func failure(code, message string) *collectionResult {
+	return &collectionResult{
+		Success: false,
+		Errors:  []map[string]interface{}{{"statusCode": code, "message": message, "fields": []string{}}},
+	}
+}
+
+func withoutAttributes(record force.SObject) force.SObject {
+	out := copyRecord(record)
+	delete(out, "attributes")
+	return out
+}
+
+// storeSnapshot is a copy of the record store used to roll back allOrNone requests.
+type storeSnapshot struct {
+	records map[string]map[string]force.SObject
+	order   map[string][]string
+}
+
+// snapshot copies the record store. The caller must hold s.mu.
+func (s *Server) snapshot() *storeSnapshot {
+	snap := &storeSnapshot{records: map[string]map[string]force.SObject{}, order: map[string][]string{}}
+	for key, records := range s.records {
+		snap.records[key] = map[string]force.SObject{}
+		for id, r := range records {
+			snap.records[key][id] = copyRecord(r)
+		}
+	}
+	for key, ids := range s.order {
+		snap.order[key] = append([]string{}, ids...)
+	}
+	return snap
+}
+
+// restore replaces the record store with a snapshot. The caller must hold s.mu.
+func (s *Server) restore(snap *storeSnapshot) {
+	s.records = snap.records
+	s.order = snap.order
+}
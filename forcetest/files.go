@@ -0,0 +1,100 @@
+package forcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// isMultipart reports whether a request has a multipart body.
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return strings.HasPrefix(mediaType, "multipart/")
+}
+
+// createWithBlob inserts a record from a multipart request holding its JSON fields and the content of a blob
+// field. Like Salesforce, the blob field of the stored record holds the URL its content is downloaded from.
+// The caller must hold s.mu.
+func (s *Server) createWithBlob(w http.ResponseWriter, r *http.Request, meta *force.SObjectMetadata) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, "INVALID_MULTIPART_REQUEST", err.Error())
+		return
+	}
+
+	var fields force.SObject
+	var blobField string
+	var blob []byte
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+
+		switch {
+		case fields == nil && strings.HasPrefix(part.FormName(), "entity_"):
+			if err := json.NewDecoder(part).Decode(&fields); err != nil {
+				writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+				return
+			}
+		case blobField == "":
+			blobField = s.fieldName(meta, part.FormName())
+			if blob, err = ioutil.ReadAll(part); err != nil {
+				writeErrors(w, http.StatusBadRequest, "INVALID_MULTIPART_REQUEST", err.Error())
+				return
+			}
+		}
+	}
+	if fields == nil || blobField == "" {
+		writeErrors(w, http.StatusBadRequest, "INVALID_MULTIPART_REQUEST",
+			"Multipart message must include a JSON entity part and a binary part")
+		return
+	}
+	if status, code, msg := s.validateFields(meta, fields); status != 0 {
+		writeErrors(w, status, code, msg)
+		return
+	}
+
+	switch strings.ToLower(meta.GetName()) {
+	case "contentversion":
+		if id, _ := fields["ContentDocumentId"].(string); id == "" {
+			fields["ContentDocumentId"] = s.newID("069")
+		}
+		fields["ContentSize"] = len(blob)
+	case "attachment":
+		fields["BodyLength"] = len(blob)
+	}
+
+	id := s.insert(meta, fields)
+	version := strings.Split(strings.TrimPrefix(r.URL.Path, "/services/data/"), "/")[0]
+	s.records[strings.ToLower(meta.GetName())][id][blobField] = fmt.Sprintf("/services/data/%s/sobjects/%s/%s/%s",
+		version, meta.GetName(), id, blobField)
+	s.blobs[id+"/"+strings.ToLower(blobField)] = blob
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "success": true, "errors": []interface{}{}})
+}
+
+// handleBlob serves the content of a blob field. The caller must hold s.mu.
+func (s *Server) handleBlob(w http.ResponseWriter, r *http.Request, meta *force.SObjectMetadata, id, field string) {
+	blob, ok := s.blobs[id+"/"+strings.ToLower(field)]
+	if _, exists := s.records[strings.ToLower(meta.GetName())][id]; !ok || !exists || r.Method != http.MethodGet {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octetstream")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(blob)
+}
+
+// Blob returns the content of a blob field of a stored record, such as a ContentVersion's `VersionData`.
+func (s *Server) Blob(id, field string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blob, ok := s.blobs[id+"/"+strings.ToLower(field)]
+	return blob, ok
+}
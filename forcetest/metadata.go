@@ -0,0 +1,723 @@
+package forcetest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/metadata"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// metadataFolder describes the components stored in a folder of a source zip.
+type metadataFolder struct {
+	Type      string
+	Suffix    string
+	KeyPrefix string
+
+	// XML is true if the component file itself is XML rather than source such as Apex.
+	XML bool
+}
+
+// metadataFolders maps the folders of a source zip to the components they hold.
+var metadataFolders = map[string]metadataFolder{
+	"applications":    {Type: "CustomApplication", Suffix: ".app", KeyPrefix: "02u", XML: true},
+	"classes":         {Type: "ApexClass", Suffix: ".cls", KeyPrefix: "01p"},
+	"components":      {Type: "ApexComponent", Suffix: ".component", KeyPrefix: "099"},
+	"flows":           {Type: "Flow", Suffix: ".flow", KeyPrefix: "301", XML: true},
+	"labels":          {Type: "CustomLabels", Suffix: ".labels", XML: true},
+	"layouts":         {Type: "Layout", Suffix: ".layout", KeyPrefix: "00h", XML: true},
+	"objects":         {Type: "CustomObject", Suffix: ".object", KeyPrefix: "01I", XML: true},
+	"pages":           {Type: "ApexPage", Suffix: ".page", KeyPrefix: "066"},
+	"permissionsets":  {Type: "PermissionSet", Suffix: ".permissionset", KeyPrefix: "0PS", XML: true},
+	"profiles":        {Type: "Profile", Suffix: ".profile", KeyPrefix: "00e", XML: true},
+	"staticresources": {Type: "StaticResource", Suffix: ".resource", KeyPrefix: "081"},
+	"tabs":            {Type: "CustomTab", Suffix: ".tab", KeyPrefix: "01r", XML: true},
+	"triggers":        {Type: "ApexTrigger", Suffix: ".trigger", KeyPrefix: "01q"},
+}
+
+// metadataComponent is a component stored by the fake Metadata API.
+type metadataComponent struct {
+	ID       string
+	Type     string
+	FullName string
+	FileName string
+	Content  []byte
+
+	// Meta is the content of the accompanying -meta.xml file, if any.
+	Meta []byte
+
+	Created      time.Time
+	LastModified time.Time
+}
+
+// metadataJob is an asynchronous deploy or retrieve.
+type metadataJob struct {
+	// pending is the number of status checks that report the job in progress before it's done.
+	pending int
+
+	deploy   *metadata.DeployResult
+	retrieve *metadata.RetrieveResult
+}
+
+// AddMetadata stores a component as if it had been deployed. The file name is the component's path in a source
+// zip, such as `classes/Greeter.cls` or `objects/Invoice__c.object`. A `-meta.xml` file is attached to its
+// component.
+func (s *Server) AddMetadata(fileName string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.metadataComponent(fileName)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(fileName, "-meta.xml") {
+		c.Meta = content
+	} else {
+		c.Content = content
+	}
+	s.saveMetadata(c)
+	return nil
+}
+
+// Metadata returns the content of a stored component.
+func (s *Server) Metadata(metadataType, fullName string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.metadata[metadataKey(metadataType, fullName)]
+	if !ok {
+		return nil, false
+	}
+	return c.Content, true
+}
+
+func metadataKey(metadataType, fullName string) string {
+	return strings.ToLower(metadataType + "/" + fullName)
+}
+
+// metadataComponent returns the component of a file, which is not yet stored. The caller must hold s.mu.
+func (s *Server) metadataComponent(fileName string) (*metadataComponent, error) {
+	folder, base := path.Split(fileName)
+	mf, ok := metadataFolders[strings.TrimSuffix(folder, "/")]
+	if !ok {
+		return nil, fmt.Errorf("unknown metadata folder of %s", fileName)
+	}
+	fullName := strings.TrimSuffix(strings.TrimSuffix(base, "-meta.xml"), mf.Suffix)
+
+	c := &metadataComponent{Type: mf.Type, FullName: fullName, FileName: path.Join(folder, fullName+mf.Suffix)}
+	if existing, ok := s.metadata[metadataKey(mf.Type, fullName)]; ok {
+		copied := *existing
+		c = &copied
+	}
+	return c, nil
+}
+
+// saveMetadata stores a component, assigning its ID and timestamps. The caller must hold s.mu.
+func (s *Server) saveMetadata(c *metadataComponent) {
+	key := metadataKey(c.Type, c.FullName)
+	now := time.Now().UTC()
+	if c.ID == "" {
+		c.ID = s.newID(metadataFolders[path.Dir(c.FileName)].KeyPrefix)
+		c.Created = now
+		s.metadataOrder = append(s.metadataOrder, key)
+	}
+	c.LastModified = now
+	s.metadata[key] = c
+}
+
+// components returns the stored components of a metadata type in file name order. The caller must hold s.mu.
+func (s *Server) components(metadataType string) []*metadataComponent {
+	components := make([]*metadataComponent, 0)
+	for _, key := range s.metadataOrder {
+		if c := s.metadata[key]; strings.EqualFold(c.Type, metadataType) {
+			components = append(components, c)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].FileName < components[j].FileName })
+	return components
+}
+
+func (c *metadataComponent) fileProperties() *metadata.FileProperties {
+	return &metadata.FileProperties{
+		CreatedByID:        UserID,
+		CreatedByName:      "Fake User",
+		CreatedDate:        c.Created,
+		FileName:           c.FileName,
+		FullName:           c.FullName,
+		ID:                 c.ID,
+		LastModifiedByID:   UserID,
+		LastModifiedByName: "Fake User",
+		LastModifiedDate:   c.LastModified,
+		ManageableState:    "unmanaged",
+		Type:               c.Type,
+	}
+}
+
+type soapRequest struct {
+	Header struct {
+		SessionID string `xml:"SessionHeader>sessionId"`
+	} `xml:"Header"`
+	Body struct {
+		Operation struct {
+			XMLName xml.Name
+			Inner   []byte `xml:",innerxml"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+type soapEnvelope struct {
+	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+	Body    soapBody `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+}
+
+type soapBody struct {
+	Content interface{}
+}
+
+type soapResponse struct {
+	XMLName xml.Name
+	Result  interface{} `xml:"result"`
+}
+
+type soapFault struct {
+	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault"`
+	Code    string   `xml:"faultcode"`
+	Message string   `xml:"faultstring"`
+}
+
+type readMetadataResult struct {
+	Records []*readMetadataRecord `xml:"records"`
+}
+
+type readMetadataRecord struct {
+	XSI   string `xml:"xmlns:xsi,attr"`
+	Type  string `xml:"xsi:type,attr"`
+	Inner []byte `xml:",innerxml"`
+}
+
+func writeSOAP(w http.ResponseWriter, status int, content interface{}) {
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(&soapEnvelope{Body: soapBody{Content: content}})
+}
+
+func writeSOAPResult(w http.ResponseWriter, operation string, result interface{}) {
+	writeSOAP(w, http.StatusOK, &soapResponse{
+		XMLName: xml.Name{Space: metadata.Namespace, Local: operation + "Response"},
+		Result:  result,
+	})
+}
+
+func writeFault(w http.ResponseWriter, code, message string) {
+	writeSOAP(w, http.StatusInternalServerError, &soapFault{Code: code, Message: message})
+}
+
+// unmarshalSOAP decodes the children of a SOAP operation into v.
+func unmarshalSOAP(inner []byte, v interface{}) error {
+	return xml.Unmarshal(append(append([]byte("<inner>"), inner...), "</inner>"...), v)
+}
+
+// handleMetadata emulates the SOAP Metadata API. Deploys and retrieves complete immediately but report being in
+// progress on their first status check.
+func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	var req soapRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFault(w, "soapenv:Client", fmt.Sprintf("Unable to parse request: %v", err))
+		return
+	}
+	if req.Header.SessionID != s.AccessToken() {
+		writeFault(w, "sf:INVALID_SESSION_ID", "INVALID_SESSION_ID: Invalid Session ID found in SessionHeader: Illegal Session")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiRequests++
+
+	op := req.Body.Operation
+	switch op.XMLName.Local {
+	case "deploy":
+		var body struct {
+			ZipFile       string                 `xml:"ZipFile"`
+			DeployOptions metadata.DeployOptions `xml:"DeployOptions"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		result := s.deployMetadata(body.ZipFile, &body.DeployOptions)
+		s.metadataJobs[result.ID] = &metadataJob{pending: 1, deploy: result}
+		writeSOAPResult(w, op.XMLName.Local, &metadata.AsyncResult{ID: result.ID, State: "Queued"})
+	case "checkDeployStatus":
+		var body struct {
+			AsyncProcessID string `xml:"asyncProcessId"`
+			IncludeDetails bool   `xml:"includeDetails"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		job, ok := s.metadataJobs[body.AsyncProcessID]
+		if !ok || job.deploy == nil {
+			writeFault(w, "sf:INVALID_ID_FIELD", "INVALID_ID_FIELD: invalid deploy id "+body.AsyncProcessID)
+			return
+		}
+		result := *job.deploy
+		if job.pending > 0 {
+			job.pending--
+			result.Done, result.Status, result.Success = false, metadata.DeployStatuses.InProgress, false
+			result.CompletedDate = time.Time{}
+		}
+		if !body.IncludeDetails || !result.Done {
+			result.Details = nil
+		}
+		writeSOAPResult(w, op.XMLName.Local, &result)
+	case "retrieve":
+		var body struct {
+			RetrieveRequest metadata.RetrieveRequest `xml:"retrieveRequest"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		result := s.retrieveMetadata(&body.RetrieveRequest)
+		s.metadataJobs[result.ID] = &metadataJob{pending: 1, retrieve: result}
+		writeSOAPResult(w, op.XMLName.Local, &metadata.AsyncResult{ID: result.ID, State: "Queued"})
+	case "checkRetrieveStatus":
+		var body struct {
+			AsyncProcessID string `xml:"asyncProcessId"`
+			IncludeZip     bool   `xml:"includeZip"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		job, ok := s.metadataJobs[body.AsyncProcessID]
+		if !ok || job.retrieve == nil {
+			writeFault(w, "sf:INVALID_ID_FIELD", "INVALID_ID_FIELD: invalid retrieve id "+body.AsyncProcessID)
+			return
+		}
+		result := *job.retrieve
+		if job.pending > 0 {
+			job.pending--
+			result = metadata.RetrieveResult{ID: result.ID, Status: metadata.RetrieveStatuses.InProgress}
+		}
+		if !body.IncludeZip {
+			result.ZipFile = ""
+		}
+		writeSOAPResult(w, op.XMLName.Local, &result)
+	case "listMetadata":
+		var body struct {
+			Queries []*metadata.ListMetadataQuery `xml:"queries"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		if len(body.Queries) > metadata.MaxListMetadataQueries {
+			writeFault(w, "sf:INVALID_QUERY_FILTER_OPERATOR", fmt.Sprintf(
+				"INVALID_QUERY_FILTER_OPERATOR: Too many queries, maximum is %d", metadata.MaxListMetadataQueries))
+			return
+		}
+		writeSOAPResult(w, op.XMLName.Local, s.listMetadata(body.Queries))
+	case "readMetadata":
+		var body struct {
+			Type      string   `xml:"type"`
+			FullNames []string `xml:"fullNames"`
+		}
+		if err := unmarshalSOAP(op.Inner, &body); err != nil {
+			writeFault(w, "soapenv:Client", err.Error())
+			return
+		}
+		if len(body.FullNames) > metadata.MaxReadMetadata {
+			writeFault(w, "sf:EXCEEDED_ID_LIMIT", fmt.Sprintf(
+				"EXCEEDED_ID_LIMIT: record limit reached. cannot submit more than %d records", metadata.MaxReadMetadata))
+			return
+		}
+		writeSOAPResult(w, op.XMLName.Local, s.readMetadata(body.Type, body.FullNames))
+	default:
+		writeFault(w, "soapenv:Client", fmt.Sprintf("No operation available for request %s", op.XMLName.Local))
+	}
+}
+
+// deployMetadata deploys a base64 encoded zip and returns the finished result. The caller must hold s.mu.
+func (s *Server) deployMetadata(zipFile string, opts *metadata.DeployOptions) *metadata.DeployResult {
+	now := time.Now().UTC()
+	result := &metadata.DeployResult{
+		ID:              s.newID("0Af"),
+		Done:            true,
+		Status:          metadata.DeployStatuses.Failed,
+		CheckOnly:       opts.CheckOnly,
+		IgnoreWarnings:  opts.IgnoreWarnings,
+		RollbackOnError: opts.RollbackOnError,
+		CreatedDate:     now,
+		CompletedDate:   now,
+		Details: &metadata.DeployDetails{
+			ComponentFailures:  []*metadata.DeployMessage{},
+			ComponentSuccesses: []*metadata.DeployMessage{},
+		},
+	}
+
+	files, manifest, err := readSourceZip(zipFile, opts.SinglePackage)
+	if err != nil {
+		result.ErrorStatusCode, result.ErrorMessage = "INVALID_ZIP", err.Error()
+		return result
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if !strings.HasSuffix(name, "-meta.xml") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	deployed := make([]*metadataComponent, 0)
+	found := map[string]bool{}
+	for _, name := range names {
+		c, componentErr := s.metadataComponent(name)
+		if componentErr != nil {
+			result.Details.ComponentFailures = append(result.Details.ComponentFailures, &metadata.DeployMessage{
+				FileName: name, Problem: componentErr.Error(), ProblemType: "Error"})
+			continue
+		}
+		members := manifest.Members(c.Type)
+		if !containsFold(members, "*") && !containsFold(members, c.FullName) {
+			continue
+		}
+		found[metadataKey(c.Type, c.FullName)] = true
+
+		message := &metadata.DeployMessage{ComponentType: c.Type, FileName: c.FileName, FullName: c.FullName}
+		if problem, line := validateSource(files[name], metadataFolders[path.Dir(name)].XML); problem != "" {
+			message.Problem, message.ProblemType, message.LineNumber = problem, "Error", line
+			result.Details.ComponentFailures = append(result.Details.ComponentFailures, message)
+			continue
+		}
+		if meta, ok := files[name+"-meta.xml"]; ok {
+			if problem, line := validateSource(meta, true); problem != "" {
+				message.FileName += "-meta.xml"
+				message.Problem, message.ProblemType, message.LineNumber = problem, "Error", line
+				result.Details.ComponentFailures = append(result.Details.ComponentFailures, message)
+				continue
+			}
+			c.Meta = meta
+		}
+		c.Content = files[name]
+
+		message.Success, message.ID = true, c.ID
+		message.Created, message.Changed = c.ID == "", c.ID != ""
+		result.Details.ComponentSuccesses = append(result.Details.ComponentSuccesses, message)
+		deployed = append(deployed, c)
+	}
+
+	for _, t := range manifest.Types {
+		for _, member := range t.Members {
+			if member == "*" || found[metadataKey(t.Name, member)] {
+				continue
+			}
+			result.Details.ComponentFailures = append(result.Details.ComponentFailures, &metadata.DeployMessage{
+				ComponentType: t.Name, FileName: "package.xml", FullName: member, ProblemType: "Error",
+				Problem: fmt.Sprintf("An object '%s' of type %s was named in package.xml, but was not found in zipped directory",
+					member, t.Name)})
+		}
+	}
+
+	result.Details.RunTestResult = s.deployTests(opts)
+	result.NumberComponentsTotal = len(result.Details.ComponentSuccesses) + len(result.Details.ComponentFailures)
+	result.NumberComponentErrors = len(result.Details.ComponentFailures)
+	result.NumberTestsTotal = result.Details.RunTestResult.NumTestsRun
+	result.NumberTestsCompleted = result.NumberTestsTotal - result.Details.RunTestResult.NumFailures
+	result.NumberTestErrors = result.Details.RunTestResult.NumFailures
+
+	switch {
+	case result.NumberTestErrors > 0 || (result.NumberComponentErrors > 0 && (opts.RollbackOnError || len(deployed) == 0)):
+		return result
+	case result.NumberComponentErrors > 0:
+		result.Status = metadata.DeployStatuses.SucceededPartial
+	default:
+		result.Status, result.Success = metadata.DeployStatuses.Succeeded, true
+	}
+
+	result.NumberComponentsDeployed = len(deployed)
+	if !opts.CheckOnly {
+		for i, c := range deployed {
+			s.saveMetadata(c)
+			result.Details.ComponentSuccesses[i].ID = c.ID
+		}
+	}
+	return result
+}
+
+// deployTests runs the Apex tests selected by the deploy's test level.
+func (s *Server) deployTests(opts *metadata.DeployOptions) *metadata.RunTestsResult {
+	result := &metadata.RunTestsResult{
+		Failures:             []*metadata.RunTestFailure{},
+		Successes:            []*metadata.RunTestSuccess{},
+		CodeCoverageWarnings: []*metadata.CodeCoverageWarning{},
+	}
+
+	req := &force.RunTestsRequest{}
+	switch opts.TestLevel {
+	case metadata.TestLevels.RunSpecifiedTests:
+		for _, name := range opts.RunTests {
+			req.Tests = append(req.Tests, &force.TestItem{ClassName: name})
+		}
+	case metadata.TestLevels.RunLocalTests, metadata.TestLevels.RunAllTestsInOrg:
+		req.TestLevel = force.TestLevels.RunLocalTests
+	default:
+		return result
+	}
+
+	s.ensureApexObjects()
+	classes, selected := s.selectApexTests(req)
+	for _, class := range classes {
+		classID := s.apexClassID(class)
+		for _, t := range selected[class] {
+			result.NumTestsRun++
+			result.TotalTime += float64(t.RunTime)
+			if outcome(t) == force.ApexTestOutcomes.Pass {
+				result.Successes = append(result.Successes, &metadata.RunTestSuccess{ID: classID, Name: t.ClassName,
+					MethodName: t.MethodName, Time: float64(t.RunTime)})
+				continue
+			}
+			result.NumFailures++
+			result.Failures = append(result.Failures, &metadata.RunTestFailure{ID: classID, Name: t.ClassName,
+				MethodName: t.MethodName, Message: t.Message, StackTrace: t.StackTrace, Time: float64(t.RunTime)})
+		}
+	}
+	return result
+}
+
+// readSourceZip returns the files of a base64 encoded source zip keyed by their path relative to package.xml,
+// along with the parsed package.xml.
+func readSourceZip(zipFile string, singlePackage bool) (map[string][]byte, *metadata.Package, error) {
+	b, err := base64.StdEncoding.DecodeString(zipFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := ""
+	found := false
+	for _, f := range zr.File {
+		if path.Base(f.Name) == "package.xml" && (!found || len(f.Name) < len(root)+len("package.xml")) {
+			root, found = strings.TrimSuffix(f.Name, "package.xml"), true
+		}
+	}
+	if !found || (singlePackage && root != "") {
+		return nil, nil, fmt.Errorf("No package.xml found")
+	}
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasPrefix(f.Name, root) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		files[strings.TrimPrefix(f.Name, root)] = content
+	}
+
+	manifest, err := metadata.ParsePackage(files["package.xml"])
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(files, "package.xml")
+	return files, manifest, nil
+}
+
+var xmlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// validateSource checks that an XML file is well formed, returning the problem and its line.
+func validateSource(content []byte, isXML bool) (string, int) {
+	if !isXML {
+		return "", 0
+	}
+	d := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return "", 0
+		}
+		if err != nil {
+			line := 0
+			if m := xmlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+				fmt.Sscan(m[1], &line)
+			}
+			return "Error parsing file: " + err.Error(), line
+		}
+	}
+}
+
+// retrieveMetadata retrieves the components of an unpackaged manifest. The caller must hold s.mu.
+func (s *Server) retrieveMetadata(req *metadata.RetrieveRequest) *metadata.RetrieveResult {
+	result := &metadata.RetrieveResult{
+		ID:             s.newID("09S"),
+		Done:           true,
+		Status:         metadata.RetrieveStatuses.Failed,
+		FileProperties: []*metadata.FileProperties{},
+		Messages:       []*metadata.RetrieveMessage{},
+	}
+	if req.Unpackaged == nil {
+		result.ErrorStatusCode, result.ErrorMessage = "UNSUPPORTED", "Only unpackaged retrieves are supported"
+		return result
+	}
+
+	root := "unpackaged/"
+	if req.SinglePackage {
+		root = ""
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, content []byte) {
+		f, _ := zw.Create(root + name)
+		_, _ = f.Write(content)
+	}
+
+	for _, t := range req.Unpackaged.Types {
+		components := s.components(t.Name)
+		for _, member := range t.Members {
+			matched := false
+			for _, c := range components {
+				if member != "*" && !strings.EqualFold(member, c.FullName) {
+					continue
+				}
+				matched = true
+				write(c.FileName, c.Content)
+				if c.Meta != nil {
+					write(c.FileName+"-meta.xml", c.Meta)
+				}
+				props := c.fileProperties()
+				props.FileName = root + props.FileName
+				result.FileProperties = append(result.FileProperties, props)
+			}
+			if !matched && member != "*" {
+				result.Messages = append(result.Messages, &metadata.RetrieveMessage{FileName: root + "package.xml",
+					Problem: fmt.Sprintf("Entity of type '%s' named '%s' cannot be found", t.Name, member)})
+			}
+		}
+	}
+
+	manifest := &metadata.Package{Types: req.Unpackaged.Types, Version: req.APIVersion}
+	b, _ := manifest.Bytes()
+	write("package.xml", b)
+	if err := zw.Close(); err != nil {
+		result.ErrorStatusCode, result.ErrorMessage = "UNKNOWN_EXCEPTION", err.Error()
+		return result
+	}
+
+	result.FileProperties = append(result.FileProperties, &metadata.FileProperties{FileName: root + "package.xml",
+		FullName: strings.TrimSuffix(root, "/"), Type: "Package"})
+	result.Status, result.Success = metadata.RetrieveStatuses.Succeeded, true
+	result.ZipFile = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return result
+}
+
+// objectFields are the fields of a CustomObject file.
+type objectFields struct {
+	Fields []struct {
+		FullName string `xml:"fullName"`
+		Inner    []byte `xml:",innerxml"`
+	} `xml:"fields"`
+}
+
+// listMetadata lists stored components. Fields of stored objects are listed as CustomField components. The
+// caller must hold s.mu.
+func (s *Server) listMetadata(queries []*metadata.ListMetadataQuery) []*metadata.FileProperties {
+	properties := make([]*metadata.FileProperties, 0)
+	for _, q := range queries {
+		if !strings.EqualFold(q.Type, "CustomField") {
+			for _, c := range s.components(q.Type) {
+				properties = append(properties, c.fileProperties())
+			}
+			continue
+		}
+
+		for _, object := range s.components("CustomObject") {
+			var fields objectFields
+			if xml.Unmarshal(object.Content, &fields) != nil {
+				continue
+			}
+			for _, f := range fields.Fields {
+				props := object.fileProperties()
+				props.Type, props.FullName = "CustomField", object.FullName+"."+f.FullName
+				properties = append(properties, props)
+			}
+		}
+	}
+	return properties
+}
+
+var fullNamePattern = regexp.MustCompile(`<fullName>[^<]*</fullName>`)
+
+// readMetadata reads stored components as records. A CustomField is read from the file of its object. The
+// caller must hold s.mu.
+func (s *Server) readMetadata(metadataType string, fullNames []string) *readMetadataResult {
+	result := &readMetadataResult{Records: []*readMetadataRecord{}}
+	for _, fullName := range fullNames {
+		record := &readMetadataRecord{XSI: "http://www.w3.org/2001/XMLSchema-instance", Type: metadataType}
+		result.Records = append(result.Records, record)
+
+		if strings.EqualFold(metadataType, "CustomField") {
+			objectName, fieldName := fullName, ""
+			if i := strings.Index(fullName, "."); i >= 0 {
+				objectName, fieldName = fullName[:i], fullName[i+1:]
+			}
+			object, ok := s.metadata[metadataKey("CustomObject", objectName)]
+			if !ok {
+				continue
+			}
+			var fields objectFields
+			if xml.Unmarshal(object.Content, &fields) != nil {
+				continue
+			}
+			for _, f := range fields.Fields {
+				if strings.EqualFold(f.FullName, fieldName) {
+					inner := f.Inner
+					if loc := fullNamePattern.FindIndex(inner); loc != nil {
+						inner = append(append([]byte{}, inner[:loc[0]]...), inner[loc[1]:]...)
+					}
+					record.Inner = append([]byte("<fullName>"+object.FullName+"."+f.FullName+"</fullName>"), inner...)
+				}
+			}
+			continue
+		}
+
+		c, ok := s.metadata[metadataKey(metadataType, fullName)]
+		if !ok {
+			continue
+		}
+		source := c.Meta
+		if metadataFolders[path.Dir(c.FileName)].XML {
+			source = c.Content
+		}
+		var root struct {
+			Inner []byte `xml:",innerxml"`
+		}
+		if xml.Unmarshal(source, &root) != nil {
+			continue
+		}
+		record.Inner = append([]byte("<fullName>"+c.FullName+"</fullName>"), root.Inner...)
+	}
+	return result
+}
@@ -0,0 +1,309 @@
+package forcetest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// authCode is an authorization code issued by the fake authorize endpoint.
+type authCode struct {
+	clientID            string
+	redirectURI         string
+	codeChallenge       string
+	codeChallengeMethod string
+}
+
+// handleAuthorize approves every authorization request of the web server flow, redirecting to the redirect URI
+// with an authorization code. Requests for an unknown client are redirected with an error.
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("redirect_uri") == "" {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: "redirect_uri_mismatch", Description: "redirect_uri must match configuration"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	params := redirectURI.Query()
+	if state := query.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	switch {
+	case query.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+		params.Set("error_description", "response type not supported")
+	case s.creds != nil && query.Get("client_id") != s.creds.ClientID:
+		params.Set("error", force.TokenErrorCodes.InvalidClientID.ToString())
+		params.Set("error_description", "client identifier invalid")
+	default:
+		s.sequence++
+		code := fmt.Sprintf("aPrx%012d", s.sequence)
+		s.authCodes[code] = &authCode{
+			clientID:            query.Get("client_id"),
+			redirectURI:         query.Get("redirect_uri"),
+			codeChallenge:       query.Get("code_challenge"),
+			codeChallengeMethod: query.Get("code_challenge_method"),
+		}
+		params.Set("code", code)
+	}
+
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// authorizationCodeGrant exchanges an authorization code, which can only be used once, for a token.
+func (s *Server) authorizationCodeGrant(w http.ResponseWriter, form url.Values) {
+	s.mu.Lock()
+	code, ok := s.authCodes[form.Get("code")]
+	delete(s.authCodes, form.Get("code"))
+	creds := s.creds
+	token := s.accessToken
+	s.mu.Unlock()
+
+	invalidGrant := func(description string) {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidGrant, Description: description})
+	}
+	switch {
+	case !ok:
+		invalidGrant("invalid authorization code")
+		return
+	case form.Get("client_id") != code.clientID:
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClientID, Description: "client identifier invalid"})
+		return
+	case creds != nil && form.Get("client_secret") != "" && form.Get("client_secret") != creds.ClientSecret:
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClient, Description: "invalid client credentials"})
+		return
+	case form.Get("redirect_uri") != code.redirectURI:
+		invalidGrant("redirect_uri must match configuration")
+		return
+	case code.codeChallenge != "" && !verifyCodeChallenge(code, form.Get("code_verifier")):
+		invalidGrant("invalid code verifier")
+		return
+	}
+
+	response := s.tokenResponse(token, form.Get("client_secret"))
+	response["refresh_token"] = DefaultRefreshToken
+	writeJSON(w, http.StatusOK, response)
+}
+
+// verifyCodeChallenge reports whether the PKCE verifier matches the challenge the code was issued for.
+func verifyCodeChallenge(code *authCode, verifier string) bool {
+	if code.codeChallengeMethod != force.PKCEMethodS256 {
+		return verifier == code.codeChallenge
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]) == code.codeChallenge
+}
+
+// deviceInterval is the interval, in seconds, between token requests of the device flow. Polling sooner is
+// answered with slow_down.
+const deviceInterval = 1
+
+// deviceCode is a pending authorization of the device flow.
+type deviceCode struct {
+	clientID string
+	userCode string
+	approved bool
+	lastPoll time.Time
+}
+
+// ApproveDevice approves the device flow authorization with the user code, as the user would in a browser.
+func (s *Server) ApproveDevice(userCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deviceCodes {
+		if d.userCode == userCode {
+			d.approved = true
+			return nil
+		}
+	}
+	return fmt.Errorf("no pending device authorization has the user code %s", userCode)
+}
+
+// clientCredentialsGrant issues a token to a client authenticating with its own credentials.
+func (s *Server) clientCredentialsGrant(w http.ResponseWriter, form url.Values) {
+	s.mu.Lock()
+	creds := s.creds
+	token := s.accessToken
+	s.mu.Unlock()
+
+	clientID, clientSecret := form.Get("client_id"), form.Get("client_secret")
+	if clientID == "" || clientSecret == "" ||
+		(creds != nil && (clientID != creds.ClientID || clientSecret != creds.ClientSecret)) {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClient, Description: "invalid client credentials"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.tokenResponse(token, clientSecret))
+}
+
+// authorizeDevice issues a device code and a user code for the device flow.
+func (s *Server) authorizeDevice(w http.ResponseWriter, form url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.creds != nil && form.Get("client_id") != s.creds.ClientID {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClientID, Description: "client identifier invalid"})
+		return
+	}
+
+	s.sequence++
+	code := &deviceCode{clientID: form.Get("client_id"), userCode: fmt.Sprintf("FAKE%04d", s.sequence)}
+	deviceCode := fmt.Sprintf("M0DFAKE%012d", s.sequence)
+	s.deviceCodes[deviceCode] = code
+	writeJSON(w, http.StatusOK, &force.DeviceAuthorization{DeviceCode: deviceCode, UserCode: code.userCode,
+		VerificationURI: s.URL + "/setup/connect", Interval: deviceInterval})
+}
+
+// deviceGrant issues a token once the device authorization is approved. Until then, requests are answered with
+// authorization_pending, or slow_down if they are sooner than the interval.
+func (s *Server) deviceGrant(w http.ResponseWriter, form url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, ok := s.deviceCodes[form.Get("code")]
+	switch {
+	case !ok || form.Get("client_id") != code.clientID:
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidGrant, Description: "invalid device code"})
+	case code.approved:
+		delete(s.deviceCodes, form.Get("code"))
+		response := s.tokenResponse(s.accessToken, "")
+		response["refresh_token"] = DefaultRefreshToken
+		writeJSON(w, http.StatusOK, response)
+	case time.Since(code.lastPoll) < deviceInterval*time.Second:
+		code.lastPoll = time.Now()
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.DeviceTokenErrorCodes.SlowDown, Description: "polling too frequently"})
+	default:
+		code.lastPoll = time.Now()
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode:   force.DeviceTokenErrorCodes.AuthorizationPending,
+			Description: "authorization request is pending"})
+	}
+}
+
+// handleRevoke revokes an access or refresh token. A revoked access token is replaced by a new one, which the token
+// endpoint issues from then on.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{ErrorCode: "invalid_request",
+			Description: "revoke requests must be posted with a token"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := r.PostForm.Get("token")
+	switch {
+	case s.revoked[token] || (token != s.accessToken && token != DefaultRefreshToken):
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{ErrorCode: "unsupported_token_type",
+			Description: "this token type is not supported"})
+		return
+	case token == s.accessToken:
+		s.sequence++
+		s.accessToken = fmt.Sprintf("%s!FAKE.ACCESS.TOKEN.%d", OrganizationID[:15], s.sequence)
+	}
+	s.revoked[token] = true
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleIntrospect reports whether a token is active. The connected app must authenticate with its client ID and
+// secret.
+func (s *Server) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{ErrorCode: "invalid_request",
+			Description: "introspection requests must be posted with a token"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clientID, clientSecret := r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	if clientID == "" || clientSecret == "" ||
+		(s.creds != nil && (clientID != s.creds.ClientID || clientSecret != s.creds.ClientSecret)) {
+		writeJSON(w, http.StatusUnauthorized, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClient, Description: "invalid client credentials"})
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if s.revoked[token] || (token != s.accessToken && token != DefaultRefreshToken) {
+		writeJSON(w, http.StatusOK, &force.TokenIntrospection{Active: false})
+		return
+	}
+
+	now := time.Now()
+	introspection := &force.TokenIntrospection{Active: true, Scope: "api refresh_token", ClientID: clientID,
+		Username: s.username(), Sub: fmt.Sprintf("%s/id/%s/%s", s.URL, OrganizationID, UserID),
+		TokenType: "access_token", Exp: now.Add(2 * time.Hour).Unix(), Iat: now.Unix(), Nbf: now.Unix()}
+	if token == DefaultRefreshToken {
+		introspection.TokenType = "refresh_token"
+	}
+	writeJSON(w, http.StatusOK, introspection)
+}
+
+// handleIdentity serves the identity URL of issued tokens and the OpenID Connect UserInfo endpoint.
+func (s *Server) handleIdentity(w http.ResponseWriter, r *http.Request, path string) {
+	s.mu.Lock()
+	username := s.username()
+	s.mu.Unlock()
+
+	id := fmt.Sprintf("%s/id/%s/%s", s.URL, OrganizationID, UserID)
+	if strings.HasPrefix(path, "/id/") && path != strings.TrimPrefix(id, s.URL) {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	photos := force.IdentityPhotos{Picture: s.URL + "/profilephoto/005/F", Thumbnail: s.URL + "/profilephoto/005/T"}
+	urls := force.IdentityURLs{
+		Enterprise:   s.URL + "/services/Soap/c/{version}/" + OrganizationID[:15],
+		Metadata:     s.URL + "/services/Soap/m/{version}/" + OrganizationID[:15],
+		Partner:      s.URL + "/services/Soap/u/{version}/" + OrganizationID[:15],
+		REST:         s.URL + "/services/data/v{version}/",
+		SObjects:     s.URL + "/services/data/v{version}/sobjects/",
+		Search:       s.URL + "/services/data/v{version}/search/",
+		Query:        s.URL + "/services/data/v{version}/query/",
+		Recent:       s.URL + "/services/data/v{version}/recent/",
+		ToolingSOAP:  s.URL + "/services/Soap/T/{version}/" + OrganizationID[:15],
+		ToolingREST:  s.URL + "/services/data/v{version}/tooling/",
+		Profile:      s.URL + "/" + UserID,
+		CustomDomain: s.URL,
+	}
+
+	if path == "/services/oauth2/userinfo" {
+		writeJSON(w, http.StatusOK, &force.UserInfo{Sub: id, UserID: UserID, OrganizationID: OrganizationID,
+			PreferredUsername: username, Nickname: "user", Name: "Fake User", Email: username, EmailVerified: true,
+			GivenName: "Fake", FamilyName: "User", Zoneinfo: "America/Los_Angeles", Photos: photos,
+			Profile: urls.Profile, Picture: photos.Picture, URLs: urls, Active: true, UserType: "STANDARD",
+			Language: "en_US", Locale: "en_US", UTCOffset: -28800000})
+		return
+	}
+	writeJSON(w, http.StatusOK, &force.Identity{ID: id, UserID: UserID, OrganizationID: OrganizationID,
+		Username: username, NickName: "user", DisplayName: "Fake User", Email: username, EmailVerified: true,
+		FirstName: "Fake", LastName: "User", Timezone: "America/Los_Angeles", Photos: photos, URLs: urls,
+		Active: true, UserType: "STANDARD", Language: "en_US", Locale: "en_US", UTCOffset: -28800000})
+}
+
+// username returns the username of the fake user. The caller must hold s.mu.
+func (s *Server) username() string {
+	if s.creds != nil {
+		return s.creds.Username
+	}
+	return "user@example.com"
+}
@@ -0,0 +1,241 @@
+package forcetest
+
+import (
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet {
+		writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A locator segment requests the next page of a previously executed query.
+	if len(segments) == 1 {
+		c, ok := s.cursors[segments[0]]
+		if !ok {
+			writeErrors(w, http.StatusBadRequest, "INVALID_QUERY_LOCATOR", "invalid query locator")
+			return
+		}
+		delete(s.cursors, segments[0])
+		writeJSON(w, http.StatusOK, s.page(r, c))
+		return
+	}
+
+	q, err := parseSOQL(r.URL.Query().Get("q"))
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, "MALFORMED_QUERY", err.Error())
+		return
+	}
+
+	meta, ok := s.sobjects[strings.ToLower(q.from)]
+	if !ok {
+		writeErrors(w, http.StatusBadRequest, "INVALID_TYPE",
+			fmt.Sprintf("sObject type '%s' is not supported.", q.from))
+		return
+	}
+	for _, f := range q.referencedFields() {
+		if !s.resolvable(meta, f) {
+			writeErrors(w, http.StatusBadRequest, "INVALID_FIELD",
+				fmt.Sprintf("No such column '%s' on entity '%s'.", f, meta.GetName()))
+			return
+		}
+	}
+
+	matches := make([]force.SObject, 0)
+	for _, record := range s.all(strings.ToLower(meta.GetName())) {
+		if q.where == nil || q.where.match(s.lookup(meta, record)) {
+			matches = append(matches, record)
+		}
+	}
+
+	if q.count {
+		writeJSON(w, http.StatusOK, force.QueryResult{Done: true, TotalSize: len(matches), Records: []force.SObject{}})
+		return
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return q.less(s.lookup(meta, matches[i]), s.lookup(meta, matches[j]))
+	})
+	if q.offset > 0 {
+		if q.offset > len(matches) {
+			q.offset = len(matches)
+		}
+		matches = matches[q.offset:]
+	}
+	if q.limit >= 0 && q.limit < len(matches) {
+		matches = matches[:q.limit]
+	}
+
+	records := make([]force.SObject, 0, len(matches))
+	for _, m := range matches {
+		records = append(records, s.project(meta, m, q.fields))
+	}
+	writeJSON(w, http.StatusOK, s.page(r, &cursor{records: records, total: len(records)}))
+}
+
+// cursor holds the records of a query that have yet to be returned.
+type cursor struct {
+	// records are the remaining records, starting at offset.
+	records []force.SObject
+
+	// total is the total number of records matched by the query.
+	total int
+
+	// offset is the position of the first remaining record in the full result.
+	offset int
+}
+
+// page returns the next page of a cursor, storing any remainder under a new query locator.
+// The caller must hold s.mu.
+func (s *Server) page(r *http.Request, c *cursor) *force.QueryResult {
+	records := c.records
+	result := &force.QueryResult{Done: true, TotalSize: c.total, Records: records}
+
+	if len(records) > s.pageSize {
+		s.sequence++
+		locator := fmt.Sprintf("01g%012s", strconv.FormatInt(int64(s.sequence), 36))
+		locator = fmt.Sprintf("%s%s-%d", locator, idSuffix(locator), c.offset+s.pageSize)
+		s.cursors[locator] = &cursor{records: records[s.pageSize:], total: c.total, offset: c.offset + s.pageSize}
+
+		// Strip the locator, if any, to get the '/services/data/{version}/query' prefix.
+		path := r.URL.Path
+		if i := strings.Index(path, "/query/"); i >= 0 {
+			path = path[:i+len("/query")]
+		}
+		path = strings.Replace(path, "/queryAll", "/query", 1)
+
+		result.Done = false
+		result.Records = records[:s.pageSize]
+		result.NextRecordsURL = path + "/" + locator
+	}
+
+	return result
+}
+
+// referencedFields returns every field path named in the SELECT and ORDER BY clauses.
+func (q *query) referencedFields() []string {
+	fields := make([]string, 0, len(q.fields)+len(q.orderBy))
+	fields = append(fields, q.fields...)
+	for _, o := range q.orderBy {
+		fields = append(fields, o.field)
+	}
+	return fields
+}
+
+// resolvable checks if a possibly dotted field path exists on the metadata. Objects without field metadata
+// accept any field.
+func (s *Server) resolvable(meta *force.SObjectMetadata, path string) bool {
+	if meta == nil || !meta.HasFields() {
+		return true
+	}
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		return findField(meta, parts[0]) != nil
+	}
+
+	ref := findRelationship(meta, parts[0])
+	if ref == nil {
+		return false
+	}
+	for _, target := range ref.ReferenceTo {
+		if s.resolvable(s.sobjects[strings.ToLower(target)], parts[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns a function resolving dotted field paths against a record. The caller must hold s.mu.
+func (s *Server) lookup(meta *force.SObjectMetadata, record force.SObject) func(string) interface{} {
+	return func(path string) interface{} {
+		return s.resolve(meta, record, path)
+	}
+}
+
+// resolve returns the value of a possibly dotted field path, following parent relationships.
+// The caller must hold s.mu.
+func (s *Server) resolve(meta *force.SObjectMetadata, record force.SObject, path string) interface{} {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		return fieldValue(record, path)
+	}
+
+	parentMeta, parent := s.parent(meta, record, parts[0])
+	if parent == nil {
+		return nil
+	}
+	return s.resolve(parentMeta, parent, parts[1])
+}
+
+// parent returns the record referenced by a relationship name. The caller must hold s.mu.
+func (s *Server) parent(meta *force.SObjectMetadata, record force.SObject, relationship string) (*force.SObjectMetadata, force.SObject) {
+	if meta == nil {
+		return nil, nil
+	}
+	ref := findRelationship(meta, relationship)
+	if ref == nil {
+		return nil, nil
+	}
+	id, ok := fieldValue(record, ref.GetName()).(string)
+	if !ok || id == "" {
+		return nil, nil
+	}
+	return s.find(id)
+}
+
+// project builds the query output of a record, nesting parent relationship fields. The caller must hold s.mu.
+func (s *Server) project(meta *force.SObjectMetadata, record force.SObject, fields []string) force.SObject {
+	out := force.SObject{"attributes": withAttributes(meta, record)["attributes"]}
+
+	// Group dotted paths by their relationship so each parent is projected once.
+	children := map[string][]string{}
+	relationships := make([]string, 0)
+	for _, f := range fields {
+		parts := strings.SplitN(f, ".", 2)
+		if len(parts) == 1 {
+			out[s.fieldName(meta, f)] = fieldValue(record, f)
+			continue
+		}
+		if _, ok := children[parts[0]]; !ok {
+			relationships = append(relationships, parts[0])
+		}
+		children[parts[0]] = append(children[parts[0]], parts[1])
+	}
+
+	for _, rel := range relationships {
+		name := rel
+		if ref := findRelationship(meta, rel); ref != nil {
+			name = ref.GetRelationshipName()
+		}
+		parentMeta, parent := s.parent(meta, record, rel)
+		if parent == nil {
+			out[name] = nil
+			continue
+		}
+		out[name] = s.project(parentMeta, parent, children[rel])
+	}
+
+	return out
+}
+
+// fieldValue returns a record's value for a case-insensitive field name.
+func fieldValue(record force.SObject, name string) interface{} {
+	if v, ok := record[name]; ok {
+		return v
+	}
+	for k, v := range record {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
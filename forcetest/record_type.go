@@ -0,0 +1,143 @@
+package forcetest
+
+import (
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"strings"
+)
+
+// AddRecordType adds a record type to a registered SObject and returns its ID, which is generated if info has
+// none. The master record type is added along with the first record type.
+//
+// Picklists restricts the values of picklist fields available to the record type, keyed by field name. The
+// values of other picklist fields are the active values of their describe.
+func (s *Server) AddRecordType(objectName string, info *force.SObjectRecordTypeInfoMetadata,
+	picklists map[string][]string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		return "", fmt.Errorf("sobject %s is not registered", objectName)
+	}
+	if meta.MasterRecordType() == nil {
+		meta.RecordTypeInfos = append(meta.RecordTypeInfos, &force.SObjectRecordTypeInfoMetadata{
+			Available:                force.Bool(true),
+			DefaultRecordTypeMapping: force.Bool(false),
+			DeveloperName:            force.String("Master"),
+			Name:                     force.String("Master"),
+			Master:                   force.Bool(true),
+			RecordTypeId:             force.String(force.MasterRecordTypeID),
+		})
+	}
+
+	if info.RecordTypeId == nil {
+		info.RecordTypeId = force.String(s.newID("012"))
+	}
+	if info.Available == nil {
+		info.Available = force.Bool(true)
+	}
+	if info.Name == nil {
+		info.Name = info.DeveloperName
+	}
+	meta.RecordTypeInfos = append(meta.RecordTypeInfos, info)
+
+	restricted := map[string][]string{}
+	for field, values := range picklists {
+		restricted[strings.ToLower(field)] = values
+	}
+	s.recordTypePicklists[info.GetRecordTypeId()] = restricted
+	return info.GetRecordTypeId(), nil
+}
+
+// handleUIAPI serves the picklist values resources of the User Interface API:
+// `object-info/{object}/picklist-values/{recordTypeId}` and `.../{recordTypeId}/{field}`.
+func (s *Server) handleUIAPI(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet || len(segments) < 4 || segments[0] != "object-info" ||
+		segments[2] != "picklist-values" {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, ok := s.sobjects[strings.ToLower(segments[1])]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND",
+			fmt.Sprintf("sObject type '%s' is not supported", segments[1]))
+		return
+	}
+	recordTypeID := segments[3]
+	restricted, ok := s.recordTypePicklists[recordTypeID]
+	if !ok && recordTypeID != force.MasterRecordTypeID {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("Invalid record type ID: %s", recordTypeID))
+		return
+	}
+
+	fields := map[string]*force.UIPicklistValues{}
+	for _, f := range meta.Fields {
+		if f.Type != force.FieldDataTypes.Picklist && f.Type != force.FieldDataTypes.Multipicklist {
+			continue
+		}
+		fields[f.GetName()] = uiPicklistValues(meta, f, restricted[strings.ToLower(f.GetName())])
+	}
+
+	if len(segments) == 5 {
+		for name, values := range fields {
+			if strings.EqualFold(name, segments[4]) {
+				writeJSON(w, http.StatusOK, values)
+				return
+			}
+		}
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("Invalid picklist field: %s", segments[4]))
+		return
+	}
+	writeJSON(w, http.StatusOK, &force.UIPicklistFieldValues{PicklistFieldValues: fields})
+}
+
+// uiPicklistValues returns the active values of a picklist field, restricted to the supplied values if any.
+func uiPicklistValues(meta *force.SObjectMetadata, f *force.SObjectFieldMetadata,
+	restricted []string) *force.UIPicklistValues {
+	allowed := map[string]bool{}
+	for _, value := range restricted {
+		allowed[value] = true
+	}
+
+	result := &force.UIPicklistValues{ControllerValues: map[string]int{}, Values: []*force.UIPicklistValue{}}
+	if controller := meta.FindField(f.GetControllerName()); f.GetDependentPicklist() && controller != nil {
+		if controller.Type == force.FieldDataTypes.Boolean {
+			result.ControllerValues["false"] = 0
+			result.ControllerValues["true"] = 1
+		}
+		for i, entry := range controller.PicklistValues {
+			result.ControllerValues[entry.GetValue()] = i
+		}
+	}
+
+	for i := range f.PicklistValues {
+		entry := &f.PicklistValues[i]
+		if entry.Active != nil && !entry.GetActive() {
+			continue
+		}
+		if len(allowed) > 0 && !allowed[entry.GetValue()] {
+			continue
+		}
+
+		value := &force.UIPicklistValue{Label: entry.GetLabel(), Value: entry.GetValue(), ValidFor: []int{}}
+		if value.Label == "" {
+			value.Label = value.Value
+		}
+		if f.GetDependentPicklist() {
+			if indexes, err := entry.ValidForIndexes(); err == nil {
+				value.ValidFor = indexes
+			}
+		}
+		if entry.GetDefaultValue() {
+			result.DefaultValue = value
+		}
+		result.Values = append(result.Values, value)
+	}
+	return result
+}
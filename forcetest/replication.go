@@ -0,0 +1,60 @@
+package forcetest
+
+import (
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// handleReplication serves the getUpdated and getDeleted resources of a SObject. Unlike Salesforce, the window
+// isn't rounded to the minute, so changes are reported as soon as they are made. The caller must hold s.mu.
+func (s *Server) handleReplication(w http.ResponseWriter, r *http.Request, meta *force.SObjectMetadata, resource string) {
+	now := time.Now()
+	start, startErr := time.Parse(time.RFC3339, r.URL.Query().Get("start"))
+	end, endErr := time.Parse(time.RFC3339, r.URL.Query().Get("end"))
+	switch {
+	case startErr != nil || endErr != nil:
+		writeErrors(w, http.StatusBadRequest, "MALFORMED_QUERY", "start and end must be ISO 8601 date times")
+		return
+	case !end.After(start):
+		writeErrors(w, http.StatusBadRequest, "INVALID_REPLICATION_DATE", "end date must be after start date")
+		return
+	case start.Before(now.Add(-force.ReplicationRetention)):
+		writeErrors(w, http.StatusBadRequest, "INVALID_REPLICATION_DATE",
+			fmt.Sprintf("start date cannot be more than %d days ago", int(force.ReplicationRetention.Hours()/24)))
+		return
+	}
+
+	covered := end
+	if covered.After(now) {
+		covered = now
+	}
+	within := func(value interface{}) bool {
+		str, _ := value.(string)
+		t, err := time.Parse(force.DateTimeFormat, str)
+		return err == nil && !t.Before(start) && t.Before(covered)
+	}
+
+	key := strings.ToLower(meta.GetName())
+	if resource == "updated" {
+		ids := make([]string, 0)
+		for _, id := range s.order[key] {
+			if record, ok := s.records[key][id]; ok && within(record["SystemModstamp"]) {
+				ids = append(ids, id)
+			}
+		}
+		writeJSON(w, http.StatusOK, &force.UpdatedResult{IDs: ids, LatestDateCovered: timestamp(covered)})
+		return
+	}
+
+	deleted := make([]*force.DeletedRecord, 0)
+	for _, d := range s.deleted[key] {
+		if within(d.DeletedDate) {
+			deleted = append(deleted, d)
+		}
+	}
+	writeJSON(w, http.StatusOK, &force.DeletedResult{DeletedRecords: deleted, LatestDateCovered: timestamp(covered),
+		EarliestDateAvailable: timestamp(now.Add(-force.ReplicationRetention))})
+}
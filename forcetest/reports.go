@@ -0,0 +1,488 @@
+package forcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportCurrency is the currency code of currency values in fake reports.
+const reportCurrency = "USD"
+
+// fakeReport is a report registered with the fake server.
+type fakeReport struct {
+	meta      *force.ReportMetadata
+	columns   map[string]*force.ReportColumnInfo
+	rows      []force.SObject
+	instances []*reportInstance
+}
+
+// reportInstance is an asynchronous run of a fake report. It reports Running on its first check and Success after.
+type reportInstance struct {
+	instance *force.ReportInstance
+	result   *force.ReportResult
+	checked  bool
+}
+
+// AddReport registers a report defined by its metadata, the label and data type of each column keyed by column
+// name, and its detail rows keyed by column name. The report ID is generated if meta has none and returned.
+//
+// Runs evaluate the report filters, which are all required to match, and group the rows by the down and across
+// groupings. The `RowCount` aggregate and the `s!COLUMN` sums are supported. Currency values are plain numbers.
+func (s *Server) AddReport(meta *force.ReportMetadata, columns map[string]*force.ReportColumnInfo,
+	rows ...force.SObject) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if meta.ID == "" {
+		meta.ID = s.newID("00O")
+	}
+	if meta.ReportFormat == "" {
+		meta.ReportFormat = force.ReportFormats.Tabular
+	}
+	if meta.Aggregates == nil {
+		meta.Aggregates = []string{"RowCount"}
+	}
+	s.reports[meta.ID] = &fakeReport{meta: meta, columns: columns, rows: rows}
+	s.reportOrder = append(s.reportOrder, meta.ID)
+	return meta.ID
+}
+
+// AddDashboard registers a dashboard returned when listing dashboards. Its ID is generated if it has none.
+func (s *Server) AddDashboard(dashboard *force.Dashboard) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dashboard.ID == "" {
+		dashboard.ID = s.newID("01Z")
+	}
+	s.dashboards = append(s.dashboards, dashboard)
+	return dashboard.ID
+}
+
+// handleAnalytics serves the report and dashboard resources of the Analytics API.
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && segments[0] == "dashboards" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, s.dashboards)
+	case len(segments) == 1 && segments[0] == "reports" && r.Method == http.MethodGet:
+		s.listReports(w, r)
+	case len(segments) >= 2 && segments[0] == "reports":
+		s.mu.Lock()
+		report, ok := s.reports[segments[1]]
+		s.mu.Unlock()
+		if !ok {
+			writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+			return
+		}
+		s.handleReport(w, r, report, segments[2:])
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+func (s *Server) listReports(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := make([]*force.Report, 0, len(s.reportOrder))
+	for _, id := range s.reportOrder {
+		urlStr := r.URL.Path + "/" + id
+		reports = append(reports, &force.Report{ID: id, Name: s.reports[id].meta.Name, URL: urlStr,
+			DescribeURL: urlStr + "/describe", InstancesURL: urlStr + "/instances"})
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request, report *fakeReport, segments []string) {
+	includeDetails, _ := strconv.ParseBool(r.URL.Query().Get("includeDetails"))
+
+	switch {
+	case len(segments) == 0 && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+		result, status, code, msg := s.runReport(r, report, includeDetails)
+		if result == nil {
+			writeErrors(w, status, code, msg)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	case len(segments) == 1 && segments[0] == "describe" && r.Method == http.MethodGet:
+		meta, info := report.describe(nil)
+		writeJSON(w, http.StatusOK, &force.ReportDescribe{ReportMetadata: meta, ReportExtendedMetadata: info})
+	case len(segments) == 1 && segments[0] == "instances" && r.Method == http.MethodPost:
+		result, status, code, msg := s.runReport(r, report, includeDetails)
+		if result == nil {
+			writeErrors(w, status, code, msg)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		instance := &force.ReportInstance{ID: s.newID("0LG"), Status: force.ReportInstanceStatuses.New,
+			RequestDate: time.Now().UTC().Format(time.RFC3339), OwnerID: UserID, HasDetailRows: includeDetails}
+		instance.URL = r.URL.Path + "/" + instance.ID
+		report.instances = append(report.instances, &reportInstance{instance: instance, result: result})
+		writeJSON(w, http.StatusCreated, instance)
+	case len(segments) == 1 && segments[0] == "instances" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		instances := make([]*force.ReportInstance, 0, len(report.instances))
+		for _, ri := range report.instances {
+			instances = append(instances, ri.instance)
+		}
+		writeJSON(w, http.StatusOK, instances)
+	case len(segments) == 2 && segments[0] == "instances" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, ri := range report.instances {
+			if ri.instance.ID != segments[1] {
+				continue
+			}
+			if !ri.checked {
+				ri.checked = true
+				ri.instance.Status = force.ReportInstanceStatuses.Running
+				writeJSON(w, http.StatusOK, &force.ReportResult{Attributes: ri.instance})
+				return
+			}
+			completed := time.Now().UTC().Format(time.RFC3339)
+			ri.instance.Status = force.ReportInstanceStatuses.Success
+			ri.instance.CompletionDate = &completed
+			ri.result.Attributes = ri.instance
+			writeJSON(w, http.StatusOK, ri.result)
+			return
+		}
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+// describe returns the report metadata with the overrides applied along with the extended metadata.
+func (report *fakeReport) describe(override *force.ReportMetadata) (*force.ReportMetadata, *force.ReportExtendedMetadata) {
+	meta := *report.meta
+	if override != nil {
+		if override.ReportFormat != "" {
+			meta.ReportFormat = override.ReportFormat
+		}
+		if override.DetailColumns != nil {
+			meta.DetailColumns = override.DetailColumns
+		}
+		if override.Aggregates != nil {
+			meta.Aggregates = override.Aggregates
+		}
+		if override.GroupingsDown != nil {
+			meta.GroupingsDown = override.GroupingsDown
+		}
+		if override.GroupingsAcross != nil {
+			meta.GroupingsAcross = override.GroupingsAcross
+		}
+		if override.ReportFilters != nil {
+			meta.ReportFilters = override.ReportFilters
+		}
+	}
+
+	info := &force.ReportExtendedMetadata{
+		DetailColumnInfo:    map[string]*force.ReportColumnInfo{},
+		AggregateColumnInfo: map[string]*force.ReportColumnInfo{},
+		GroupingColumnInfo:  map[string]*force.ReportColumnInfo{},
+	}
+	for _, name := range meta.DetailColumns {
+		if c, ok := report.columns[name]; ok {
+			info.DetailColumnInfo[name] = c
+		}
+	}
+	for _, name := range meta.Aggregates {
+		if name == "RowCount" {
+			info.AggregateColumnInfo[name] = &force.ReportColumnInfo{Label: "Record Count", DataType: "int"}
+		} else if c, ok := report.columns[strings.TrimPrefix(name, "s!")]; ok {
+			info.AggregateColumnInfo[name] = &force.ReportColumnInfo{Label: "Sum of " + c.Label, DataType: c.DataType}
+		}
+	}
+	for i, g := range append(append([]*force.ReportGrouping{}, meta.GroupingsDown...), meta.GroupingsAcross...) {
+		if c, ok := report.columns[g.Name]; ok {
+			level := i
+			if i >= len(meta.GroupingsDown) {
+				level = i - len(meta.GroupingsDown)
+			}
+			info.GroupingColumnInfo[g.Name] = &force.ReportColumnInfo{Label: c.Label, DataType: c.DataType,
+				GroupingLevel: &level}
+		}
+	}
+	return &meta, info
+}
+
+// runReport runs a report with the metadata posted in the request, if any. It returns the result, or the status,
+// error code and message of the failure.
+func (s *Server) runReport(r *http.Request, report *fakeReport, includeDetails bool) (*force.ReportResult, int, string, string) {
+	var body force.ReportRunRequest
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, info := report.describe(body.ReportMetadata)
+	for _, name := range referencedReportColumns(meta) {
+		if _, ok := report.columns[name]; !ok {
+			return nil, http.StatusBadRequest, "BAD_REQUEST", fmt.Sprintf("The column %s is invalid", name)
+		}
+	}
+
+	matched := make([]int, 0, len(report.rows))
+	for i, row := range report.rows {
+		ok, err := matchReportFilters(row, meta.ReportFilters)
+		if err != nil {
+			return nil, http.StatusBadRequest, "BAD_REQUEST", err.Error()
+		}
+		if ok {
+			matched = append(matched, i)
+		}
+	}
+
+	downKeys := map[string][]int{"T": matched}
+	acrossKeys := map[string][]int{"T": matched}
+	result := &force.ReportResult{
+		AllData:                true,
+		HasDetailRows:          includeDetails,
+		FactMap:                map[string]*force.ReportFact{},
+		GroupingsDown:          &force.ReportGroupings{Groupings: report.group(matched, meta.GroupingsDown, "", downKeys)},
+		GroupingsAcross:        &force.ReportGroupings{Groupings: report.group(matched, meta.GroupingsAcross, "", acrossKeys)},
+		ReportMetadata:         meta,
+		ReportExtendedMetadata: info,
+	}
+
+	for dk, down := range downKeys {
+		for ak, across := range acrossKeys {
+			rows := intersectRows(down, across)
+			fact := &force.ReportFact{Aggregates: report.aggregate(rows, meta.Aggregates, info)}
+			if includeDetails && len(meta.GroupingsAcross) == 0 {
+				fact.Rows = make([]*force.ReportFactRow, 0, len(rows))
+				for _, i := range rows {
+					cells := make([]*force.ReportFactValue, 0, len(meta.DetailColumns))
+					for _, name := range meta.DetailColumns {
+						cells = append(cells, reportFactValue(report.rows[i][name], report.columns[name].DataType))
+					}
+					fact.Rows = append(fact.Rows, &force.ReportFactRow{DataCells: cells})
+				}
+			}
+			result.FactMap[dk+"!"+ak] = fact
+		}
+	}
+	return result, 0, "", ""
+}
+
+// referencedReportColumns returns the columns used by the metadata.
+func referencedReportColumns(meta *force.ReportMetadata) []string {
+	names := append([]string{}, meta.DetailColumns...)
+	for _, name := range meta.Aggregates {
+		if name != "RowCount" {
+			names = append(names, strings.TrimPrefix(name, "s!"))
+		}
+	}
+	for _, g := range append(append([]*force.ReportGrouping{}, meta.GroupingsDown...), meta.GroupingsAcross...) {
+		names = append(names, g.Name)
+	}
+	for _, f := range meta.ReportFilters {
+		names = append(names, f.Column)
+	}
+	return names
+}
+
+// group groups rows by the first grouping and recursively by the rest, recording the rows of every grouping key.
+func (report *fakeReport) group(rows []int, groupings []*force.ReportGrouping, prefix string,
+	keys map[string][]int) []*force.ReportGroupingValue {
+	values := make([]*force.ReportGroupingValue, 0)
+	if len(groupings) == 0 {
+		return values
+	}
+
+	name := groupings[0].Name
+	byValue := map[string][]int{}
+	distinct := make([]interface{}, 0)
+	for _, i := range rows {
+		v := report.rows[i][name]
+		label := reportLabel(v)
+		if _, ok := byValue[label]; !ok {
+			distinct = append(distinct, v)
+		}
+		byValue[label] = append(byValue[label], i)
+	}
+	sort.SliceStable(distinct, func(i, j int) bool { return compareReportValues(distinct[i], distinct[j]) < 0 })
+	if strings.EqualFold(groupings[0].SortOrder, "desc") {
+		for i, j := 0, len(distinct)-1; i < j; i, j = i+1, j-1 {
+			distinct[i], distinct[j] = distinct[j], distinct[i]
+		}
+	}
+
+	for n, v := range distinct {
+		key := strconv.Itoa(n)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		label := reportLabel(v)
+		keys[key] = byValue[label]
+		raw, _ := json.Marshal(v)
+		values = append(values, &force.ReportGroupingValue{Key: key, Label: label, Value: raw,
+			Groupings: report.group(byValue[label], groupings[1:], key, keys)})
+	}
+	return values
+}
+
+// aggregate computes the aggregates of rows.
+func (report *fakeReport) aggregate(rows []int, aggregates []string, info *force.ReportExtendedMetadata) []*force.ReportFactValue {
+	values := make([]*force.ReportFactValue, 0, len(aggregates))
+	for _, name := range aggregates {
+		if name == "RowCount" {
+			values = append(values, reportFactValue(len(rows), "int"))
+			continue
+		}
+
+		sum := 0.0
+		for _, i := range rows {
+			if f, ok := reportNumber(report.rows[i][strings.TrimPrefix(name, "s!")]); ok {
+				sum += f
+			}
+		}
+		values = append(values, reportFactValue(sum, info.AggregateColumnInfo[name].DataType))
+	}
+	return values
+}
+
+// intersectRows returns the rows present in both a and b, in order.
+func intersectRows(a, b []int) []int {
+	in := map[int]bool{}
+	for _, i := range b {
+		in[i] = true
+	}
+	rows := make([]int, 0)
+	for _, i := range a {
+		if in[i] {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// matchReportFilters reports whether a row matches every filter.
+func matchReportFilters(row force.SObject, filters []*force.ReportFilter) (bool, error) {
+	for _, f := range filters {
+		v := row[f.Column]
+		candidates := strings.Split(f.Value, ",")
+
+		var ok bool
+		switch f.Operator {
+		case force.ReportFilterOperators.Equals:
+			ok = anyReportValue(candidates, func(c string) bool { return compareReportValues(v, c) == 0 })
+		case force.ReportFilterOperators.NotEqual:
+			ok = !anyReportValue(candidates, func(c string) bool { return compareReportValues(v, c) == 0 })
+		case force.ReportFilterOperators.LessThan:
+			ok = v != nil && compareReportValues(v, f.Value) < 0
+		case force.ReportFilterOperators.GreaterThan:
+			ok = v != nil && compareReportValues(v, f.Value) > 0
+		case force.ReportFilterOperators.LessOrEqual:
+			ok = v != nil && compareReportValues(v, f.Value) <= 0
+		case force.ReportFilterOperators.GreaterOrEqual:
+			ok = v != nil && compareReportValues(v, f.Value) >= 0
+		case force.ReportFilterOperators.Contains:
+			ok = anyReportValue(candidates, func(c string) bool {
+				return strings.Contains(strings.ToLower(reportString(v)), strings.ToLower(c))
+			})
+		case force.ReportFilterOperators.NotContain:
+			ok = !anyReportValue(candidates, func(c string) bool {
+				return strings.Contains(strings.ToLower(reportString(v)), strings.ToLower(c))
+			})
+		case force.ReportFilterOperators.StartsWith:
+			ok = anyReportValue(candidates, func(c string) bool {
+				return strings.HasPrefix(strings.ToLower(reportString(v)), strings.ToLower(c))
+			})
+		default:
+			return false, fmt.Errorf("The filter operator %s is not supported", f.Operator)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func anyReportValue(candidates []string, match func(string) bool) bool {
+	for _, c := range candidates {
+		if match(strings.TrimSpace(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareReportValues compares two values numerically if both are numbers and as case-insensitive strings
+// otherwise, which orders ISO dates chronologically.
+func compareReportValues(a, b interface{}) int {
+	fa, okA := reportNumber(a)
+	fb, okB := reportNumber(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(reportString(a)), strings.ToLower(reportString(b)))
+}
+
+// reportNumber converts a number or numeric string to a float64.
+func reportNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func reportString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// reportLabel formats a value the way Salesforce displays it, which is a dash for empty values.
+func reportLabel(v interface{}) string {
+	if v == nil || v == "" {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
+
+// reportFactValue encodes a value as a report cell, where currencies are objects holding an amount and currency.
+func reportFactValue(v interface{}, dataType string) *force.ReportFactValue {
+	value := v
+	label := reportLabel(v)
+	if dataType == "currency" {
+		value = map[string]interface{}{"amount": v, "currency": reportCurrency}
+		if v != nil {
+			label = fmt.Sprintf("%s %v", reportCurrency, v)
+		}
+	}
+	raw, _ := json.Marshal(value)
+	return &force.ReportFactValue{Label: label, Value: raw}
+}
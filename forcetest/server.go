@@ -0,0 +1,667 @@
+// Package forcetest provides an in-process fake of the Salesforce REST API.
+//
+// A Server emulates the OAuth authorize and token endpoints along with the describe, sobject CRUD, blob, query,
+// composite and invocable action endpoints over an in-memory record store, the Tooling API, Analytics reports,
+// record type picklist values of the User Interface API, custom Apex REST handlers, a Bayeux stand-in of the
+// Streaming API and a SOAP stand-in of the Metadata API.
+// It is meant to be used in tests that construct a force.Client pointed at the fake:
+//
+//	fake := forcetest.NewServer()
+//	defer fake.Close()
+//
+//	fake.AddSObject(forcetest.NewSObjectMetadata("Account", "001",
+//		forcetest.NewField("Name", force.FieldDataTypes.String)))
+//
+//	client, err := force.New(force.InstanceURL(fake.URL), force.AccessToken(fake.AccessToken()))
+package forcetest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAccessToken is the access token issued by the fake token endpoint.
+	DefaultAccessToken = "00D000000000001!FAKE.ACCESS.TOKEN"
+
+	// DefaultPageSize is the number of records returned per query page before a nextRecordsUrl is issued.
+	DefaultPageSize = 2000
+
+	// OrganizationID is the ID of the fake organization.
+	OrganizationID = "00D000000000001AAA"
+
+	// UserID is the ID of the fake authenticated user.
+	UserID = "005000000000001AAA"
+
+	// DefaultRefreshToken is the refresh token issued by the web server and device flows.
+	DefaultRefreshToken = "5Aep861FAKE.REFRESH.TOKEN"
+)
+
+// Server is a fake Salesforce instance backed by an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	// mu protects all fields below.
+	mu sync.Mutex
+
+	// creds, if set, are the only credentials accepted by the token endpoint.
+	creds *force.OAuthCredentials
+
+	// accessToken is the token issued to clients and required on every API request.
+	accessToken string
+
+	// pageSize is the maximum number of records returned in a single query page.
+	pageSize int
+
+	// sobjects holds registered metadata keyed by lower-cased object name.
+	sobjects map[string]*force.SObjectMetadata
+
+	// records holds each object's records keyed by lower-cased object name and then by record ID.
+	records map[string]map[string]force.SObject
+
+	// order holds the insertion order of record IDs per lower-cased object name.
+	order map[string][]string
+
+	// cursors holds the remaining records of paginated queries keyed by query locator.
+	cursors map[string]*cursor
+
+	// sequence is used to generate record IDs and query locators.
+	sequence int
+
+	// apiRequests counts the API requests served, reported by the limits endpoint.
+	apiRequests int
+
+	// streaming is the state of the fake Streaming API.
+	streaming *bayeux
+
+	// anonymousApex, if set, emulates anonymous Apex execution.
+	anonymousApex AnonymousApexFunc
+
+	// apexTests holds the Apex test methods reported by test runs.
+	apexTests []*ApexTest
+
+	// apexCoverage holds the Apex code coverage reported by synchronous test runs.
+	apexCoverage []*ApexCoverage
+
+	// actions holds the invocable actions keyed by actionKey.
+	actions map[string]*fakeAction
+
+	// apexREST holds the custom Apex REST handlers keyed by URL mapping.
+	apexREST map[string]http.HandlerFunc
+
+	// metadata holds the components of the fake Metadata API keyed by lower-cased type and full name.
+	metadata map[string]*metadataComponent
+
+	// metadataOrder holds the keys of stored components in the order they were first stored.
+	metadataOrder []string
+
+	// metadataJobs holds deploys and retrieves keyed by ID.
+	metadataJobs map[string]*metadataJob
+
+	// reports holds the reports of the Analytics API keyed by ID.
+	reports map[string]*fakeReport
+
+	// reportOrder holds the report IDs in the order they were added.
+	reportOrder []string
+
+	// dashboards holds the dashboards of the Analytics API.
+	dashboards []*force.Dashboard
+
+	// blobs holds the content of blob fields keyed by record ID and lower-cased field name.
+	blobs map[string][]byte
+
+	// deleted holds each object's deleted records keyed by lower-cased object name, in the order deleted.
+	deleted map[string][]*force.DeletedRecord
+
+	// recordTypePicklists holds the picklist values restricted by record type, keyed by record type ID and then by
+	// lower-cased field name.
+	recordTypePicklists map[string]map[string][]string
+
+	// authCodes holds the unused authorization codes of the web server flow.
+	authCodes map[string]*authCode
+
+	// deviceCodes holds the pending authorizations of the device flow keyed by device code.
+	deviceCodes map[string]*deviceCode
+
+	// revoked holds the tokens revoked through the revoke endpoint.
+	revoked map[string]bool
+}
+
+// NewServer starts and returns a new fake Salesforce server. Callers should Close it when finished.
+func NewServer() *Server {
+	s := &Server{
+		accessToken:  DefaultAccessToken,
+		pageSize:     DefaultPageSize,
+		sobjects:     map[string]*force.SObjectMetadata{},
+		records:      map[string]map[string]force.SObject{},
+		order:        map[string][]string{},
+		cursors:      map[string]*cursor{},
+		streaming:    newBayeux(),
+		metadata:     map[string]*metadataComponent{},
+		metadataJobs: map[string]*metadataJob{},
+		apexREST:     map[string]http.HandlerFunc{},
+		actions:      map[string]*fakeAction{},
+		reports:      map[string]*fakeReport{},
+		dashboards:   []*force.Dashboard{},
+		blobs:        map[string][]byte{},
+		deleted:      map[string][]*force.DeletedRecord{},
+
+		recordTypePicklists: map[string]map[string][]string{},
+		authCodes:           map[string]*authCode{},
+		deviceCodes:         map[string]*deviceCode{},
+		revoked:             map[string]bool{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetCredentials restricts the token endpoint to only accept the supplied credentials.
+//
+// When no credentials are set, any non-empty username and password are accepted.
+func (s *Server) SetCredentials(username, password, clientID, clientSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds = &force.OAuthCredentials{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Username:     username,
+		Password:     password,
+	}
+}
+
+// AccessToken returns the access token accepted by the server.
+func (s *Server) AccessToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken
+}
+
+// SetAccessToken changes the access token issued and accepted by the server.
+func (s *Server) SetAccessToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = token
+}
+
+// SetPageSize sets the maximum number of records returned per query page.
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// AddSObject registers a SObject so it can be described, queried and written to.
+func (s *Server) AddSObject(meta *force.SObjectMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(meta.GetName())
+	s.sobjects[key] = meta
+	if _, ok := s.records[key]; !ok {
+		s.records[key] = map[string]force.SObject{}
+	}
+}
+
+// Insert seeds a record directly into the store and returns its generated ID.
+//
+// The SObject must be registered via AddSObject first.
+func (s *Server) Insert(objectName string, record force.SObject) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.sobjects[strings.ToLower(objectName)]
+	if !ok {
+		return "", fmt.Errorf("sobject %s is not registered", objectName)
+	}
+	return s.insert(meta, record), nil
+}
+
+// Record returns a copy of a stored record.
+func (s *Server) Record(objectName, id string) (force.SObject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[strings.ToLower(objectName)][id]
+	if !ok {
+		return nil, false
+	}
+	return copyRecord(r), true
+}
+
+// Records returns copies of all stored records of an object in insertion order.
+func (s *Server) Records(objectName string) []force.SObject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.all(strings.ToLower(objectName))
+}
+
+// all returns copies of an object's records in insertion order. The caller must hold s.mu.
+func (s *Server) all(key string) []force.SObject {
+	records := make([]force.SObject, 0)
+	for _, id := range s.order[key] {
+		if r, ok := s.records[key][id]; ok {
+			records = append(records, copyRecord(r))
+		}
+	}
+	return records
+}
+
+// insert stores a new record and returns its ID. The caller must hold s.mu.
+func (s *Server) insert(meta *force.SObjectMetadata, fields force.SObject) string {
+	key := strings.ToLower(meta.GetName())
+	id := s.newID(meta.GetKeyPrefix())
+	now := timestamp(time.Now())
+
+	record := force.SObject{}
+	for k, v := range fields {
+		if k == "attributes" {
+			continue
+		}
+		record[s.fieldName(meta, k)] = v
+	}
+	record["Id"] = id
+	record["CreatedDate"] = now
+	record["LastModifiedDate"] = now
+	record["SystemModstamp"] = now
+
+	s.records[key][id] = record
+	s.order[key] = append(s.order[key], id)
+	return id
+}
+
+// remove deletes a record, remembering when for GetDeleted. The caller must hold s.mu.
+func (s *Server) remove(meta *force.SObjectMetadata, id string) {
+	key := strings.ToLower(meta.GetName())
+	delete(s.records[key], id)
+	s.deleted[key] = append(s.deleted[key], &force.DeletedRecord{ID: id, DeletedDate: timestamp(time.Now())})
+}
+
+// find looks up a record by ID across all objects. The caller must hold s.mu.
+func (s *Server) find(id string) (*force.SObjectMetadata, force.SObject) {
+	for key, records := range s.records {
+		if r, ok := records[id]; ok {
+			return s.sobjects[key], r
+		}
+	}
+	return nil, nil
+}
+
+// fieldName returns the properly cased name of a field if it's defined in the metadata.
+func (s *Server) fieldName(meta *force.SObjectMetadata, name string) string {
+	if f := findField(meta, name); f != nil {
+		return f.GetName()
+	}
+	return name
+}
+
+// newID generates an 18 character record ID for the key prefix. The caller must hold s.mu.
+func (s *Server) newID(keyPrefix string) string {
+	s.sequence++
+	if len(keyPrefix) != 3 {
+		keyPrefix = "a00"
+	}
+	id := fmt.Sprintf("%s%012s", keyPrefix, strconv.FormatInt(int64(s.sequence), 36))
+	return id + idSuffix(id)
+}
+
+// idSuffix computes the three character case-sensitivity checksum of a 15 character ID.
+func idSuffix(id string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"
+	suffix := ""
+	for chunk := 0; chunk < 3; chunk++ {
+		flags := 0
+		for i := 0; i < 5; i++ {
+			c := id[chunk*5+i]
+			if c >= 'A' && c <= 'Z' {
+				flags |= 1 << uint(i)
+			}
+		}
+		suffix += string(alphabet[flags])
+	}
+	return suffix
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	switch {
+	case path == "/services/oauth2/token":
+		s.handleToken(w, r)
+	case path == "/services/oauth2/authorize":
+		s.handleAuthorize(w, r)
+	case path == "/services/oauth2/revoke":
+		s.handleRevoke(w, r)
+	case path == "/services/oauth2/introspect":
+		s.handleIntrospect(w, r)
+	case path == "/services/oauth2/userinfo" || strings.HasPrefix(path, "/id/"):
+		if !s.authorized(r) {
+			writeErrors(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+			return
+		}
+		s.handleIdentity(w, r, path)
+	case strings.HasPrefix(path, "/services/data/"):
+		if !s.authorized(r) {
+			writeErrors(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+			return
+		}
+
+		s.mu.Lock()
+		s.apiRequests++
+		s.mu.Unlock()
+
+		// Strip '/services/data/{version}' leaving the resource segments.
+		segments := strings.Split(strings.TrimPrefix(path, "/services/data/"), "/")
+		s.handleData(w, r, segments[1:])
+	case strings.HasPrefix(path, "/services/apexrest/"):
+		if !s.authorized(r) {
+			writeErrors(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+			return
+		}
+		s.handleApexREST(w, r, strings.TrimPrefix(path, "/services/apexrest"))
+	case strings.HasPrefix(path, "/services/Soap/m/"):
+		s.handleMetadata(w, r)
+	case strings.HasPrefix(path, "/cometd/"):
+		if !s.authorized(r) {
+			writeErrors(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+			return
+		}
+		s.handleStreaming(w, r)
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	return r.Header.Get("Authorization") == "Bearer "+s.AccessToken()
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{ErrorCode: "invalid_request", Description: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	creds := s.creds
+	token := s.accessToken
+	s.mu.Unlock()
+
+	if r.PostForm.Get("response_type") == "device_code" {
+		s.authorizeDevice(w, r.PostForm)
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+	case "authorization_code":
+		s.authorizationCodeGrant(w, r.PostForm)
+		return
+	case "client_credentials":
+		s.clientCredentialsGrant(w, r.PostForm)
+		return
+	case "device":
+		s.deviceGrant(w, r.PostForm)
+		return
+	default:
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: "unsupported_grant_type", Description: "grant type not supported"})
+		return
+	}
+
+	username, password := r.PostForm.Get("username"), r.PostForm.Get("password")
+	clientID, clientSecret := r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+
+	if creds != nil && (clientID != creds.ClientID || clientSecret != creds.ClientSecret) {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidClientID, Description: "client identifier invalid"})
+		return
+	}
+	if username == "" || password == "" || (creds != nil && (username != creds.Username || password != creds.Password)) {
+		writeJSON(w, http.StatusBadRequest, force.TokenErrorResponse{
+			ErrorCode: force.TokenErrorCodes.InvalidGrant, Description: "authentication failure"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.tokenResponse(token, clientSecret))
+}
+
+// tokenResponse builds a signed token response for the access token.
+func (s *Server) tokenResponse(token, clientSecret string) map[string]string {
+	id := fmt.Sprintf("%s/id/%s/%s", s.URL, OrganizationID, UserID)
+	issuedAt := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+
+	mac := hmac.New(sha256.New, []byte(clientSecret))
+	mac.Write([]byte(id + issuedAt))
+
+	return map[string]string{
+		"id":           id,
+		"issued_at":    issuedAt,
+		"instance_url": s.URL,
+		"access_token": token,
+		"signature":    base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		"token_type":   "Bearer",
+	}
+}
+
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	switch segments[0] {
+	case "sobjects":
+		s.handleSObjects(w, r, segments[1:])
+	case "query", "queryAll":
+		s.handleQuery(w, r, segments[1:])
+	case "composite":
+		s.handleComposite(w, r, segments[1:])
+	case "limits":
+		s.handleLimits(w)
+	case "tooling":
+		s.handleTooling(w, r, segments[1:])
+	case "actions":
+		s.handleActions(w, r, segments[1:])
+	case "analytics":
+		s.handleAnalytics(w, r, segments[1:])
+	case "ui-api":
+		s.handleUIAPI(w, r, segments[1:])
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+func (s *Server) handleSObjects(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 && r.Method == http.MethodGet {
+		s.handleDescribeGlobal(w)
+		return
+	}
+	if len(segments) == 0 {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, ok := s.sobjects[strings.ToLower(segments[0])]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodPost && isMultipart(r):
+		s.createWithBlob(w, r, meta)
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var fields force.SObject
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+			return
+		}
+		if status, code, msg := s.validateFields(meta, fields); status != 0 {
+			writeErrors(w, status, code, msg)
+			return
+		}
+		if isPlatformEvent(meta) {
+			id, replayID := s.publishEvent(meta, fields)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{
+				"id": id, "success": true, "replayId": replayID, "errors": []interface{}{}})
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"id": s.insert(meta, fields), "success": true, "errors": []interface{}{}})
+	case len(segments) == 2 && segments[1] == "describe" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, meta)
+	case len(segments) == 2 && (segments[1] == "updated" || segments[1] == "deleted") && r.Method == http.MethodGet:
+		s.handleReplication(w, r, meta, segments[1])
+	case len(segments) == 2:
+		s.handleRecord(w, r, meta, segments[1])
+	case len(segments) == 3:
+		s.handleBlob(w, r, meta, segments[1], segments[2])
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+// handleDescribeGlobal lists the basic metadata of the registered SObjects, ordered by name.
+func (s *Server) handleDescribeGlobal(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sobjects := make([]*force.SObjectMetadata, 0, len(s.sobjects))
+	for _, meta := range s.sobjects {
+		basic := *meta
+		basic.Fields = nil
+		basic.ChildRelationships = nil
+		basic.RecordTypeInfos = nil
+		sobjects = append(sobjects, &basic)
+	}
+	sort.Slice(sobjects, func(i, j int) bool { return sobjects[i].GetName() < sobjects[j].GetName() })
+	writeJSON(w, http.StatusOK, &force.DescribeGlobalResult{Encoding: "UTF-8", MaxBatchSize: 200, SObjects: sobjects})
+}
+
+// handleRecord serves a single record. The caller must hold s.mu.
+func (s *Server) handleRecord(w http.ResponseWriter, r *http.Request, meta *force.SObjectMetadata, id string) {
+	key := strings.ToLower(meta.GetName())
+	record, ok := s.records[key][id]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		out := withAttributes(meta, record)
+		if fields := r.URL.Query().Get("fields"); fields != "" {
+			out = force.SObject{"attributes": out["attributes"]}
+			for _, f := range strings.Split(fields, ",") {
+				name := s.fieldName(meta, strings.TrimSpace(f))
+				out[name] = record[name]
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodPatch:
+		var fields force.SObject
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+			return
+		}
+		if status, code, msg := s.validateFields(meta, fields); status != 0 {
+			writeErrors(w, status, code, msg)
+			return
+		}
+		s.update(meta, record, fields)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.remove(meta, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+	}
+}
+
+func (s *Server) handleLimits(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]*force.Limit{
+		"DailyApiRequests": {Max: 15000, Remaining: 15000 - s.apiRequests},
+		"DataStorageMB":    {Max: 5, Remaining: 5},
+		"FileStorageMB":    {Max: 20, Remaining: 20},
+	})
+}
+
+// update applies fields onto an existing record. The caller must hold s.mu.
+func (s *Server) update(meta *force.SObjectMetadata, record, fields force.SObject) {
+	for k, v := range fields {
+		if k == "attributes" || strings.EqualFold(k, "Id") {
+			continue
+		}
+		record[s.fieldName(meta, k)] = v
+	}
+	now := timestamp(time.Now())
+	record["LastModifiedDate"] = now
+	record["SystemModstamp"] = now
+}
+
+// validateFields rejects fields not present in the metadata, if the metadata defines any fields.
+func (s *Server) validateFields(meta *force.SObjectMetadata, fields force.SObject) (int, string, string) {
+	if !meta.HasFields() {
+		return 0, "", ""
+	}
+	for k := range fields {
+		if k == "attributes" {
+			continue
+		}
+		if findField(meta, k) == nil {
+			return http.StatusBadRequest, "INVALID_FIELD",
+				fmt.Sprintf("No such column '%s' on sobject of type %s", k, meta.GetName())
+		}
+	}
+	return 0, "", ""
+}
+
+// withAttributes returns a copy of the record including its 'attributes' entry.
+func withAttributes(meta *force.SObjectMetadata, record force.SObject) force.SObject {
+	out := copyRecord(record)
+	out["attributes"] = map[string]interface{}{
+		"type": meta.GetName(),
+		"url":  fmt.Sprintf("/services/data/%s/sobjects/%s/%v", force.DefaultAPIVersion, meta.GetName(), record["Id"]),
+	}
+	return out
+}
+
+func copyRecord(r force.SObject) force.SObject {
+	out := make(force.SObject, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	return out
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000+0000")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErrors(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, []map[string]interface{}{{"message": message, "errorCode": code}})
+}
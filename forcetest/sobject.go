@@ -0,0 +1,87 @@
+package forcetest
+
+import (
+	"github.com/davidji99/force-go/force"
+	"strings"
+)
+
+// NewSObjectMetadata returns metadata for a queryable, createable and updateable SObject with the supplied fields.
+//
+// The 'Id', 'CreatedDate', 'LastModifiedDate' and 'SystemModstamp' system fields are added if they aren't supplied
+// as the server maintains them on every record. The key prefix is used as the first three characters of record IDs.
+func NewSObjectMetadata(name, keyPrefix string, fields ...*force.SObjectFieldMetadata) *force.SObjectMetadata {
+	meta := &force.SObjectMetadata{
+		Name:         force.String(name),
+		Label:        force.String(name),
+		LabelPlural:  force.String(name),
+		KeyPrefix:    force.String(keyPrefix),
+		Custom:       force.Bool(strings.HasSuffix(name, "__c")),
+		Createable:   force.Bool(true),
+		Deletable:    force.Bool(true),
+		Queryable:    force.Bool(true),
+		Retrieveable: force.Bool(true),
+		Updateable:   force.Bool(true),
+		Fields:       []*force.SObjectFieldMetadata{},
+	}
+
+	system := []*force.SObjectFieldMetadata{
+		NewField("Id", force.FieldDataTypes.ID),
+		NewField("CreatedDate", force.FieldDataTypes.DateTime),
+		NewField("LastModifiedDate", force.FieldDataTypes.DateTime),
+		NewField("SystemModstamp", force.FieldDataTypes.DateTime),
+	}
+	system[0].Length = force.Int(18)
+	for _, f := range system {
+		f.Createable = force.Bool(false)
+		f.Updateable = force.Bool(false)
+		f.Nillable = force.Bool(false)
+		if findField(&force.SObjectMetadata{Fields: fields}, f.GetName()) == nil {
+			meta.Fields = append(meta.Fields, f)
+		}
+	}
+	meta.Fields = append(meta.Fields, fields...)
+
+	return meta
+}
+
+// NewField returns metadata for a createable, updateable and nillable field.
+func NewField(name string, fieldType force.FieldDataType) *force.SObjectFieldMetadata {
+	return &force.SObjectFieldMetadata{
+		Name:       force.String(name),
+		Label:      force.String(name),
+		Type:       fieldType,
+		Createable: force.Bool(true),
+		Updateable: force.Bool(true),
+		Nillable:   force.Bool(true),
+		Custom:     force.Bool(strings.HasSuffix(name, "__c")),
+	}
+}
+
+// NewReferenceField returns metadata for a lookup field pointing at the referenced SObjects.
+func NewReferenceField(name, relationshipName string, referenceTo ...string) *force.SObjectFieldMetadata {
+	f := NewField(name, force.FieldDataTypes.Reference)
+	f.RelationshipName = force.String(relationshipName)
+	f.ReferenceTo = referenceTo
+	f.Length = force.Int(18)
+	return f
+}
+
+// findField returns a field by case-insensitive name.
+func findField(meta *force.SObjectMetadata, name string) *force.SObjectFieldMetadata {
+	for _, f := range meta.Fields {
+		if strings.EqualFold(f.GetName(), name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// findRelationship returns a reference field by case-insensitive relationship name.
+func findRelationship(meta *force.SObjectMetadata, name string) *force.SObjectFieldMetadata {
+	for _, f := range meta.Fields {
+		if f.RelationshipName != nil && strings.EqualFold(f.GetRelationshipName(), name) {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package forcetest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a parsed SOQL statement.
+//
+// Only the subset of SOQL needed by tests is supported: a field list (including parent relationship
+// fields such as 'Account.Name' and COUNT()), a single FROM object, WHERE clauses made of comparisons,
+// IN, NOT IN and LIKE combined with AND, OR and parentheses, ORDER BY, LIMIT and OFFSET.
+type query struct {
+	fields  []string
+	count   bool
+	from    string
+	where   condition
+	orderBy []ordering
+	limit   int
+	offset  int
+}
+
+type ordering struct {
+	field string
+	desc  bool
+}
+
+// condition evaluates a WHERE clause against a record. The lookup function resolves a field path.
+type condition interface {
+	match(lookup func(path string) interface{}) bool
+}
+
+type andCondition []condition
+
+func (a andCondition) match(lookup func(string) interface{}) bool {
+	for _, c := range a {
+		if !c.match(lookup) {
+			return false
+		}
+	}
+	return true
+}
+
+type orCondition []condition
+
+func (o orCondition) match(lookup func(string) interface{}) bool {
+	for _, c := range o {
+		if c.match(lookup) {
+			return true
+		}
+	}
+	return false
+}
+
+type comparison struct {
+	field  string
+	op     string
+	values []interface{}
+}
+
+func (c *comparison) match(lookup func(string) interface{}) bool {
+	actual := lookup(c.field)
+
+	switch c.op {
+	case "in":
+		for _, v := range c.values {
+			if compare(actual, v) == 0 {
+				return true
+			}
+		}
+		return false
+	case "not in":
+		for _, v := range c.values {
+			if compare(actual, v) == 0 {
+				return false
+			}
+		}
+		return true
+	case "like":
+		return like(fmt.Sprint(actual), fmt.Sprint(c.values[0]))
+	}
+
+	expected := c.values[0]
+	if actual == nil || expected == nil {
+		switch c.op {
+		case "=":
+			return actual == nil && expected == nil
+		case "!=", "<>":
+			return (actual == nil) != (expected == nil)
+		}
+		return false
+	}
+
+	result := compare(actual, expected)
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// compare orders two values numerically when both are numbers and case-insensitively otherwise.
+func compare(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	af, aErr := strconv.ParseFloat(fmt.Sprint(a), 64)
+	bf, bErr := strconv.ParseFloat(fmt.Sprint(b), 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
+
+// like implements SOQL LIKE matching where '%' matches any sequence and '_' matches a single character.
+func like(value, pattern string) bool {
+	value, pattern = strings.ToLower(value), strings.ToLower(pattern)
+	if pattern == "" {
+		return value == ""
+	}
+	switch pattern[0] {
+	case '%':
+		for i := 0; i <= len(value); i++ {
+			if like(value[i:], pattern[1:]) {
+				return true
+			}
+		}
+		return false
+	case '_':
+		return value != "" && like(value[1:], pattern[1:])
+	}
+	return value != "" && value[0] == pattern[0] && like(value[1:], pattern[1:])
+}
+
+// less reports whether the record resolved by a sorts before the record resolved by b per the ORDER BY clause.
+func (q *query) less(a, b func(string) interface{}) bool {
+	for _, o := range q.orderBy {
+		c := compare(a(o.field), b(o.field))
+		if c == 0 {
+			continue
+		}
+		if o.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// token kinds
+const (
+	tokenIdent = iota
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+// parser parses a SOQL statement from its tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parseSOQL(soql string) (*query, error) {
+	tokens, err := tokenize(soql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parse()
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, value: b.String()})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".:-+TZ", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		case strings.ContainsRune("!<>", r) && i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '>'):
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("(),=<>", r):
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it's the supplied case-insensitive keyword.
+func (p *parser) keyword(k string) bool {
+	t := p.peek()
+	if t != nil && t.kind == tokenIdent && strings.EqualFold(t.value, k) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) symbol(s string) bool {
+	t := p.peek()
+	if t != nil && t.kind == tokenSymbol && t.value == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t == nil || t.kind != tokenIdent {
+		return "", fmt.Errorf("expected identifier")
+	}
+	return t.value, nil
+}
+
+func (p *parser) parse() (*query, error) {
+	q := &query{limit: -1}
+	if !p.keyword("select") {
+		return nil, fmt.Errorf("expected SELECT")
+	}
+
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(name, "count") && p.symbol("(") {
+			if !p.symbol(")") {
+				return nil, fmt.Errorf("expected ')'")
+			}
+			q.count = true
+		} else {
+			q.fields = append(q.fields, name)
+		}
+		if !p.symbol(",") {
+			break
+		}
+	}
+
+	if !p.keyword("from") {
+		return nil, fmt.Errorf("expected FROM")
+	}
+	from, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	q.from = from
+
+	if p.keyword("where") {
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("order") {
+		if !p.keyword("by") {
+			return nil, fmt.Errorf("expected BY")
+		}
+		for {
+			field, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			o := ordering{field: field}
+			if p.keyword("desc") {
+				o.desc = true
+			} else {
+				p.keyword("asc")
+			}
+			if p.keyword("nulls") && !p.keyword("first") && !p.keyword("last") {
+				return nil, fmt.Errorf("expected FIRST or LAST")
+			}
+			q.orderBy = append(q.orderBy, o)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("limit") {
+		if q.limit, err = p.integer(); err != nil {
+			return nil, err
+		}
+	}
+	if p.keyword("offset") {
+		if q.offset, err = p.integer(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t := p.peek(); t != nil {
+		return nil, fmt.Errorf("unexpected token '%s'", t.value)
+	}
+	return q, nil
+}
+
+func (p *parser) integer() (int, error) {
+	t := p.next()
+	if t == nil || t.kind != tokenNumber {
+		return 0, fmt.Errorf("expected number")
+	}
+	return strconv.Atoi(t.value)
+}
+
+func (p *parser) parseOr() (condition, error) {
+	or := orCondition{}
+	for {
+		c, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, c)
+		if !p.keyword("or") {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	and := andCondition{}
+	for {
+		c, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, c)
+		if !p.keyword("and") {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *parser) parseCondition() (condition, error) {
+	if p.symbol("(") {
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			return nil, fmt.Errorf("expected ')'")
+		}
+		return c, nil
+	}
+
+	field, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	c := &comparison{field: field}
+
+	switch {
+	case p.keyword("in"):
+		c.op = "in"
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, fmt.Errorf("expected IN")
+		}
+		c.op = "not in"
+	case p.keyword("like"):
+		c.op = "like"
+	default:
+		t := p.next()
+		if t == nil || t.kind != tokenSymbol {
+			return nil, fmt.Errorf("expected operator after %s", field)
+		}
+		c.op = t.value
+	}
+
+	if c.op == "in" || c.op == "not in" {
+		if !p.symbol("(") {
+			return nil, fmt.Errorf("expected '('")
+		}
+		for {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+			if !p.symbol(",") {
+				break
+			}
+		}
+		if !p.symbol(")") {
+			return nil, fmt.Errorf("expected ')'")
+		}
+		return c, nil
+	}
+
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	c.values = []interface{}{v}
+	return c, nil
+}
+
+func (p *parser) value() (interface{}, error) {
+	t := p.next()
+	if t == nil {
+		return nil, fmt.Errorf("expected value")
+	}
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return t.value, nil
+	case tokenIdent:
+		switch strings.ToLower(t.value) {
+		case "null":
+			return nil, nil
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value '%s'", t.value)
+}
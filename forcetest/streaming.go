@@ -0,0 +1,416 @@
+package forcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStreamingTimeout is how long the fake Bayeux server holds a connect request open waiting for events.
+const DefaultStreamingTimeout = 110 * time.Second
+
+// streamingChannelPrefixes are the channel types accepted by the fake Bayeux server.
+var streamingChannelPrefixes = []string{"/topic/", "/event/", "/data/", "/u/"}
+
+// bayeux is the state of the fake Streaming API. It has its own lock so connect requests can wait for events
+// without blocking the rest of the server.
+type bayeux struct {
+	mu sync.Mutex
+
+	// timeout is how long connect requests wait for events.
+	timeout time.Duration
+
+	// clients holds handshaken clients keyed by client ID.
+	clients map[string]*bayeuxClient
+
+	// events holds every published event per channel for replay.
+	events map[string][]*bayeuxEvent
+
+	// sequence is used to generate client IDs.
+	sequence int
+
+	// replayID is the replay ID of the last published event.
+	replayID int64
+
+	// closed is closed when the server shuts down, releasing pending connect requests.
+	closed chan struct{}
+}
+
+type bayeuxClient struct {
+	subscriptions map[string]bool
+	queue         []*force.StreamingMessage
+	notify        chan struct{}
+}
+
+type bayeuxEvent struct {
+	replayID int64
+	message  *force.StreamingMessage
+}
+
+func newBayeux() *bayeux {
+	return &bayeux{
+		timeout: DefaultStreamingTimeout,
+		clients: map[string]*bayeuxClient{},
+		events:  map[string][]*bayeuxEvent{},
+		closed:  make(chan struct{}),
+	}
+}
+
+// Close shuts down the server, releasing any pending streaming connect requests first.
+func (s *Server) Close() {
+	s.streaming.mu.Lock()
+	select {
+	case <-s.streaming.closed:
+	default:
+		close(s.streaming.closed)
+	}
+	s.streaming.mu.Unlock()
+	s.Server.Close()
+}
+
+// SetStreamingTimeout sets how long connect requests are held open waiting for events.
+func (s *Server) SetStreamingTimeout(timeout time.Duration) {
+	s.streaming.mu.Lock()
+	defer s.streaming.mu.Unlock()
+	s.streaming.timeout = timeout
+}
+
+// Publish delivers an event to every client subscribed to the channel and retains it for replay.
+//
+// The data is sent as the Bayeux message data after its `event.replayId` and `event.createdDate` are set.
+// The replay ID of the event is returned.
+func (s *Server) Publish(channel string, data map[string]interface{}) int64 {
+	b := s.streaming
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replayID++
+	payload := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		payload[k] = v
+	}
+	event := map[string]interface{}{}
+	if e, ok := data["event"].(map[string]interface{}); ok {
+		for k, v := range e {
+			event[k] = v
+		}
+	}
+	event["replayId"] = b.replayID
+	event["createdDate"] = timestamp(time.Now())
+	payload["event"] = event
+
+	raw, _ := json.Marshal(payload)
+	e := &bayeuxEvent{replayID: b.replayID, message: &force.StreamingMessage{Channel: channel, Data: raw}}
+	b.events[channel] = append(b.events[channel], e)
+
+	for _, c := range b.clients {
+		if c.subscriptions[channel] {
+			c.enqueue(e.message)
+		}
+	}
+	return b.replayID
+}
+
+// PublishPushTopic publishes a PushTopic event of the type, such as `created`, for the record on
+// `/topic/{topicName}`.
+func (s *Server) PublishPushTopic(topicName, eventType string, record force.SObject) int64 {
+	return s.Publish("/topic/"+topicName, map[string]interface{}{
+		"event":   map[string]interface{}{"type": eventType},
+		"sobject": copyRecord(record),
+	})
+}
+
+// DropStreamingClients forgets every handshaken client, as happens when a Salesforce session expires.
+// The next connect request of each client is answered with advice to handshake again.
+func (s *Server) DropStreamingClients() {
+	b := s.streaming
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, c := range b.clients {
+		delete(b.clients, id)
+		c.wake()
+	}
+}
+
+// Subscribers returns the number of clients subscribed to a channel.
+func (s *Server) Subscribers(channel string) int {
+	b := s.streaming
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, c := range b.clients {
+		if c.subscriptions[channel] {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *bayeuxClient) enqueue(m *force.StreamingMessage) {
+	c.queue = append(c.queue, m)
+	c.wake()
+}
+
+func (c *bayeuxClient) wake() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) handleStreaming(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+		return
+	}
+
+	var messages []*force.StreamingMessage
+	if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
+		writeErrors(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+		return
+	}
+
+	replies := make([]*force.StreamingMessage, 0, len(messages))
+	for _, m := range messages {
+		switch m.Channel {
+		case "/meta/handshake":
+			replies = append(replies, s.streaming.handshake(m))
+		case "/meta/connect":
+			replies = append(replies, s.streaming.connect(r, m)...)
+		case "/meta/subscribe":
+			replies = append(replies, s.streaming.subscribe(m))
+		case "/meta/unsubscribe":
+			replies = append(replies, s.streaming.unsubscribe(m))
+		case "/meta/disconnect":
+			replies = append(replies, s.streaming.disconnect(m))
+		default:
+			replies = append(replies, bayeuxFailure(m, "403::Publishing is not supported"))
+		}
+	}
+	writeJSON(w, http.StatusOK, replies)
+}
+
+func (b *bayeux) handshake(m *force.StreamingMessage) *force.StreamingMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequence++
+	id := fmt.Sprintf("fake-client-%d", b.sequence)
+	b.clients[id] = &bayeuxClient{subscriptions: map[string]bool{}, notify: make(chan struct{}, 1)}
+
+	return &force.StreamingMessage{
+		Channel:                  m.Channel,
+		ID:                       m.ID,
+		ClientID:                 id,
+		Successful:               true,
+		Version:                  "1.0",
+		SupportedConnectionTypes: []string{"long-polling"},
+		Advice:                   b.advice(force.StreamingReconnects.Retry),
+	}
+}
+
+// connect waits until the client has queued events or the timeout elapses, then returns the events followed
+// by the connect reply.
+func (b *bayeux) connect(r *http.Request, m *force.StreamingMessage) []*force.StreamingMessage {
+	b.mu.Lock()
+	c, ok := b.clients[m.ClientID]
+	timeout := b.timeout
+	b.mu.Unlock()
+	if !ok {
+		return []*force.StreamingMessage{b.unknownClient(m)}
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for {
+		b.mu.Lock()
+		if b.clients[m.ClientID] != c {
+			b.mu.Unlock()
+			return []*force.StreamingMessage{b.unknownClient(m)}
+		}
+		if len(c.queue) > 0 {
+			replies := append(c.queue, b.connected(m))
+			c.queue = nil
+			b.mu.Unlock()
+			return replies
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-t.C:
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			return []*force.StreamingMessage{b.connected(m)}
+		case <-r.Context().Done():
+			return nil
+		case <-b.closed:
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			return []*force.StreamingMessage{b.connected(m)}
+		}
+	}
+}
+
+// connected returns a successful connect reply. The caller must hold b.mu.
+func (b *bayeux) connected(m *force.StreamingMessage) *force.StreamingMessage {
+	return &force.StreamingMessage{
+		Channel:    m.Channel,
+		ID:         m.ID,
+		ClientID:   m.ClientID,
+		Successful: true,
+		Advice:     b.advice(force.StreamingReconnects.Retry),
+	}
+}
+
+func (b *bayeux) subscribe(m *force.StreamingMessage) *force.StreamingMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.clients[m.ClientID]
+	if !ok {
+		return b.unknownClient(m)
+	}
+	if !validStreamingChannel(m.Subscription) {
+		return bayeuxFailure(m, fmt.Sprintf("403::Unknown channel %s", m.Subscription))
+	}
+
+	replayID := force.ReplayNew
+	if replay, ok := m.Ext["replay"].(map[string]interface{}); ok {
+		if v, ok := replay[m.Subscription].(float64); ok {
+			replayID = int64(v)
+		}
+	}
+
+	c.subscriptions[m.Subscription] = true
+	if replayID != force.ReplayNew {
+		for _, e := range b.events[m.Subscription] {
+			if replayID == force.ReplayAll || e.replayID > replayID {
+				c.enqueue(e.message)
+			}
+		}
+	}
+
+	reply := bayeuxFailure(m, "")
+	reply.Successful = true
+	return reply
+}
+
+func (b *bayeux) unsubscribe(m *force.StreamingMessage) *force.StreamingMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.clients[m.ClientID]
+	if !ok {
+		return b.unknownClient(m)
+	}
+	delete(c.subscriptions, m.Subscription)
+
+	reply := bayeuxFailure(m, "")
+	reply.Successful = true
+	return reply
+}
+
+func (b *bayeux) disconnect(m *force.StreamingMessage) *force.StreamingMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.clients[m.ClientID]; ok {
+		delete(b.clients, m.ClientID)
+		c.wake()
+	}
+	return &force.StreamingMessage{Channel: m.Channel, ID: m.ID, ClientID: m.ClientID, Successful: true}
+}
+
+// advice returns reconnect advice. The caller must hold b.mu.
+func (b *bayeux) advice(reconnect string) *force.StreamingAdvice {
+	return &force.StreamingAdvice{
+		Reconnect: reconnect,
+		Interval:  0,
+		Timeout:   int(b.timeout / time.Millisecond),
+	}
+}
+
+func (b *bayeux) unknownClient(m *force.StreamingMessage) *force.StreamingMessage {
+	reply := bayeuxFailure(m, "403::Unknown client")
+	reply.Advice = &force.StreamingAdvice{Reconnect: force.StreamingReconnects.Handshake}
+	return reply
+}
+
+func bayeuxFailure(m *force.StreamingMessage, message string) *force.StreamingMessage {
+	return &force.StreamingMessage{
+		Channel:      m.Channel,
+		ID:           m.ID,
+		ClientID:     m.ClientID,
+		Subscription: m.Subscription,
+		Error:        message,
+	}
+}
+
+func validStreamingChannel(channel string) bool {
+	for _, prefix := range streamingChannelPrefixes {
+		if strings.HasPrefix(channel, prefix) && len(channel) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishChange publishes a Change Data Capture event of the change type, such as `UPDATE`, for records of a
+// SObject on its change event channel, such as `/data/AccountChangeEvent`. The fields are sent alongside the
+// ChangeEventHeader and, for updates, listed as its changed fields.
+func (s *Server) PublishChange(objectName, changeType string, recordIDs []string, fields force.SObject) int64 {
+	changed := make([]string, 0, len(fields))
+	for k := range fields {
+		changed = append(changed, k)
+	}
+	sort.Strings(changed)
+
+	payload := copyRecord(fields)
+	payload["ChangeEventHeader"] = &force.ChangeEventHeader{
+		EntityName:      objectName,
+		RecordIDs:       recordIDs,
+		ChangeType:      changeType,
+		ChangeOrigin:    "com/salesforce/api/rest/" + strings.TrimPrefix(force.DefaultAPIVersion, "v"),
+		TransactionKey:  fmt.Sprintf("fake-transaction-%d", time.Now().UnixNano()),
+		SequenceNumber:  1,
+		CommitTimestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		CommitUser:      UserID,
+		ChangedFields:   changed,
+	}
+
+	return s.Publish(force.ChangeEventChannel(objectName), map[string]interface{}{
+		"schema":  "fake-schema-" + strings.ToLower(objectName),
+		"payload": payload,
+	})
+}
+
+// isPlatformEvent returns whether the SObject is a platform event, whose records are published rather than
+// stored.
+func isPlatformEvent(meta *force.SObjectMetadata) bool {
+	return strings.HasSuffix(strings.ToLower(meta.GetName()), force.PlatformEventSuffix)
+}
+
+// publishEvent publishes a platform event record on `/event/{name}` and returns its ID and replay ID. Unlike
+// Salesforce, the fake includes the replay ID in publish responses. The caller must hold s.mu.
+func (s *Server) publishEvent(meta *force.SObjectMetadata, fields force.SObject) (string, int64) {
+	s.sequence++
+	id := fmt.Sprintf("e00%012s", strconv.FormatInt(int64(s.sequence), 36))
+	id += idSuffix(id)
+
+	payload := force.SObject{"CreatedDate": timestamp(time.Now()), "CreatedById": UserID}
+	for k, v := range fields {
+		payload[s.fieldName(meta, k)] = v
+	}
+
+	return id, s.Publish("/event/"+meta.GetName(), map[string]interface{}{
+		"schema":  "fake-schema-" + strings.ToLower(meta.GetName()),
+		"payload": payload,
+	})
+}
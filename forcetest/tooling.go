@@ -0,0 +1,71 @@
+package forcetest
+
+import (
+	"github.com/davidji99/force-go/force"
+	"net/http"
+)
+
+// AnonymousApexFunc returns the result of executing anonymous Apex on the fake server.
+type AnonymousApexFunc func(apex string) *force.ExecuteAnonymousResult
+
+// SetAnonymousApex sets the function emulating anonymous Apex execution. By default all Apex compiles and runs
+// successfully.
+func (s *Server) SetAnonymousApex(fn AnonymousApexFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.anonymousApex = fn
+}
+
+// handleTooling serves the Tooling API. Tooling objects share the server's record store, so they are registered
+// with AddSObject like any other SObject.
+func (s *Server) handleTooling(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+
+	switch segments[0] {
+	case "sobjects":
+		s.handleSObjects(w, r, segments[1:])
+	case "query":
+		s.handleQuery(w, r, segments[1:])
+	case "executeAnonymous":
+		if r.Method != http.MethodGet {
+			writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+			return
+		}
+		s.handleExecuteAnonymous(w, r)
+	case "runTestsAsynchronous":
+		if r.Method != http.MethodPost {
+			writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+			return
+		}
+		s.handleRunTestsAsynchronous(w, r)
+	case "runTestsSynchronous":
+		if r.Method != http.MethodPost {
+			writeErrors(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method not allowed")
+			return
+		}
+		s.handleRunTestsSynchronous(w, r)
+	default:
+		writeErrors(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+func (s *Server) handleExecuteAnonymous(w http.ResponseWriter, r *http.Request) {
+	apex := r.URL.Query().Get("anonymousBody")
+	if apex == "" {
+		writeErrors(w, http.StatusBadRequest, "MISSING_ARGUMENT", "anonymousBody must be specified")
+		return
+	}
+
+	s.mu.Lock()
+	fn := s.anonymousApex
+	s.mu.Unlock()
+
+	result := &force.ExecuteAnonymousResult{Line: -1, Column: -1, Compiled: true, Success: true}
+	if fn != nil {
+		result = fn(apex)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
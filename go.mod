@@ -4,6 +4,7 @@ go 1.17
 
 require (
 	github.com/davidji99/simpleresty v0.2.3
+	github.com/go-resty/resty/v2 v2.2.0
 	github.com/mitchellh/mapstructure v1.4.0
 	github.com/stretchr/testify v1.4.0
 )
@@ -11,7 +12,6 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/davidji99/go-querystring v1.0.2 // indirect
-	github.com/go-resty/resty/v2 v2.2.0 // indirect
 	github.com/golang/protobuf v1.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.0.0-20200222125558-5a598a2470a0 // indirect
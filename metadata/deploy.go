@@ -0,0 +1,307 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TestLevels represents all test levels of a deploy.
+var TestLevels = struct {
+	NoTestRun         string
+	RunSpecifiedTests string
+	RunLocalTests     string
+	RunAllTestsInOrg  string
+}{
+	NoTestRun:         "NoTestRun",
+	RunSpecifiedTests: "RunSpecifiedTests",
+	RunLocalTests:     "RunLocalTests",
+	RunAllTestsInOrg:  "RunAllTestsInOrg",
+}
+
+// DeployStatuses represents all statuses of a deploy.
+var DeployStatuses = struct {
+	Pending          string
+	InProgress       string
+	Succeeded        string
+	SucceededPartial string
+	Failed           string
+	Canceling        string
+	Canceled         string
+}{
+	Pending:          "Pending",
+	InProgress:       "InProgress",
+	Succeeded:        "Succeeded",
+	SucceededPartial: "SucceededPartial",
+	Failed:           "Failed",
+	Canceling:        "Canceling",
+	Canceled:         "Canceled",
+}
+
+// DeployOptions represents the options of a deploy. Fields are listed in the order the WSDL requires.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/meta_deploy.htm
+type DeployOptions struct {
+	AllowMissingFiles bool `xml:"allowMissingFiles,omitempty"`
+	AutoUpdatePackage bool `xml:"autoUpdatePackage,omitempty"`
+
+	// CheckOnly validates the deploy and runs its tests without saving any components.
+	CheckOnly bool `xml:"checkOnly,omitempty"`
+
+	IgnoreWarnings  bool `xml:"ignoreWarnings,omitempty"`
+	PerformRetrieve bool `xml:"performRetrieve,omitempty"`
+	PurgeOnDelete   bool `xml:"purgeOnDelete,omitempty"`
+
+	// RollbackOnError fails the whole deploy if any component fails. Production orgs always roll back.
+	RollbackOnError bool `xml:"rollbackOnError,omitempty"`
+
+	// RunTests lists the test classes to run when TestLevel is RunSpecifiedTests.
+	RunTests []string `xml:"runTests,omitempty"`
+
+	// SinglePackage indicates the zip holds a single package with package.xml at its root.
+	SinglePackage bool `xml:"singlePackage,omitempty"`
+
+	// TestLevel is one of the TestLevels. Defaults to NoTestRun in sandboxes and RunLocalTests in production.
+	TestLevel string `xml:"testLevel,omitempty"`
+}
+
+// DeployResult represents the status of a deploy.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/meta_deployresult.htm
+type DeployResult struct {
+	ID                       string         `xml:"id"`
+	Done                     bool           `xml:"done"`
+	Status                   string         `xml:"status"`
+	Success                  bool           `xml:"success"`
+	CheckOnly                bool           `xml:"checkOnly"`
+	IgnoreWarnings           bool           `xml:"ignoreWarnings"`
+	RollbackOnError          bool           `xml:"rollbackOnError"`
+	NumberComponentsTotal    int            `xml:"numberComponentsTotal"`
+	NumberComponentsDeployed int            `xml:"numberComponentsDeployed"`
+	NumberComponentErrors    int            `xml:"numberComponentErrors"`
+	NumberTestsTotal         int            `xml:"numberTestsTotal"`
+	NumberTestsCompleted     int            `xml:"numberTestsCompleted"`
+	NumberTestErrors         int            `xml:"numberTestErrors"`
+	StateDetail              string         `xml:"stateDetail,omitempty"`
+	ErrorStatusCode          string         `xml:"errorStatusCode,omitempty"`
+	ErrorMessage             string         `xml:"errorMessage,omitempty"`
+	CreatedDate              time.Time      `xml:"createdDate"`
+	CompletedDate            time.Time      `xml:"completedDate"`
+	Details                  *DeployDetails `xml:"details,omitempty"`
+}
+
+// DeployDetails holds the per component and test results of a deploy.
+type DeployDetails struct {
+	ComponentFailures  []*DeployMessage `xml:"componentFailures"`
+	ComponentSuccesses []*DeployMessage `xml:"componentSuccesses"`
+	RunTestResult      *RunTestsResult  `xml:"runTestResult,omitempty"`
+}
+
+// DeployMessage represents the result of deploying a single component.
+type DeployMessage struct {
+	Changed       bool   `xml:"changed"`
+	ColumnNumber  int    `xml:"columnNumber,omitempty"`
+	ComponentType string `xml:"componentType"`
+	Created       bool   `xml:"created"`
+	Deleted       bool   `xml:"deleted"`
+	FileName      string `xml:"fileName"`
+	FullName      string `xml:"fullName"`
+	ID            string `xml:"id,omitempty"`
+	LineNumber    int    `xml:"lineNumber,omitempty"`
+	Problem       string `xml:"problem,omitempty"`
+	ProblemType   string `xml:"problemType,omitempty"`
+	Success       bool   `xml:"success"`
+}
+
+// RunTestsResult represents the Apex tests run by a deploy.
+type RunTestsResult struct {
+	NumFailures          int                    `xml:"numFailures"`
+	NumTestsRun          int                    `xml:"numTestsRun"`
+	TotalTime            float64                `xml:"totalTime"`
+	Failures             []*RunTestFailure      `xml:"failures"`
+	Successes            []*RunTestSuccess      `xml:"successes"`
+	CodeCoverageWarnings []*CodeCoverageWarning `xml:"codeCoverageWarnings"`
+}
+
+// RunTestFailure represents a failed Apex test method.
+type RunTestFailure struct {
+	ID         string  `xml:"id"`
+	Name       string  `xml:"name"`
+	MethodName string  `xml:"methodName"`
+	Message    string  `xml:"message"`
+	StackTrace string  `xml:"stackTrace"`
+	Time       float64 `xml:"time"`
+}
+
+// RunTestSuccess represents a passing Apex test method.
+type RunTestSuccess struct {
+	ID         string  `xml:"id"`
+	Name       string  `xml:"name"`
+	MethodName string  `xml:"methodName"`
+	Time       float64 `xml:"time"`
+}
+
+// CodeCoverageWarning represents a class or trigger without enough code coverage.
+type CodeCoverageWarning struct {
+	Name    string `xml:"name"`
+	Message string `xml:"message"`
+}
+
+// DeployError represents a deploy that finished without succeeding.
+type DeployError struct {
+	// ID is the ID of the deploy.
+	ID string
+
+	// Status is the final status of the deploy, such as `Failed`.
+	Status string
+
+	// Message is the error message of a deploy that failed as a whole.
+	Message string
+
+	// ComponentFailures holds the components that failed to deploy.
+	ComponentFailures []*DeployMessage
+
+	// TestFailures holds the Apex test methods that failed.
+	TestFailures []*RunTestFailure
+}
+
+// Error summarizes the failures of the deploy.
+func (e *DeployError) Error() string {
+	problems := make([]string, 0)
+	if e.Message != "" {
+		problems = append(problems, e.Message)
+	}
+	for _, f := range e.ComponentFailures {
+		location := f.FileName
+		if f.LineNumber > 0 {
+			location += fmt.Sprintf(" line %d", f.LineNumber)
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", location, f.Problem))
+	}
+	for _, f := range e.TestFailures {
+		problems = append(problems, fmt.Sprintf("%s.%s: %s", f.Name, f.MethodName, f.Message))
+	}
+	return fmt.Sprintf("deploy %s %s: %s", e.ID, e.Status, strings.Join(problems, "; "))
+}
+
+// Err returns a *DeployError if the deploy finished without fully succeeding, otherwise nil.
+func (r *DeployResult) Err() error {
+	if !r.Done || r.Status == DeployStatuses.Succeeded {
+		return nil
+	}
+	err := &DeployError{ID: r.ID, Status: r.Status, Message: r.ErrorMessage}
+	if r.Details != nil {
+		err.ComponentFailures = r.Details.ComponentFailures
+		if r.Details.RunTestResult != nil {
+			err.TestFailures = r.Details.RunTestResult.Failures
+		}
+	}
+	return err
+}
+
+type deployRequest struct {
+	XMLName       xml.Name       `xml:"http://soap.sforce.com/2006/04/metadata deploy"`
+	ZipFile       string         `xml:"ZipFile"`
+	DeployOptions *DeployOptions `xml:"DeployOptions"`
+}
+
+type checkDeployStatusRequest struct {
+	XMLName        xml.Name `xml:"http://soap.sforce.com/2006/04/metadata checkDeployStatus"`
+	AsyncProcessID string   `xml:"asyncProcessId"`
+	IncludeDetails bool     `xml:"includeDetails"`
+}
+
+// StartDeploy starts deploying a zip file of components and returns without waiting for it to finish.
+func (c *Client) StartDeploy(zipFile []byte, opts *DeployOptions) (*AsyncResult, error) {
+	if opts == nil {
+		opts = &DeployOptions{}
+	}
+
+	var response struct {
+		Result *AsyncResult `xml:"result"`
+	}
+	err := c.call(&deployRequest{ZipFile: base64.StdEncoding.EncodeToString(zipFile), DeployOptions: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// CheckDeployStatus returns the status of a deploy. If includeDetails is true, the component and test results
+// are included.
+func (c *Client) CheckDeployStatus(id string, includeDetails bool) (*DeployResult, error) {
+	var response struct {
+		Result *DeployResult `xml:"result"`
+	}
+	err := c.call(&checkDeployStatusRequest{AsyncProcessID: id, IncludeDetails: includeDetails}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// Deploy deploys a zip file of components and polls its status until it's done. If the deploy doesn't succeed,
+// the result is returned along with a *DeployError describing the failures.
+func (c *Client) Deploy(zipFile []byte, opts *DeployOptions, pollOpts ...force.PollOption) (*DeployResult, error) {
+	async, err := c.StartDeploy(zipFile, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *DeployResult
+	pollErr := poll(pollOpts, func() (bool, error) {
+		var checkErr error
+		result, checkErr = c.CheckDeployStatus(async.ID, true)
+		if checkErr != nil {
+			return false, checkErr
+		}
+		return result.Done, nil
+	})
+	if pollErr != nil {
+		return result, pollErr
+	}
+	return result, result.Err()
+}
+
+// ZipDir zips the files of a directory, such as one holding package.xml and the component folders, for deploying.
+// Paths in the zip are relative to the directory.
+func ZipDir(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		content, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			return readErr
+		}
+		f, createErr := w.Create(filepath.ToSlash(rel))
+		if createErr != nil {
+			return createErr
+		}
+		_, writeErr := f.Write(content)
+		return writeErr
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
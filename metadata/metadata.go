@@ -0,0 +1,194 @@
+// Package metadata provides a client for the Salesforce Metadata API.
+//
+// The Metadata API is only available over SOAP. The Client builds and parses the SOAP envelopes internally and
+// authenticates with the session of an existing force.Client:
+//
+//	c, err := force.New(force.InstanceURL(instanceURL), force.AccessToken(token))
+//	m, err := metadata.New(c)
+//
+//	result, err := m.Deploy(zipFile, &metadata.DeployOptions{CheckOnly: true, TestLevel: metadata.TestLevels.RunLocalTests})
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/meta_intro.htm
+package metadata
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/simpleresty"
+	"strings"
+	"time"
+)
+
+const (
+	// Namespace is the XML namespace of the Metadata API.
+	Namespace = "http://soap.sforce.com/2006/04/metadata"
+
+	// EnvelopeNamespace is the XML namespace of SOAP 1.1 envelopes.
+	EnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+
+	// DefaultTimeout is the timeout of a single Metadata API request.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Client manages communication with the Salesforce Metadata API.
+type Client struct {
+	// force is the REST client whose session is used to authenticate.
+	force *force.Client
+
+	// http is used to send the SOAP requests.
+	http *simpleresty.Client
+
+	// url is the Metadata API SOAP endpoint.
+	url string
+
+	// apiVersion is the API version without the 'v' prefix, such as `50.0`.
+	apiVersion string
+}
+
+// Option is a functional option for configuring the Metadata API client.
+type Option func(*Client) error
+
+// Timeout sets the timeout of a single Metadata API request. Defaults to DefaultTimeout.
+func Timeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		c.http.SetTimeout(timeout)
+		return nil
+	}
+}
+
+// New returns a Metadata API client that uses the instance URL, session, API version and transport of the
+// supplied force.Client.
+func New(fc *force.Client, opts ...Option) (*Client, error) {
+	if fc == nil {
+		return nil, fmt.Errorf("force client cannot be nil")
+	}
+
+	h := simpleresty.NewWithBaseURL(fc.InstanceURL())
+	h.SetHeader("Content-Type", "text/xml; charset=UTF-8").
+		SetHeader("SOAPAction", `""`).
+		SetHeader("User-Agent", fc.UserAgent()).
+		SetTimeout(DefaultTimeout)
+	if fc.Transport() != nil {
+		h.SetTransport(fc.Transport())
+	}
+
+	version := strings.TrimPrefix(fc.APIVersion(), "v")
+	c := &Client{
+		force:      fc,
+		http:       h,
+		url:        h.RequestURL(fmt.Sprintf("/services/Soap/m/%s", version)),
+		apiVersion: version,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// APIVersion returns the Metadata API version, such as `50.0`.
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// Fault represents a SOAP fault returned by the Metadata API, such as an invalid session.
+type Fault struct {
+	// Code is the fault code, such as `sf:INVALID_SESSION_ID`.
+	Code string `xml:"faultcode"`
+
+	// Message describes the fault.
+	Message string `xml:"faultstring"`
+}
+
+// Error returns the fault code and message.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%s: %s", f.Code, f.Message)
+}
+
+type envelope struct {
+	XMLName xml.Name       `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+	Header  envelopeHeader `xml:"http://schemas.xmlsoap.org/soap/envelope/ Header"`
+	Body    envelopeBody   `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+}
+
+type envelopeHeader struct {
+	SessionHeader sessionHeader
+}
+
+type sessionHeader struct {
+	XMLName   xml.Name `xml:"http://soap.sforce.com/2006/04/metadata SessionHeader"`
+	SessionID string   `xml:"sessionId"`
+}
+
+type envelopeBody struct {
+	Request interface{}
+}
+
+type responseEnvelope struct {
+	Body struct {
+		Fault    *Fault `xml:"Fault"`
+		Response struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// call sends a SOAP request and decodes the children of the response element into result.
+func (c *Client) call(request, result interface{}) error {
+	body, marshalErr := xml.Marshal(&envelope{
+		Header: envelopeHeader{SessionHeader: sessionHeader{SessionID: c.force.AccessToken()}},
+		Body:   envelopeBody{Request: request},
+	})
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	response, postErr := c.http.R().SetBody(append([]byte(xml.Header), body...)).Post(c.url)
+	if postErr != nil {
+		return postErr
+	}
+
+	var env responseEnvelope
+	if err := xml.Unmarshal(response.Body(), &env); err != nil {
+		return fmt.Errorf("unable to decode Metadata API response (%s): %v", response.Status(), err)
+	}
+	if env.Body.Fault != nil {
+		return env.Body.Fault
+	}
+	if response.StatusCode() >= 300 {
+		return fmt.Errorf("unexpected Metadata API response: %s", response.Status())
+	}
+
+	if result == nil {
+		return nil
+	}
+	return unmarshalInner(env.Body.Response.Inner, result)
+}
+
+// unmarshalInner decodes the inner XML of an element, which may hold several sibling elements, into v.
+func unmarshalInner(inner []byte, v interface{}) error {
+	return xml.Unmarshal(append(append([]byte("<inner>"), inner...), "</inner>"...), v)
+}
+
+// AsyncResult represents the status of an asynchronous deploy or retrieve.
+type AsyncResult struct {
+	ID         string `xml:"id"`
+	Done       bool   `xml:"done"`
+	State      string `xml:"state"`
+	StatusCode string `xml:"statusCode"`
+	Message    string `xml:"message"`
+}
+
+// poll calls check with a poller built from the options until check reports the operation is done.
+func poll(opts []force.PollOption, check func() (bool, error)) error {
+	poller, err := force.NewPoller(opts...)
+	if err != nil {
+		return err
+	}
+	return poller.Poll(check)
+}
@@ -0,0 +1,168 @@
+package metadata
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// MaxListMetadataQueries is the maximum number of queries in a single listMetadata call.
+	MaxListMetadataQueries = 3
+
+	// MaxReadMetadata is the maximum number of components read in a single readMetadata call.
+	MaxReadMetadata = 10
+)
+
+// ListMetadataQuery represents a metadata type, and for folder based types its folder, to list.
+type ListMetadataQuery struct {
+	Folder string `xml:"folder,omitempty"`
+	Type   string `xml:"type"`
+}
+
+// FileProperties represents a component returned by listMetadata or retrieve.
+type FileProperties struct {
+	CreatedByID        string    `xml:"createdById"`
+	CreatedByName      string    `xml:"createdByName"`
+	CreatedDate        time.Time `xml:"createdDate"`
+	FileName           string    `xml:"fileName"`
+	FullName           string    `xml:"fullName"`
+	ID                 string    `xml:"id"`
+	LastModifiedByID   string    `xml:"lastModifiedById"`
+	LastModifiedByName string    `xml:"lastModifiedByName"`
+	LastModifiedDate   time.Time `xml:"lastModifiedDate"`
+	ManageableState    string    `xml:"manageableState,omitempty"`
+	NamespacePrefix    string    `xml:"namespacePrefix,omitempty"`
+	Type               string    `xml:"type"`
+}
+
+// CustomObject represents the metadata of a custom or standard object.
+type CustomObject struct {
+	FullName         string         `xml:"fullName"`
+	Label            string         `xml:"label"`
+	PluralLabel      string         `xml:"pluralLabel"`
+	Description      string         `xml:"description"`
+	DeploymentStatus string         `xml:"deploymentStatus"`
+	SharingModel     string         `xml:"sharingModel"`
+	NameField        *CustomField   `xml:"nameField"`
+	Fields           []*CustomField `xml:"fields"`
+}
+
+// CustomField represents the metadata of a field. Its full name is qualified by the object, such as
+// `Account.Region__c`, except when nested in a CustomObject.
+type CustomField struct {
+	FullName         string `xml:"fullName"`
+	Label            string `xml:"label"`
+	Type             string `xml:"type"`
+	Description      string `xml:"description"`
+	InlineHelpText   string `xml:"inlineHelpText"`
+	DefaultValue     string `xml:"defaultValue"`
+	Formula          string `xml:"formula"`
+	Length           int    `xml:"length"`
+	Precision        int    `xml:"precision"`
+	Scale            int    `xml:"scale"`
+	Required         bool   `xml:"required"`
+	Unique           bool   `xml:"unique"`
+	ExternalID       bool   `xml:"externalId"`
+	ReferenceTo      string `xml:"referenceTo"`
+	RelationshipName string `xml:"relationshipName"`
+}
+
+// ApexClass represents the metadata of an Apex class.
+type ApexClass struct {
+	FullName   string `xml:"fullName"`
+	APIVersion string `xml:"apiVersion"`
+	Status     string `xml:"status"`
+}
+
+// ApexTrigger represents the metadata of an Apex trigger.
+type ApexTrigger struct {
+	FullName   string `xml:"fullName"`
+	APIVersion string `xml:"apiVersion"`
+	Status     string `xml:"status"`
+}
+
+type listMetadataRequest struct {
+	XMLName     xml.Name             `xml:"http://soap.sforce.com/2006/04/metadata listMetadata"`
+	Queries     []*ListMetadataQuery `xml:"queries"`
+	AsOfVersion string               `xml:"asOfVersion"`
+}
+
+type readMetadataRequest struct {
+	XMLName   xml.Name `xml:"http://soap.sforce.com/2006/04/metadata readMetadata"`
+	Type      string   `xml:"type"`
+	FullNames []string `xml:"fullNames"`
+}
+
+type readMetadataRecord struct {
+	Type  string `xml:"type,attr"`
+	Inner []byte `xml:",innerxml"`
+}
+
+// ListMetadata lists the components matching the queries, splitting them into calls of at most
+// MaxListMetadataQueries.
+func (c *Client) ListMetadata(queries ...*ListMetadataQuery) ([]*FileProperties, error) {
+	properties := make([]*FileProperties, 0)
+	for start := 0; start < len(queries); start += MaxListMetadataQueries {
+		end := start + MaxListMetadataQueries
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		var response struct {
+			Result []*FileProperties `xml:"result"`
+		}
+		err := c.call(&listMetadataRequest{Queries: queries[start:end], AsOfVersion: c.apiVersion}, &response)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, response.Result...)
+	}
+	return properties, nil
+}
+
+// ReadMetadata reads components of a metadata type by full name and appends them to v, which must be a pointer
+// to a slice of structs such as *[]*CustomObject. Components that don't exist are skipped. Names are read in
+// calls of at most MaxReadMetadata.
+func (c *Client) ReadMetadata(metadataType string, fullNames []string, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("v must be a pointer to a slice, got %T", v)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+
+	for start := 0; start < len(fullNames); start += MaxReadMetadata {
+		end := start + MaxReadMetadata
+		if end > len(fullNames) {
+			end = len(fullNames)
+		}
+
+		var response struct {
+			Records []*readMetadataRecord `xml:"result>records"`
+		}
+		err := c.call(&readMetadataRequest{Type: metadataType, FullNames: fullNames[start:end]}, &response)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range response.Records {
+			if len(record.Inner) == 0 {
+				continue
+			}
+
+			elem := reflect.New(elemType)
+			target := elem.Interface()
+			if elemType.Kind() == reflect.Ptr {
+				elem.Elem().Set(reflect.New(elemType.Elem()))
+				target = elem.Elem().Interface()
+			}
+			if err := unmarshalInner(record.Inner, target); err != nil {
+				return fmt.Errorf("unable to decode %s: %v", metadataType, err)
+			}
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"io/ioutil"
+	"strings"
+)
+
+// Package represents a package.xml manifest listing components by type.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/manifest_samples.htm
+type Package struct {
+	Types   []*PackageTypeMembers `xml:"types"`
+	Version string                `xml:"version,omitempty"`
+}
+
+// PackageTypeMembers represents the members of a metadata type in a manifest. A member of `*` matches every
+// component of the type.
+type PackageTypeMembers struct {
+	Members []string `xml:"members"`
+	Name    string   `xml:"name"`
+}
+
+// NewPackage returns an empty manifest for the API version, such as `50.0`.
+func NewPackage(version string) *Package {
+	return &Package{Types: []*PackageTypeMembers{}, Version: version}
+}
+
+// Add adds members of a metadata type to the manifest.
+func (p *Package) Add(metadataType string, members ...string) *Package {
+	for _, t := range p.Types {
+		if t.Name == metadataType {
+			t.Members = append(t.Members, members...)
+			return p
+		}
+	}
+	p.Types = append(p.Types, &PackageTypeMembers{Members: members, Name: metadataType})
+	return p
+}
+
+// Members returns the members of a metadata type in the manifest.
+func (p *Package) Members(metadataType string) []string {
+	for _, t := range p.Types {
+		if strings.EqualFold(t.Name, metadataType) {
+			return t.Members
+		}
+	}
+	return nil
+}
+
+type packageFile struct {
+	XMLName xml.Name `xml:"http://soap.sforce.com/2006/04/metadata Package"`
+	*Package
+}
+
+// Bytes returns the manifest as the contents of a package.xml file.
+func (p *Package) Bytes() ([]byte, error) {
+	b, err := xml.MarshalIndent(&packageFile{Package: p}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// ParsePackage parses the contents of a package.xml file.
+func ParsePackage(b []byte) (*Package, error) {
+	var p Package
+	if err := xml.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("invalid package.xml: %v", err)
+	}
+	return &p, nil
+}
+
+// ReadPackage reads and parses a package.xml file.
+func ReadPackage(path string) (*Package, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePackage(b)
+}
+
+// RetrieveRequest represents the components to retrieve. Fields are listed in the order the WSDL requires.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/meta_retrieve_request.htm
+type RetrieveRequest struct {
+	APIVersion    string   `xml:"apiVersion,omitempty"`
+	PackageNames  []string `xml:"packageNames,omitempty"`
+	SinglePackage bool     `xml:"singlePackage"`
+	SpecificFiles []string `xml:"specificFiles,omitempty"`
+	Unpackaged    *Package `xml:"unpackaged,omitempty"`
+}
+
+// RetrieveStatuses represents all statuses of a retrieve.
+var RetrieveStatuses = struct {
+	Pending    string
+	InProgress string
+	Succeeded  string
+	Failed     string
+}{
+	Pending:    "Pending",
+	InProgress: "InProgress",
+	Succeeded:  "Succeeded",
+	Failed:     "Failed",
+}
+
+// RetrieveResult represents the status of a retrieve.
+//
+// Reference: https://developer.salesforce.com/docs/atlas.en-us.api_meta.meta/api_meta/meta_retrieveresult.htm
+type RetrieveResult struct {
+	Done            bool               `xml:"done"`
+	ErrorMessage    string             `xml:"errorMessage,omitempty"`
+	ErrorStatusCode string             `xml:"errorStatusCode,omitempty"`
+	FileProperties  []*FileProperties  `xml:"fileProperties"`
+	ID              string             `xml:"id"`
+	Messages        []*RetrieveMessage `xml:"messages"`
+	Status          string             `xml:"status"`
+	Success         bool               `xml:"success"`
+
+	// ZipFile is the base64 encoded zip of the retrieved components. Use Zip to decode it.
+	ZipFile string `xml:"zipFile,omitempty"`
+}
+
+// RetrieveMessage represents a warning about a component that couldn't be retrieved.
+type RetrieveMessage struct {
+	FileName string `xml:"fileName"`
+	Problem  string `xml:"problem"`
+}
+
+// Zip returns the decoded zip of the retrieved components.
+func (r *RetrieveResult) Zip() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(r.ZipFile)
+}
+
+// WriteZip writes the zip of the retrieved components to a file.
+func (r *RetrieveResult) WriteZip(path string) error {
+	b, err := r.Zip()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// Err returns an error if the retrieve failed, otherwise nil.
+func (r *RetrieveResult) Err() error {
+	if r.Status != RetrieveStatuses.Failed {
+		return nil
+	}
+	return fmt.Errorf("retrieve %s failed: %s: %s", r.ID, r.ErrorStatusCode, r.ErrorMessage)
+}
+
+type retrieveRequest struct {
+	XMLName         xml.Name         `xml:"http://soap.sforce.com/2006/04/metadata retrieve"`
+	RetrieveRequest *RetrieveRequest `xml:"retrieveRequest"`
+}
+
+type checkRetrieveStatusRequest struct {
+	XMLName        xml.Name `xml:"http://soap.sforce.com/2006/04/metadata checkRetrieveStatus"`
+	AsyncProcessID string   `xml:"asyncProcessId"`
+	IncludeZip     bool     `xml:"includeZip"`
+}
+
+// StartRetrieve starts retrieving components and returns without waiting for it to finish.
+func (c *Client) StartRetrieve(req *RetrieveRequest) (*AsyncResult, error) {
+	var response struct {
+		Result *AsyncResult `xml:"result"`
+	}
+	if err := c.call(&retrieveRequest{RetrieveRequest: req}, &response); err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// CheckRetrieveStatus returns the status of a retrieve. If includeZip is true, the zip of the retrieved
+// components is included once it's done.
+func (c *Client) CheckRetrieveStatus(id string, includeZip bool) (*RetrieveResult, error) {
+	var response struct {
+		Result *RetrieveResult `xml:"result"`
+	}
+	err := c.call(&checkRetrieveStatusRequest{AsyncProcessID: id, IncludeZip: includeZip}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// Retrieve retrieves the components listed in the manifest as a single package and polls its status until it's
+// done. The zip of the components is available from the result's Zip method.
+func (c *Client) Retrieve(manifest *Package, pollOpts ...force.PollOption) (*RetrieveResult, error) {
+	version := manifest.Version
+	if version == "" {
+		version = c.apiVersion
+	}
+
+	async, err := c.StartRetrieve(&RetrieveRequest{APIVersion: version, SinglePackage: true, Unpackaged: manifest})
+	if err != nil {
+		return nil, err
+	}
+
+	var result *RetrieveResult
+	pollErr := poll(pollOpts, func() (bool, error) {
+		var checkErr error
+		result, checkErr = c.CheckRetrieveStatus(async.ID, true)
+		if checkErr != nil {
+			return false, checkErr
+		}
+		return result.Done, nil
+	})
+	if pollErr != nil {
+		return result, pollErr
+	}
+	return result, result.Err()
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type approvalOutput struct {
+	Approved bool   `json:"Approved"`
+	Reviewer string `json:"Reviewer"`
+}
+
+func newActionServer() *forcetest.Server {
+	fake := newFakeServer()
+	fake.AddStandardAction(&force.ActionDescribe{Name: "emailSimple", Label: "Send Email", Type: "EMAILSIMPLE",
+		Inputs: []*force.ActionParameter{
+			{Name: "emailAddresses", Type: "STRING", Required: true},
+			{Name: "emailSubject", Type: "STRING", Required: true},
+			{Name: "emailBody", Type: "TEXTAREA", Required: true},
+		}}, nil)
+	fake.AddCustomAction(force.ActionTypes.Flow, &force.ActionDescribe{Name: "Approve_Invoice", Label: "Approve Invoice",
+		Inputs:  []*force.ActionParameter{{Name: "Amount", Type: "DOUBLE", Required: true}},
+		Outputs: []*force.ActionParameter{{Name: "Approved", Type: "BOOLEAN"}, {Name: "Reviewer", Type: "STRING"}},
+	}, func(input force.SObject) (map[string]interface{}, error) {
+		amount := input["Amount"].(float64)
+		if amount < 0 {
+			return nil, fmt.Errorf("amount cannot be negative")
+		}
+		return map[string]interface{}{"Approved": amount < 1000, "Reviewer": "Finance"}, nil
+	})
+	return fake
+}
+
+func TestActions_ListAndDescribe(t *testing.T) {
+	fake := newActionServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	standard, _, err := client.StandardActions()
+	assert.Nil(t, err)
+	assert.Len(t, standard, 1)
+	assert.Equal(t, "emailSimple", standard[0].Name)
+
+	types, _, err := client.CustomActionTypes()
+	assert.Nil(t, err)
+	assert.Contains(t, types, force.ActionTypes.Flow)
+
+	flows, _, err := client.CustomActions(force.ActionTypes.Flow)
+	assert.Nil(t, err)
+	assert.Len(t, flows, 1)
+	assert.Equal(t, "Approve_Invoice", flows[0].Name)
+
+	describe, _, err := client.DescribeCustomAction(force.ActionTypes.Flow, "Approve_Invoice")
+	assert.Nil(t, err)
+	assert.True(t, describe.FindInput("amount").Required)
+	assert.Len(t, describe.Outputs, 2)
+
+	describe, _, err = client.DescribeStandardAction("emailSimple")
+	assert.Nil(t, err)
+	assert.True(t, describe.Standard)
+	assert.Len(t, describe.Inputs, 3)
+}
+
+func TestLaunchFlow(t *testing.T) {
+	fake := newActionServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	results, err := client.LaunchFlow("Approve_Invoice", force.SObject{"Amount": 250}, force.SObject{"Amount": 5000})
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+
+	var out approvalOutput
+	assert.Nil(t, results[1].Decode(&out))
+	assert.Equal(t, approvalOutput{Approved: false, Reviewer: "Finance"}, out)
+}
+
+func TestInvokeAction_CollectsErrors(t *testing.T) {
+	fake := newActionServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	inputs := make([]interface{}, 0, force.MaxActionInputs+2)
+	for i := 0; i < force.MaxActionInputs+2; i++ {
+		inputs = append(inputs, force.SObject{"Amount": 10})
+	}
+	inputs[1] = force.SObject{"Amount": -1}
+	inputs[force.MaxActionInputs+1] = force.SObject{}
+
+	results, err := client.InvokeCustomAction(force.ActionTypes.Flow, "Approve_Invoice", inputs...)
+	assert.Len(t, results, force.MaxActionInputs+2)
+	assert.True(t, results[0].IsSuccess)
+
+	actionErr, ok := err.(*force.ActionError)
+	assert.True(t, ok)
+	assert.Len(t, actionErr.Failures, 2)
+	assert.Equal(t, 1, actionErr.Failures[0].Index)
+	assert.Equal(t, force.MaxActionInputs+1, actionErr.Failures[1].Index)
+	assert.Equal(t, "REQUIRED_FIELD_MISSING", actionErr.Failures[1].Errors[0].StatusCode)
+	assert.Equal(t, "2 inputs to Approve_Invoice failed: input 1: UNKNOWN_EXCEPTION: amount cannot be negative; "+
+		"input 201: REQUIRED_FIELD_MISSING: Missing required input parameter: Amount (Amount)", err.Error())
+
+	_, err = client.InvokeStandardAction("emailSimple", force.SObject{"emailAddresses": "a@example.com",
+		"emailSubject": "Hi", "emailBody": "Hello"})
+	assert.Nil(t, err)
+
+	_, err = client.InvokeStandardAction("missingAction", force.SObject{})
+	assert.NotNil(t, err)
+}
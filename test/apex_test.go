@@ -0,0 +1,103 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newApexServer() *forcetest.Server {
+	fake := newFakeServer()
+	fake.AddApexTests(
+		&forcetest.ApexTest{ClassName: "GreeterTest", MethodName: "greets", RunTime: 12},
+		&forcetest.ApexTest{ClassName: "GreeterTest", MethodName: "rejectsBlank", RunTime: 8,
+			Outcome: force.ApexTestOutcomes.Fail, Message: "System.AssertException: Assertion Failed",
+			StackTrace: "Class.GreeterTest.rejectsBlank: line 14, column 1"},
+		&forcetest.ApexTest{ClassName: "BrokenTest", MethodName: "runs",
+			Outcome: force.ApexTestOutcomes.CompileFail, Message: "Variable does not exist: x"},
+	)
+	fake.SetApexCoverage(&forcetest.ApexCoverage{ClassName: "Greeter", CoveredLines: []int{1, 2, 3}, UncoveredLines: []int{7}})
+	return fake
+}
+
+func TestExecuteAnonymous_Exception(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	fake.SetAnonymousApex(func(apex string) *force.ExecuteAnonymousResult {
+		return &force.ExecuteAnonymousResult{Line: 3, Column: 1, Compiled: true,
+			ExceptionMessage:    force.String("System.NullPointerException: Attempt to de-reference a null object"),
+			ExceptionStackTrace: force.String("AnonymousBlock: line 3, column 1")}
+	})
+
+	result, err := client.ExecuteAnonymous("String s; s.length();")
+	assert.True(t, result.Compiled)
+	assert.False(t, result.Success)
+
+	apexErr, ok := err.(*force.ApexError)
+	assert.True(t, ok)
+	assert.Equal(t, "AnonymousBlock: line 3, column 1", apexErr.StackTrace)
+	assert.Equal(t, "line 3, column 1: System.NullPointerException: Attempt to de-reference a null object", err.Error())
+}
+
+func TestRunTests_Synchronous(t *testing.T) {
+	fake := newApexServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	result, err := client.RunTests(&force.RunTestsRequest{Tests: []*force.TestItem{{ClassName: "GreeterTest"}}})
+	assert.Nil(t, err)
+	assert.False(t, result.Passed())
+	assert.Equal(t, 2, result.NumTestsRun)
+	assert.Equal(t, 1, result.NumFailures)
+	assert.Equal(t, float64(20), result.TotalTime)
+
+	failures := result.Failures()
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "rejectsBlank", failures[0].MethodName)
+	assert.Equal(t, "Class.GreeterTest.rejectsBlank: line 14, column 1", failures[0].StackTrace)
+
+	assert.Len(t, result.Coverage, 1)
+	assert.Equal(t, "Greeter", result.Coverage[0].Name)
+	assert.Equal(t, float64(75), result.Coverage[0].Percent())
+	assert.Equal(t, []int{7}, result.Coverage[0].UncoveredLines)
+}
+
+func TestRunTests_Asynchronous(t *testing.T) {
+	fake := newApexServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	result, err := client.RunTestsAsync(&force.RunTestsRequest{TestLevel: force.TestLevels.RunLocalTests},
+		force.PollInterval(10*time.Millisecond), force.PollTimeout(time.Second))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.JobID)
+	assert.Equal(t, 2, result.NumTestsRun)
+	assert.Equal(t, 2, result.NumFailures)
+
+	outcomes := map[string]string{}
+	for _, test := range result.Tests {
+		outcomes[test.ClassName+"."+test.MethodName] = test.Outcome
+	}
+	assert.Equal(t, map[string]string{
+		"GreeterTest.greets":       force.ApexTestOutcomes.Pass,
+		"GreeterTest.rejectsBlank": force.ApexTestOutcomes.Fail,
+		"BrokenTest.":              force.ApexTestOutcomes.CompileFail,
+	}, outcomes)
+
+	assert.Len(t, result.Coverage, 1)
+	assert.Equal(t, "Greeter", result.Coverage[0].Name)
+	assert.Equal(t, 3, result.Coverage[0].NumLinesCovered)
+	assert.Equal(t, []int{7}, result.Coverage[0].UncoveredLines)
+}
+
+func TestPoller_Timeout(t *testing.T) {
+	poller, err := force.NewPoller(force.PollInterval(time.Millisecond), force.PollTimeout(5*time.Millisecond))
+	assert.Nil(t, err)
+
+	err = poller.Poll(func() (bool, error) { return false, nil })
+	assert.EqualError(t, err, "operation did not complete within 5ms")
+}
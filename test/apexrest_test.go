@@ -0,0 +1,145 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/davidji99/force-go/force"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type invoice struct {
+	Number string  `json:"number"`
+	Amount float64 `json:"amount"`
+}
+
+func TestApexREST(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	fake.HandleApexREST("/invoices/*", func(w http.ResponseWriter, r *http.Request) {
+		var body invoice
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body.Number = strings.TrimPrefix(r.URL.Path, "/services/apexrest/invoices/") + "-" + r.URL.Query().Get("suffix")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+
+	var result invoice
+	response, err := client.ApexREST("post", "/invoices/INV-1?suffix=A", &invoice{Amount: 12.5}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, invoice{Number: "INV-1-A", Amount: 12.5}, result)
+
+	// Percent-encoded characters in the path and query reach the endpoint unchanged.
+	response, err = client.ApexREST(http.MethodGet, "/invoices/INV%2F2?suffix=a%20b", nil, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "INV/2-a b", result.Number)
+
+	_, err = client.ApexREST(http.MethodGet, "/unknown", nil, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestApexREST_Retry(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake, force.Retry(2, time.Millisecond))
+
+	attempts := 0
+	fake.HandleApexREST("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"ok"`))
+	})
+
+	var result string
+	_, err := client.ApexREST(http.MethodGet, "flaky", nil, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestApexREST_RetryNonIdempotent(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake, force.Retry(2, time.Millisecond))
+
+	attempts := 0
+	status := http.StatusServiceUnavailable
+	fake.HandleApexREST("/charge", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"ok"`))
+	})
+
+	// A POST failing with a server error may have been committed, so it isn't retried.
+	_, err := client.ApexREST(http.MethodPost, "charge", nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// Rate limited requests were rejected before taking effect.
+	attempts = 0
+	status = http.StatusTooManyRequests
+	var result string
+	_, err = client.ApexREST(http.MethodPost, "charge", nil, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestDo(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	fake.HandleApexREST("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(r.Header.Get("Content-Type") + " " + string(body)))
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, "/services/apexrest/echo", strings.NewReader("hello"))
+	req.Header.Set("Content-Type", "text/plain")
+	response, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain hello", response.Body)
+
+	req, _ = http.NewRequest(http.MethodGet, fake.URL+"/services/data/v50.0/limits", nil)
+	response, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	fake.HandleApexREST("/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(r.URL.RawQuery + " " + r.URL.Query().Get("q") + " " + r.URL.Query().Get("path")))
+	})
+	req, _ = http.NewRequest(http.MethodGet, fake.URL+"/services/apexrest/search?q=a%20b&path=c%2Fd", nil)
+	response, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "q=a%20b&path=c%2Fd a b c/d", response.Body)
+
+	// The access token is never sent to another host.
+	req, _ = http.NewRequest(http.MethodGet, "https://attacker.example.com/services/data/v50.0/limits", nil)
+	_, err = client.Do(req)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "is not on the instance URL")
+
+	req, _ = http.NewRequest(http.MethodGet, fake.URL+"/services/data/v50.0/limits", nil)
+	fake.SetAccessToken("ROTATED")
+	_, err = client.Do(req)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "INVALID_SESSION_ID")
+}
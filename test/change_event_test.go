@@ -0,0 +1,104 @@
+package test
+
+import (
+	"fmt"
+	"github.com/davidji99/force-go/force"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeEventChannel(t *testing.T) {
+	assert.Equal(t, "/data/AccountChangeEvent", force.ChangeEventChannel("Account"))
+	assert.Equal(t, "/data/Invoice__ChangeEvent", force.ChangeEventChannel("Invoice__c"))
+	assert.Equal(t, "/data/ChangeEvents", force.ChangeEventChannel(""))
+}
+
+func TestFileReplayStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "replay.json")
+	store, err := force.NewFileReplayStore(path)
+	assert.Nil(t, err)
+
+	_, ok, err := store.Load("/data/AccountChangeEvent")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, store.Save("/data/AccountChangeEvent", 42))
+
+	reopened, err := force.NewFileReplayStore(path)
+	assert.Nil(t, err)
+	id, ok, err := reopened.Load("/data/AccountChangeEvent")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestChangeSubscriber_ResumesFromCheckpoint(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+	account, _, err := client.Describe("Account")
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	consume := func(n int) []*force.ChangeEvent {
+		store, err := force.NewFileReplayStore(path)
+		assert.Nil(t, err)
+		stream := newStreamingClient(t, fake)
+		defer stream.Close()
+
+		subscriber, err := force.NewChangeSubscriber(stream, store, []*force.SObjectMetadata{account},
+			force.ChangeReplayFrom(force.ReplayAll))
+		assert.Nil(t, err)
+
+		events := make([]*force.ChangeEvent, 0)
+		err = subscriber.Run(func(event *force.ChangeEvent) error {
+			events = append(events, event)
+			if len(events) == n {
+				return subscriber.Close()
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		return events
+	}
+
+	fake.PublishChange("Account", force.ChangeTypes.Create, []string{"001000000000001AAA"}, force.SObject{"Name": "Acme"})
+	fake.PublishChange("Account", force.ChangeTypes.Update, []string{"001000000000001AAA"}, force.SObject{"Industry": "Energy"})
+
+	events := consume(2)
+	assert.Equal(t, force.ChangeTypes.Create, events[0].Header.ChangeType)
+	assert.Equal(t, "Acme", events[0].Fields["Name"])
+	assert.Equal(t, "Account", events[1].Metadata.GetName())
+	assert.Equal(t, []string{"Industry"}, events[1].Header.ChangedFields)
+	assert.Equal(t, []string{"001000000000001AAA"}, events[1].Header.RecordIDs)
+	assert.False(t, events[1].Header.CommitTime().IsZero())
+
+	// Only the event published after the checkpoint is delivered on restart.
+	fake.PublishChange("Account", force.ChangeTypes.Delete, []string{"001000000000001AAA"}, force.SObject{})
+	events = consume(1)
+	assert.Equal(t, force.ChangeTypes.Delete, events[0].Header.ChangeType)
+}
+
+func TestChangeSubscriber_HandlerErrorSkipsCheckpoint(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+
+	store := force.NewMemoryReplayStore()
+	subscriber, err := force.NewChangeSubscriber(stream, store, nil, force.ChangeReplayFrom(force.ReplayAll))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/data/ChangeEvents"}, subscriber.Channels())
+
+	fake.Publish("/data/ChangeEvents", map[string]interface{}{"payload": map[string]interface{}{
+		"ChangeEventHeader": map[string]interface{}{"entityName": "Contact", "changeType": "UPDATE"},
+	}})
+
+	err = subscriber.Run(func(event *force.ChangeEvent) error {
+		return fmt.Errorf("failed to process %s", event.Header.EntityName)
+	})
+	assert.EqualError(t, err, "failed to process Contact")
+
+	_, ok, _ := store.Load("/data/ChangeEvents")
+	assert.False(t, ok)
+}
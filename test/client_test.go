@@ -2,11 +2,31 @@ package test
 
 import (
 	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
 	"github.com/stretchr/testify/assert"
-	"os"
 	"testing"
 )
 
+func newFakeServer() *forcetest.Server {
+	fake := forcetest.NewServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Account", "001",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Industry", force.FieldDataTypes.Picklist),
+	))
+	fake.AddSObject(forcetest.NewSObjectMetadata("Contact", "003",
+		forcetest.NewField("LastName", force.FieldDataTypes.String),
+		forcetest.NewReferenceField("AccountId", "Account", "Account"),
+	))
+	return fake
+}
+
+func newFakeClient(t *testing.T, fake *forcetest.Server, opts ...force.Option) *force.Client {
+	opts = append([]force.Option{force.InstanceURL(fake.URL), force.AccessToken(fake.AccessToken())}, opts...)
+	client, err := force.New(opts...)
+	assert.Nil(t, err)
+	return client
+}
+
 func TestNewClient_WithAccessToken(t *testing.T) {
 	client, err := force.New(
 		force.AccessToken("SOME_TOKEN"),
@@ -24,13 +44,120 @@ func TestNewClient_WithAccessTokenEmptyString(t *testing.T) {
 }
 
 func TestNewClient_OAuth(t *testing.T) {
-	client, err := force.New(force.OAuthCred(
-		os.Getenv("OAUTH_USER"),
-		os.Getenv("OAUTH_PASSWORD"),
-		os.Getenv("OAUTH_CLIENT_ID"),
-		os.Getenv("OAUTH_CLIENT_SECRET"),
-		"",
-	))
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "password", "client-id", "client-secret")
+
+	client, err := force.New(
+		force.LoginURL(fake.URL),
+		force.OAuthCred("user@example.com", "password", "client-id", "client-secret"))
 	assert.Nil(t, err)
 	assert.NotNil(t, client)
 }
+
+func TestNewClient_OAuthInvalidGrant(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "password", "client-id", "client-secret")
+
+	client, err := force.New(
+		force.LoginURL(fake.URL),
+		force.OAuthCred("user@example.com", "wrong", "client-id", "client-secret"))
+	assert.NotNil(t, err)
+	assert.Nil(t, client)
+}
+
+func TestClient_Describe(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	meta, _, err := client.Describe("Account")
+	assert.Nil(t, err)
+	assert.Equal(t, "Account", meta.GetName())
+	assert.Contains(t, meta.GetFieldNames(), "Industry")
+
+	_, _, err = client.Describe("Nope")
+	assert.NotNil(t, err)
+}
+
+func TestClient_CreateUpdateDestroy(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	result, _, err := client.Create("Account", map[string]interface{}{"Name": "Acme"})
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Len(t, result.ID, 18)
+
+	_, err = client.Update("Account", result.ID, map[string]interface{}{"Industry": "Energy"})
+	assert.Nil(t, err)
+	record, ok := fake.Record("Account", result.ID)
+	assert.True(t, ok)
+	assert.Equal(t, "Energy", record["Industry"])
+
+	_, err = client.Destroy("Account", result.ID)
+	assert.Nil(t, err)
+	_, ok = fake.Record("Account", result.ID)
+	assert.False(t, ok)
+}
+
+func TestClient_QueryWithRelationship(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	_, _ = fake.Insert("Contact", force.SObject{"LastName": "Smith", "AccountId": accountID})
+	_, _ = fake.Insert("Contact", force.SObject{"LastName": "Jones"})
+
+	result, _, err := client.Query(&force.QueryRequest{
+		SOQL: "SELECT LastName, Account.Name FROM Contact WHERE AccountId != null ORDER BY LastName"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.TotalSize)
+	assert.Equal(t, "Smith", result.Records[0]["LastName"])
+	assert.Equal(t, "Acme", result.Records[0]["Account"].(map[string]interface{})["Name"])
+}
+
+func TestClient_InvalidSession(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake, force.AccessToken("expired"))
+
+	_, _, err := client.Describe("Account")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "INVALID_SESSION_ID")
+}
+
+func TestClient_IteratePaginates(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetPageSize(2)
+	client := newFakeClient(t, fake)
+
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		_, _ = fake.Insert("Account", force.SObject{"Name": name})
+	}
+
+	iter := client.Iterate(&force.QueryRequest{SOQL: "SELECT Name FROM Account ORDER BY Name"})
+	names := make([]interface{}, 0)
+	for iter.Next() {
+		names = append(names, iter.Record()["Name"])
+	}
+	assert.Nil(t, iter.Err())
+	assert.Equal(t, []interface{}{"A", "B", "C", "D", "E"}, names)
+	assert.Equal(t, 5, iter.TotalSize())
+}
+
+func TestClient_Get(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	id, _ := fake.Insert("Account", force.SObject{"Name": "Acme", "Industry": "Energy"})
+	record, _, err := client.Get("Account", id, "Name")
+	assert.Nil(t, err)
+	assert.Equal(t, "Acme", record["Name"])
+	assert.NotContains(t, record, "Industry")
+}
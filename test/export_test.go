@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bytes"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSelectFields(t *testing.T) {
+	fields := force.SelectFields(`SELECT Id, Account.Owner.Name, (SELECT Id FROM Contacts), COUNT(Id) total,
+		MAX(Amount) FROM Opportunity WHERE Name != 'from'`)
+	assert.Equal(t, []string{"Id", "Account.Owner.Name", "total", "expr0"}, fields)
+
+	fields = force.SelectFields(`SELECT toLabel(StageName), FORMAT(convertCurrency(Amount)), toLabel(Type) type_label,
+		Account.Name, COUNT_DISTINCT(OwnerId), SUM(Amount) FROM Opportunity GROUP BY StageName, Amount, Type, Account.Name`)
+	assert.Equal(t, []string{"StageName", "Amount", "type_label", "Account.Name", "expr0", "expr1"}, fields)
+
+	assert.Nil(t, force.SelectFields("SELECT FIELDS(STANDARD) FROM Account"))
+	assert.Nil(t, force.SelectFields("SELECT Id, fields(all) FROM Account LIMIT 200"))
+}
+
+func TestCSVWriter_FunctionColumns(t *testing.T) {
+	soql := "SELECT toLabel(StageName), convertCurrency(Amount), COUNT(Id) FROM Opportunity GROUP BY StageName, Amount"
+	var buf bytes.Buffer
+	err := force.WriteQueryResult(force.NewCSVWriter(&buf, force.SelectFields(soql)), &force.QueryResult{
+		Records: []force.SObject{{"StageName": "Closed Won", "Amount": 100, "expr0": 2}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "StageName,Amount,expr0\nClosed Won,100,2\n", buf.String())
+
+	// The columns of FIELDS() are inferred from the records.
+	buf.Reset()
+	err = force.WriteQueryResult(force.NewCSVWriter(&buf, force.SelectFields("SELECT FIELDS(STANDARD) FROM Account")),
+		&force.QueryResult{Records: []force.SObject{{"Name": "Acme", "Id": "001000000000001AAA"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "Id,Name\n001000000000001AAA,Acme\n", buf.String())
+}
+
+func TestFlattenRecord(t *testing.T) {
+	flat := force.FlattenRecord(force.SObject{
+		"attributes": map[string]interface{}{"type": "Contact"},
+		"LastName":   "Smith",
+		"Account": map[string]interface{}{
+			"attributes": map[string]interface{}{"type": "Account"},
+			"Owner":      map[string]interface{}{"Name": "Jane"},
+		},
+		"ReportsTo": nil,
+	})
+	assert.Equal(t, map[string]interface{}{"LastName": "Smith", "Account.Owner.Name": "Jane", "ReportsTo": nil}, flat)
+}
+
+func TestCSVWriter_NullRelationship(t *testing.T) {
+	records := []force.SObject{
+		{"Name": "Small", "Account": nil},
+		{"Name": "Big", "Account": map[string]interface{}{"Name": "Acme"}},
+	}
+
+	// A null relationship is a single inferred column.
+	var inferred bytes.Buffer
+	assert.Nil(t, force.WriteQueryResult(force.NewCSVWriter(&inferred, nil), &force.QueryResult{Records: records}))
+	assert.Equal(t, "Account,Name\n,Small\n,Big\n", inferred.String())
+
+	var selected bytes.Buffer
+	writer := force.NewCSVWriter(&selected, force.SelectFields("SELECT Name, Account.Name FROM Opportunity"))
+	assert.Nil(t, force.WriteQueryResult(writer, &force.QueryResult{Records: records}))
+	assert.Equal(t, "Name,Account.Name\nSmall,\nBig,Acme\n", selected.String())
+}
+
+func TestCSVWriter_QueryIterator(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetPageSize(1)
+	fake.AddSObject(forcetest.NewSObjectMetadata("User", "005", forcetest.NewField("Name", force.FieldDataTypes.String)))
+	fake.AddSObject(forcetest.NewSObjectMetadata("Opportunity", "006",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Amount", force.FieldDataTypes.Currency),
+		forcetest.NewReferenceField("AccountId", "Account", "Account"),
+		forcetest.NewReferenceField("OwnerId", "Owner", "User"),
+	))
+	client := newFakeClient(t, fake)
+
+	ownerID, _ := fake.Insert("User", force.SObject{"Name": "Jane Doe"})
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme, Inc"})
+	_, _ = fake.Insert("Opportunity", force.SObject{"Name": "Big", "Amount": 1250000, "AccountId": accountID, "OwnerId": ownerID})
+	_, _ = fake.Insert("Opportunity", force.SObject{"Name": "Small", "Amount": 10.5})
+
+	soql := "SELECT Name, amount, Account.Name, Owner.Name FROM Opportunity ORDER BY Name"
+	var buf bytes.Buffer
+	err := force.WriteQueryIterator(force.NewCSVWriter(&buf, force.SelectFields(soql)),
+		client.Iterate(&force.QueryRequest{SOQL: soql}))
+	assert.Nil(t, err)
+
+	expected := strings.Join([]string{
+		"Name,amount,Account.Name,Owner.Name",
+		`Big,1250000,"Acme, Inc",Jane Doe`,
+		"Small,10.5,,",
+	}, "\n") + "\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestNDJSONWriter_QueryResult(t *testing.T) {
+	var buf bytes.Buffer
+	err := force.WriteQueryResult(force.NewNDJSONWriter(&buf), &force.QueryResult{Records: []force.SObject{
+		{"attributes": map[string]interface{}{"type": "Account"}, "Name": "A"},
+		{"attributes": map[string]interface{}{"type": "Account"}, "Name": "B",
+			"Owner": map[string]interface{}{"attributes": map[string]interface{}{"type": "User"}, "Name": "Jane"}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"Name\":\"A\"}\n{\"Name\":\"B\",\"Owner\":{\"Name\":\"Jane\"}}\n", buf.String())
+}
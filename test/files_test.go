@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bytes"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFileServer() *forcetest.Server {
+	fake := newFakeServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("ContentVersion", "068",
+		forcetest.NewField("Title", force.FieldDataTypes.String),
+		forcetest.NewField("PathOnClient", force.FieldDataTypes.String),
+		forcetest.NewField("Description", force.FieldDataTypes.Textarea),
+		forcetest.NewField("ContentDocumentId", force.FieldDataTypes.Reference),
+		forcetest.NewField("VersionData", force.FieldDataTypes.Base64),
+	))
+	fake.AddSObject(forcetest.NewSObjectMetadata("ContentDocumentLink", "06A",
+		forcetest.NewField("ContentDocumentId", force.FieldDataTypes.Reference),
+		forcetest.NewField("LinkedEntityId", force.FieldDataTypes.Reference),
+		forcetest.NewField("ShareType", force.FieldDataTypes.Picklist),
+		forcetest.NewField("Visibility", force.FieldDataTypes.Picklist),
+	))
+	fake.AddSObject(forcetest.NewSObjectMetadata("Attachment", "00P",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("ParentId", force.FieldDataTypes.Reference),
+		forcetest.NewField("ContentType", force.FieldDataTypes.String),
+		forcetest.NewField("Body", force.FieldDataTypes.Base64),
+	))
+	return fake
+}
+
+func TestUploadContentVersion(t *testing.T) {
+	fake := newFileServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	content := strings.Repeat("0123456789", 100000)
+
+	result, err := client.UploadContentVersion(&force.ContentVersionUpload{Title: "Contract",
+		PathOnClient: "contract \"final\".pdf", Description: force.String("Signed"),
+		LinkedEntityIDs: []string{accountID}, ShareType: force.String(force.ContentShareTypes.Collaborator)},
+		strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.ContentDocumentID)
+	assert.Len(t, result.Links, 1)
+
+	blob, ok := fake.Blob(result.ID, "VersionData")
+	assert.True(t, ok)
+	assert.Equal(t, content, string(blob))
+
+	links := fake.Records("ContentDocumentLink")
+	assert.Len(t, links, 1)
+	assert.Equal(t, result.ContentDocumentID, links[0]["ContentDocumentId"])
+	assert.Equal(t, accountID, links[0]["LinkedEntityId"])
+	assert.Equal(t, "C", links[0]["ShareType"])
+
+	var buf bytes.Buffer
+	n, err := client.DownloadContentVersion(result.ID, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+}
+
+func TestUploadAttachment(t *testing.T) {
+	fake := newFileServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	created, _, err := client.UploadAttachment(&force.AttachmentUpload{Name: "notes.txt", ParentID: accountID,
+		ContentType: force.String("text/plain")}, strings.NewReader("hello"))
+	assert.Nil(t, err)
+	assert.True(t, created.Success)
+
+	record, _, err := client.Get("Attachment", created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "notes.txt", record["Name"])
+	assert.Contains(t, record["Body"], "/sobjects/Attachment/"+created.ID+"/Body")
+
+	var buf bytes.Buffer
+	_, err = client.DownloadAttachment(created.ID, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", buf.String())
+
+	_, err = client.DownloadAttachment("00P000000000000AAA", &buf)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+// rateLimitedUploads answers the first multipart request with 429 after draining its body, as a server would.
+type rateLimitedUploads struct {
+	attempts int
+}
+
+func (t *rateLimitedUploads) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	t.attempts++
+	if t.attempts > 1 {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	_, _ = io.Copy(ioutil.Discard, req.Body)
+	return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{},
+		Body: ioutil.NopCloser(strings.NewReader(`[{"errorCode":"REQUEST_LIMIT_EXCEEDED"}]`)), Request: req}, nil
+}
+
+func TestUploadAttachment_NotRetried(t *testing.T) {
+	fake := newFileServer()
+	defer fake.Close()
+	transport := &rateLimitedUploads{}
+	client := newFakeClient(t, fake, force.Retry(2, time.Millisecond), force.Transport(transport))
+
+	// The streamed content was consumed by the first attempt, so it is not sent again.
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	upload := &force.AttachmentUpload{Name: "notes.txt", ParentID: accountID}
+	_, _, err := client.UploadAttachment(upload, strings.NewReader("hello"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "REQUEST_LIMIT_EXCEEDED")
+	assert.Equal(t, 1, transport.attempts)
+
+	created, _, err := client.UploadAttachment(upload, strings.NewReader("hello"))
+	assert.Nil(t, err)
+	assert.True(t, created.Success)
+	assert.Equal(t, 2, transport.attempts)
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+	id, err := force.ParseID("001D000000IqhSL")
+	assert.Nil(t, err)
+	assert.Equal(t, force.ID("001D000000IqhSLIAZ"), id)
+	assert.Equal(t, "IAZ", id.Suffix())
+	assert.Equal(t, force.ID("001D000000IqhSL"), id.To15())
+	assert.Equal(t, "001", id.KeyPrefix())
+
+	// The case lost by lower-casing is restored from the suffix.
+	id, err = force.ParseID("001d000000iqhsliaz")
+	assert.Nil(t, err)
+	assert.Equal(t, force.ID("001D000000IqhSLIAZ"), id)
+
+	for _, invalid := range []string{"", "001D000000IqhS", "001D000000IqhSL-AZ", "001D000000IqhSLIA!",
+		"001D000000IqhSlIAZ", "001D000000IqhSL5AZ"} {
+		_, err := force.ParseID(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+
+	assert.True(t, force.EqualIDs("001D000000IqhSL", "001D000000IQHSLIAZ"))
+	assert.True(t, force.ID("001D000000IqhSLIAZ").Equal("001D000000IqhSL"))
+	assert.False(t, force.EqualIDs("001D000000IqhSL", "001D000000IqhSl"))
+	assert.False(t, force.EqualIDs("bad", "bad"))
+	assert.Equal(t, force.ID("bad"), force.ID("bad").To18())
+}
+
+func TestGetByID(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	contactID, _ := fake.Insert("Contact", force.SObject{"LastName": "Smith"})
+
+	name, err := client.ObjectNameForID(contactID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Contact", name)
+
+	record, _, err := client.GetByID(string(force.ID(contactID).To15()), "LastName")
+	assert.Nil(t, err)
+	assert.Equal(t, "Smith", record["LastName"])
+
+	registry, err := client.KeyPrefixes()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, registry.Len())
+	name, ok := registry.ObjectName("001")
+	assert.True(t, ok)
+	assert.Equal(t, "Account", name)
+
+	_, err = client.ObjectNameForID("a05000000000001AAA")
+	assert.EqualError(t, err, "no SObject has the key prefix of ID a05000000000001AAA")
+	_, _, err = client.GetByID("not-an-id")
+	assert.NotNil(t, err)
+}
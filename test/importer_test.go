@@ -0,0 +1,140 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func newImportServer(t *testing.T) (*forcetest.Server, *force.Client, *force.SObjectMetadata) {
+	stage := forcetest.NewField("Stage__c", force.FieldDataTypes.Picklist)
+	stage.Label = force.String("Stage")
+	stage.RestrictedPicklist = force.Bool(true)
+	stage.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{
+		{Value: force.String("prospect"), Label: force.String("Prospect")},
+		{Value: force.String("closed"), Label: force.String("Closed Won")},
+	}
+
+	fake := forcetest.NewServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Deal__c", "a01",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Code__c", force.FieldDataTypes.String),
+		forcetest.NewField("Amount__c", force.FieldDataTypes.Currency),
+		forcetest.NewField("Active__c", force.FieldDataTypes.Boolean),
+		forcetest.NewField("Close_Date__c", force.FieldDataTypes.Date),
+		stage,
+	))
+	client := newFakeClient(t, fake)
+
+	meta, _, err := client.Describe("Deal__c")
+	assert.Nil(t, err)
+	return fake, client, meta
+}
+
+func TestImporter_Create(t *testing.T) {
+	fake, client, meta := newImportServer(t)
+	defer fake.Close()
+
+	importer, err := force.NewImporter(client, meta, force.ImportBatchSize(2))
+	assert.Nil(t, err)
+
+	csv := strings.Join([]string{
+		"\ufeffName,amount__c,Active__c,Close_Date__c,Stage,Notes",
+		`First,"$1,250.50",yes,03/15/2021,Closed Won,ignored`,
+		"Second,10,no,2021-04-01,prospect,",
+		"Third,abc,maybe,2021-04-01,unknown,",
+		"Fourth,,,,,",
+	}, "\n")
+
+	result, err := importer.Import(strings.NewReader(csv))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, result.Processed)
+	assert.Equal(t, 3, result.Created)
+	assert.Equal(t, []string{"Notes"}, result.UnmappedColumns)
+	assert.Equal(t, []int{2, 3, 5}, []int{result.Records[0].Row, result.Records[1].Row, result.Records[2].Row})
+
+	assert.Len(t, result.Errors, 3)
+	for _, e := range result.Errors {
+		assert.Equal(t, 4, e.Row)
+	}
+	assert.Equal(t, "Amount__c", result.Errors[0].Field)
+	assert.Equal(t, "row 4, column Stage: 'unknown' is not a valid value for restricted picklist Stage__c",
+		result.Errors[2].Error())
+
+	first, _ := fake.Record("Deal__c", result.Records[0].ID)
+	assert.Equal(t, 1250.5, first["Amount__c"])
+	assert.Equal(t, true, first["Active__c"])
+	assert.Equal(t, "2021-03-15", first["Close_Date__c"])
+	assert.Equal(t, "closed", first["Stage__c"])
+}
+
+func TestImporter_Upsert(t *testing.T) {
+	fake, client, meta := newImportServer(t)
+	defer fake.Close()
+
+	existingID, _ := fake.Insert("Deal__c", force.SObject{"Name": "Old", "Code__c": "D-1"})
+
+	importer, err := force.NewImporter(client, meta, force.ImportUpsert("Code__c"),
+		force.ImportColumn("Deal Name", "Name"))
+	assert.Nil(t, err)
+
+	csv := "Deal Name,Code__c\nRenamed,D-1\nNew,D-2\nMissing,\n"
+	result, err := importer.Import(strings.NewReader(csv))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, existingID, result.Records[0].ID)
+	assert.False(t, result.Records[0].Created)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 4, result.Errors[0].Row)
+	updated, _ := fake.Record("Deal__c", existingID)
+	assert.Equal(t, "Renamed", updated["Name"])
+}
+
+func TestNewImporter_UnknownExternalID(t *testing.T) {
+	fake, client, meta := newImportServer(t)
+	defer fake.Close()
+
+	_, err := force.NewImporter(client, meta, force.ImportUpsert("Missing__c"))
+	assert.NotNil(t, err)
+}
+
+func TestCoerceFieldValue_Separators(t *testing.T) {
+	amount := forcetest.NewField("Amount__c", force.FieldDataTypes.Currency)
+	for raw, expected := range map[string]float64{"1,250.50": 1250.5, "-1,234,567": -1234567, "$ 12.5": 12.5} {
+		value, err := force.CoerceFieldValue(amount, raw)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, value)
+	}
+	for _, raw := range []string{"1,5", "12,34.5", "1,2345"} {
+		_, err := force.CoerceFieldValue(amount, raw)
+		assert.EqualError(t, err, "'"+raw+"' is not a valid number")
+	}
+
+	// Multipicklist values are separated by ';' only, so values may contain ','.
+	colors := forcetest.NewField("Colors__c", force.FieldDataTypes.Multipicklist)
+	colors.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{
+		{Value: force.String("Red, dark"), Label: force.String("Red, dark")},
+		{Value: force.String("Blue"), Label: force.String("Blue")},
+	}
+	value, err := force.CoerceFieldValue(colors, "red, dark; blue;Blue")
+	assert.Nil(t, err)
+	assert.Equal(t, "Red, dark;Blue", value)
+}
+
+func TestCoerceFieldValue_LayoutsNotShared(t *testing.T) {
+	defaults := force.DefaultDateLayouts
+	defer func() { force.DefaultDateLayouts = defaults }()
+
+	// Leave spare capacity after the default layouts, which appending the extra layouts must not write into.
+	force.DefaultDateLayouts = append(make([]string, 0, len(defaults)+4), defaults...)
+	spare := force.DefaultDateLayouts[:cap(force.DefaultDateLayouts)]
+
+	closeDate := forcetest.NewField("CloseDate", force.FieldDataTypes.Date)
+	value, err := force.CoerceFieldValue(closeDate, "02.01.2006", "02.01.2006")
+	assert.Nil(t, err)
+	assert.Equal(t, "2006-01-02", value)
+	assert.Equal(t, "", spare[len(defaults)])
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/davidji99/force-go/metadata"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const invoiceObject = `<?xml version="1.0" encoding="UTF-8"?>
+<CustomObject xmlns="http://soap.sforce.com/2006/04/metadata">
+    <label>Invoice</label>
+    <pluralLabel>Invoices</pluralLabel>
+    <sharingModel>ReadWrite</sharingModel>
+    <fields>
+        <fullName>Amount__c</fullName>
+        <label>Amount</label>
+        <type>Currency</type>
+        <precision>18</precision>
+        <scale>2</scale>
+    </fields>
+</CustomObject>
+`
+
+const greeterMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<ApexClass xmlns="http://soap.sforce.com/2006/04/metadata">
+    <apiVersion>50.0</apiVersion>
+    <status>Active</status>
+</ApexClass>
+`
+
+func newMetadataClient(t *testing.T, fake *forcetest.Server) *metadata.Client {
+	m, err := metadata.New(newFakeClient(t, fake))
+	assert.Nil(t, err)
+	return m
+}
+
+// writeSource writes files into a temporary source directory and returns the directory.
+func writeSource(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "metadata")
+	assert.Nil(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func sourceZip(t *testing.T, manifest *metadata.Package, files map[string]string) []byte {
+	b, err := manifest.Bytes()
+	assert.Nil(t, err)
+	files["package.xml"] = string(b)
+
+	dir := writeSource(t, files)
+	defer os.RemoveAll(dir)
+	zipFile, err := metadata.ZipDir(dir)
+	assert.Nil(t, err)
+	return zipFile
+}
+
+func fastPoll() []force.PollOption {
+	return []force.PollOption{force.PollInterval(time.Millisecond), force.PollTimeout(time.Second)}
+}
+
+func TestDeploy(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+
+	zipFile := sourceZip(t, metadata.NewPackage("50.0").Add("ApexClass", "Greeter").Add("CustomObject", "*"),
+		map[string]string{
+			"classes/Greeter.cls":          "public class Greeter {}",
+			"classes/Greeter.cls-meta.xml": greeterMeta,
+			"objects/Invoice__c.object":    invoiceObject,
+		})
+
+	result, err := m.Deploy(zipFile, &metadata.DeployOptions{SinglePackage: true}, fastPoll()...)
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, metadata.DeployStatuses.Succeeded, result.Status)
+	assert.Equal(t, 2, result.NumberComponentsDeployed)
+	assert.Len(t, result.Details.ComponentSuccesses, 2)
+
+	content, ok := fake.Metadata("ApexClass", "Greeter")
+	assert.True(t, ok)
+	assert.Equal(t, "public class Greeter {}", string(content))
+}
+
+func TestDeploy_CheckOnlyWithTests(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+
+	fake.AddApexTests(
+		&forcetest.ApexTest{ClassName: "GreeterTest", MethodName: "greets"},
+		&forcetest.ApexTest{ClassName: "GreeterTest", MethodName: "fails", Outcome: force.ApexTestOutcomes.Fail,
+			Message: "System.AssertException: Assertion Failed"},
+	)
+	zipFile := sourceZip(t, metadata.NewPackage("50.0").Add("ApexClass", "Greeter"),
+		map[string]string{"classes/Greeter.cls": "public class Greeter {}"})
+
+	result, err := m.Deploy(zipFile, &metadata.DeployOptions{CheckOnly: true, SinglePackage: true,
+		TestLevel: metadata.TestLevels.RunSpecifiedTests, RunTests: []string{"GreeterTest"}}, fastPoll()...)
+	assert.False(t, result.Success)
+	assert.True(t, result.CheckOnly)
+	assert.Equal(t, 2, result.NumberTestsTotal)
+	assert.Equal(t, 1, result.NumberTestErrors)
+
+	deployErr, ok := err.(*metadata.DeployError)
+	assert.True(t, ok)
+	assert.Len(t, deployErr.TestFailures, 1)
+	assert.Equal(t, "fails", deployErr.TestFailures[0].MethodName)
+
+	_, ok = fake.Metadata("ApexClass", "Greeter")
+	assert.False(t, ok)
+}
+
+func TestDeploy_ComponentFailure(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+
+	zipFile := sourceZip(t, metadata.NewPackage("50.0").Add("CustomObject", "Invoice__c", "Missing__c"),
+		map[string]string{"objects/Invoice__c.object": "<CustomObject>\n<label>Invoice</CustomObject>"})
+
+	result, err := m.Deploy(zipFile, &metadata.DeployOptions{SinglePackage: true, RollbackOnError: true}, fastPoll()...)
+	assert.NotNil(t, err)
+	assert.Equal(t, metadata.DeployStatuses.Failed, result.Status)
+	assert.Equal(t, 2, result.NumberComponentErrors)
+
+	failures := err.(*metadata.DeployError).ComponentFailures
+	assert.Equal(t, "objects/Invoice__c.object", failures[0].FileName)
+	assert.Equal(t, 2, failures[0].LineNumber)
+	assert.Equal(t, "Missing__c", failures[1].FullName)
+}
+
+func TestRetrieve(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+
+	assert.Nil(t, fake.AddMetadata("classes/Greeter.cls", []byte("public class Greeter {}")))
+	assert.Nil(t, fake.AddMetadata("classes/Greeter.cls-meta.xml", []byte(greeterMeta)))
+
+	result, err := m.Retrieve(metadata.NewPackage("50.0").Add("ApexClass", "*").Add("ApexTrigger", "Missing"),
+		fastPoll()...)
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Len(t, result.Messages, 1)
+	assert.Equal(t, "Entity of type 'ApexTrigger' named 'Missing' cannot be found", result.Messages[0].Problem)
+
+	b, err := result.Zip()
+	assert.Nil(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	assert.Nil(t, err)
+
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"classes/Greeter.cls": true, "classes/Greeter.cls-meta.xml": true,
+		"package.xml": true}, names)
+}
+
+func TestListAndReadMetadata(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+
+	assert.Nil(t, fake.AddMetadata("objects/Invoice__c.object", []byte(invoiceObject)))
+	assert.Nil(t, fake.AddMetadata("classes/Greeter.cls-meta.xml", []byte(greeterMeta)))
+
+	properties, err := m.ListMetadata(&metadata.ListMetadataQuery{Type: "CustomObject"},
+		&metadata.ListMetadataQuery{Type: "CustomField"}, &metadata.ListMetadataQuery{Type: "ApexClass"},
+		&metadata.ListMetadataQuery{Type: "Layout"})
+	assert.Nil(t, err)
+	fullNames := make([]string, 0)
+	for _, p := range properties {
+		fullNames = append(fullNames, p.Type+":"+p.FullName)
+	}
+	assert.Equal(t, []string{"CustomObject:Invoice__c", "CustomField:Invoice__c.Amount__c", "ApexClass:Greeter"},
+		fullNames)
+
+	var objects []*metadata.CustomObject
+	assert.Nil(t, m.ReadMetadata("CustomObject", []string{"Invoice__c", "Missing__c"}, &objects))
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "Invoice__c", objects[0].FullName)
+	assert.Equal(t, "Invoices", objects[0].PluralLabel)
+	assert.Equal(t, "Currency", objects[0].Fields[0].Type)
+
+	var fields []metadata.CustomField
+	assert.Nil(t, m.ReadMetadata("CustomField", []string{"Invoice__c.Amount__c"}, &fields))
+	assert.Equal(t, []metadata.CustomField{{FullName: "Invoice__c.Amount__c", Label: "Amount", Type: "Currency",
+		Precision: 18, Scale: 2}}, fields)
+
+	var classes []*metadata.ApexClass
+	assert.Nil(t, m.ReadMetadata("ApexClass", []string{"Greeter"}, &classes))
+	assert.Equal(t, &metadata.ApexClass{FullName: "Greeter", APIVersion: "50.0", Status: "Active"}, classes[0])
+}
+
+func TestMetadata_InvalidSession(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	m := newMetadataClient(t, fake)
+	fake.SetAccessToken("ROTATED")
+
+	_, err := m.ListMetadata(&metadata.ListMetadataQuery{Type: "ApexClass"})
+	fault, ok := err.(*metadata.Fault)
+	assert.True(t, ok)
+	assert.Equal(t, "sf:INVALID_SESSION_ID", fault.Code)
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// authorize follows the authorization URL and returns the callback request the fake redirects to.
+func authorize(t *testing.T, authorizeURL string) *http.Request {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authorizeURL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	return httptest.NewRequest(http.MethodGet, resp.Header.Get("Location"), nil)
+}
+
+func TestWebServerFlow(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "pass", "CLIENT_ID", "CLIENT_SECRET")
+
+	flow := &force.WebServerFlow{LoginURL: fake.URL, ClientID: "CLIENT_ID", ClientSecret: "CLIENT_SECRET",
+		RedirectURI: "https://tool.example.com/callback", Scopes: []string{"api", "refresh_token"}}
+	pkce, err := force.NewPKCE()
+	assert.Nil(t, err)
+	assert.Len(t, pkce.Verifier, 43)
+	state, err := force.NewOAuthState()
+	assert.Nil(t, err)
+
+	authorizeURL, err := url.Parse(flow.AuthorizeURL(state, pkce))
+	assert.Nil(t, err)
+	assert.Equal(t, "/services/oauth2/authorize", authorizeURL.Path)
+	assert.Equal(t, "api refresh_token", authorizeURL.Query().Get("scope"))
+	assert.Equal(t, pkce.Challenge, authorizeURL.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", authorizeURL.Query().Get("code_challenge_method"))
+
+	callback := authorize(t, authorizeURL.String())
+	assert.Equal(t, "tool.example.com", callback.Host)
+	_, err = flow.HandleCallback(callback, "other-state", pkce)
+	assert.NotNil(t, err)
+
+	token, err := flow.HandleCallback(callback, state, pkce)
+	assert.Nil(t, err)
+	assert.Equal(t, fake.AccessToken(), token.GetAccessToken())
+	assert.NotEmpty(t, token.GetRefreshToken())
+
+	client, err := force.New(force.OAuthToken(token))
+	assert.Nil(t, err)
+	assert.Equal(t, token, client.Token())
+	_, _, err = client.Describe("Account")
+	assert.Nil(t, err)
+
+	// Codes can only be exchanged once.
+	_, err = flow.HandleCallback(callback, state, pkce)
+	tokenErr, ok := err.(*force.TokenErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, force.TokenErrorCodes.InvalidGrant, tokenErr.ErrorCode)
+
+	// The code is bound to the PKCE it was requested with.
+	other, _ := force.NewPKCE()
+	callback = authorize(t, flow.AuthorizeURL(state, pkce))
+	_, err = flow.HandleCallback(callback, state, other)
+	assert.EqualError(t, err, "invalid_grant: invalid code verifier")
+
+	// The state check can't be skipped.
+	callback = authorize(t, flow.AuthorizeURL("", pkce))
+	_, err = flow.HandleCallback(callback, "", pkce)
+	assert.EqualError(t, err, "the state of the authorization request is required to check the OAuth callback")
+
+	flow.ClientID = "OTHER"
+	callback = authorize(t, flow.AuthorizeURL(state, nil))
+	_, err = flow.HandleCallback(callback, state, nil)
+	assert.EqualError(t, err, "invalid_client_id: client identifier invalid")
+}
+
+func TestOAuthToken(t *testing.T) {
+	_, err := force.New(force.OAuthToken(&force.TokenResponse{AccessToken: force.String("TOKEN")}))
+	assert.NotNil(t, err)
+
+	fake := forcetest.NewServer()
+	defer fake.Close()
+	client, err := force.New(force.OAuthToken(&force.TokenResponse{AccessToken: force.String(fake.AccessToken()),
+		InstanceURL: force.String(fake.URL)}))
+	assert.Nil(t, err)
+	assert.Equal(t, fake.URL, client.InstanceURL())
+}
+
+func TestClientCredentials(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "pass", "CLIENT_ID", "CLIENT_SECRET")
+
+	_, err := force.New(force.ClientCredentials("CLIENT_ID", ""))
+	assert.NotNil(t, err)
+
+	client, err := force.New(force.LoginURL(fake.URL), force.ClientCredentials("CLIENT_ID", "CLIENT_SECRET"))
+	assert.Nil(t, err)
+	assert.Equal(t, fake.AccessToken(), client.AccessToken())
+	_, _, err = client.Describe("Account")
+	assert.Nil(t, err)
+
+	_, tokenErr, err := force.OAuthClientCredentials(fake.URL, "CLIENT_ID", "WRONG")
+	assert.Nil(t, err)
+	assert.Equal(t, force.TokenErrorCodes.InvalidClient, tokenErr.ErrorCode)
+}
+
+func TestDeviceFlow(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+
+	// The fake responds with slow_down to polls within a second of each other, so short intervals grow.
+	flow := &force.DeviceFlow{LoginURL: fake.URL, ClientID: "CLIENT_ID", Interval: 10 * time.Millisecond,
+		SlowDown: 10 * time.Millisecond, Timeout: 5 * time.Second}
+	var prompted *force.DeviceAuthorization
+	client, err := force.New(force.DeviceAuth(flow, func(auth *force.DeviceAuthorization) error {
+		prompted = auth
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			assert.Nil(t, fake.ApproveDevice(auth.UserCode))
+		}()
+		return nil
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, fake.URL+"/setup/connect", prompted.VerificationURI)
+	assert.Contains(t, prompted.String(), prompted.UserCode)
+	assert.Equal(t, fake.AccessToken(), client.AccessToken())
+	assert.NotEmpty(t, client.Token().GetRefreshToken())
+
+	// Polling gives up if the user never approves.
+	flow.Timeout = 100 * time.Millisecond
+	_, err = flow.Run(func(*force.DeviceAuthorization) error { return nil })
+	assert.NotNil(t, err)
+
+	_, err = flow.Poll(&force.DeviceAuthorization{DeviceCode: "UNKNOWN"})
+	assert.EqualError(t, err, "invalid_grant: invalid device code")
+}
+
+func TestVerifyTokenSignature(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "pass", "CLIENT_ID", "CLIENT_SECRET")
+
+	token, _, err := force.OAuth(fake.URL, &force.OAuthCredentials{Username: "user@example.com", Password: "pass",
+		ClientID: "CLIENT_ID", ClientSecret: "CLIENT_SECRET"})
+	assert.Nil(t, err)
+	assert.Nil(t, token.VerifySignature("CLIENT_SECRET"))
+	assert.EqualError(t, token.VerifySignature("OTHER"), "token response signature does not match the client secret")
+
+	token.IssuedAt = force.String("0")
+	assert.NotNil(t, token.VerifySignature("CLIENT_SECRET"))
+	token.Signature = nil
+	assert.EqualError(t, token.VerifySignature("CLIENT_SECRET"), "token response has no signature")
+
+	_, err = force.New(force.LoginURL(fake.URL), force.VerifyTokenSignature(),
+		force.ClientCredentials("CLIENT_ID", "CLIENT_SECRET"))
+	assert.Nil(t, err)
+}
+
+func TestIdentity(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "pass", "CLIENT_ID", "CLIENT_SECRET")
+
+	client, err := force.New(force.LoginURL(fake.URL), force.OAuthCred("user@example.com", "pass", "CLIENT_ID",
+		"CLIENT_SECRET"))
+	assert.Nil(t, err)
+
+	identity, _, err := client.Identity()
+	assert.Nil(t, err)
+	assert.Equal(t, client.Token().GetID(), identity.ID)
+	assert.Equal(t, forcetest.UserID, identity.UserID)
+	assert.Equal(t, forcetest.OrganizationID, identity.OrganizationID)
+	assert.Equal(t, "user@example.com", identity.Username)
+	assert.Equal(t, fake.URL+"/services/data/v{version}/", identity.URLs.REST)
+
+	userInfo, _, err := client.UserInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, identity.ID, userInfo.Sub)
+	assert.Equal(t, "user@example.com", userInfo.PreferredUsername)
+	assert.Equal(t, identity.URLs, userInfo.URLs)
+
+	// Clients created from an access token have no identity URL.
+	tokenClient := newFakeClient(t, fake)
+	_, _, err = tokenClient.Identity()
+	assert.NotNil(t, err)
+	_, _, err = tokenClient.UserInfo()
+	assert.Nil(t, err)
+}
+
+func TestRevokeAndIntrospect(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.SetCredentials("user@example.com", "pass", "CLIENT_ID", "CLIENT_SECRET")
+
+	client, err := force.New(force.LoginURL(fake.URL), force.ClientCredentials("CLIENT_ID", "CLIENT_SECRET"))
+	assert.Nil(t, err)
+
+	introspection, _, err := client.Introspect(client.AccessToken())
+	assert.Nil(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, "CLIENT_ID", introspection.ClientID)
+	assert.Equal(t, "access_token", introspection.TokenType)
+	assert.True(t, introspection.ExpiresAt().After(time.Now()))
+
+	_, err = client.Revoke(client.AccessToken())
+	assert.Nil(t, err)
+	assert.NotEqual(t, client.AccessToken(), fake.AccessToken())
+	_, _, err = client.Describe("Account")
+	assert.NotNil(t, err)
+
+	other, err := force.New(force.LoginURL(fake.URL), force.ClientCredentials("CLIENT_ID", "CLIENT_SECRET"))
+	assert.Nil(t, err)
+	introspection, _, err = other.Introspect(client.AccessToken())
+	assert.Nil(t, err)
+	assert.False(t, introspection.Active)
+	assert.True(t, introspection.ExpiresAt().IsZero())
+
+	_, err = other.Revoke(client.AccessToken())
+	assert.NotNil(t, err)
+
+	// Introspection authenticates the connected app.
+	tokenClient := newFakeClient(t, fake)
+	_, _, err = tokenClient.Introspect(fake.AccessToken())
+	assert.NotNil(t, err)
+}
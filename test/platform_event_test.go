@@ -0,0 +1,97 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type orderPlaced struct {
+	OrderNumber string  `json:"Order_Number__c"`
+	Amount      float64 `json:"Amount__c"`
+}
+
+func newEventServer() *forcetest.Server {
+	fake := newFakeServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Order_Placed__e", "e00",
+		forcetest.NewField("Order_Number__c", force.FieldDataTypes.String),
+		forcetest.NewField("Amount__c", force.FieldDataTypes.Double),
+	))
+	return fake
+}
+
+func TestPublishEvent(t *testing.T) {
+	fake := newEventServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+	sub, err := stream.Subscribe("/event/Order_Placed__e", force.ReplayNew)
+	assert.Nil(t, err)
+
+	result, _, err := client.PublishEvent("Order_Placed__e", &orderPlaced{OrderNumber: "O-1", Amount: 9.5})
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.NotEmpty(t, result.ID)
+
+	event := nextEvent(t, sub)
+	assert.Equal(t, result.GetReplayID(), event.ReplayID)
+
+	var payload orderPlaced
+	assert.Nil(t, event.DecodePayload(&payload))
+	assert.Equal(t, orderPlaced{OrderNumber: "O-1", Amount: 9.5}, payload)
+}
+
+func TestPublishEvent_SharesDescribeCache(t *testing.T) {
+	fake := newEventServer()
+	defer fake.Close()
+	counter := &describeCounter{}
+	client := newFakeClient(t, fake, force.Transport(counter))
+
+	_, _, err := client.PublishEvent("Order_Placed__e", &orderPlaced{OrderNumber: "O-1"})
+	assert.Nil(t, err)
+	_, err = client.Validator("order_placed__e")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, counter.describes)
+
+	client.ClearMetadataCache()
+	_, _, err = client.PublishEvent("Order_Placed__e", &orderPlaced{OrderNumber: "O-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, counter.describes)
+}
+
+func TestPublishEvents_Batches(t *testing.T) {
+	fake := newEventServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	payloads := make([]interface{}, 0, force.MaxCollectionSize+1)
+	for i := 0; i < force.MaxCollectionSize+1; i++ {
+		payloads = append(payloads, force.SObject{"Amount__c": i})
+	}
+
+	results, _, err := client.PublishEvents("Order_Placed__e", payloads...)
+	assert.Nil(t, err)
+	assert.Len(t, results, force.MaxCollectionSize+1)
+	for _, r := range results {
+		assert.True(t, r.Success)
+	}
+	assert.True(t, results[len(results)-1].GetReplayID() > results[0].GetReplayID())
+}
+
+func TestPublishEvent_Validation(t *testing.T) {
+	fake := newEventServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	_, _, err := client.PublishEvent("Account", force.SObject{"Name": "Acme"})
+	assert.EqualError(t, err, "Account is not a platform event: name must end with __e")
+
+	_, _, err = client.PublishEvent("Order_Placed__e", force.SObject{"Missing__c": "x"})
+	assert.EqualError(t, err, "field Missing__c does not exist on Order_Placed__e")
+
+	_, _, err = client.PublishEvent("Unknown__e", force.SObject{})
+	assert.NotNil(t, err)
+}
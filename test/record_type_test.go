@@ -0,0 +1,90 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRecordTypes(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	id, err := client.DefaultRecordTypeID("Account")
+	assert.Nil(t, err)
+	assert.Equal(t, force.MasterRecordTypeID, id)
+
+	enterpriseID, err := fake.AddRecordType("Account", &force.SObjectRecordTypeInfoMetadata{
+		DeveloperName: force.String("Enterprise"), DefaultRecordTypeMapping: force.Bool(true)}, nil)
+	assert.Nil(t, err)
+	_, err = fake.AddRecordType("Account", &force.SObjectRecordTypeInfoMetadata{
+		DeveloperName: force.String("Retired"), Available: force.Bool(false)}, nil)
+	assert.Nil(t, err)
+
+	// The describe without record types is still cached.
+	_, err = client.RecordTypeID("Account", "Enterprise")
+	assert.NotNil(t, err)
+	client.ClearMetadataCache()
+
+	id, err = client.RecordTypeID("Account", "enterprise")
+	assert.Nil(t, err)
+	assert.Equal(t, enterpriseID, id)
+	id, err = client.DefaultRecordTypeID("Account")
+	assert.Nil(t, err)
+	assert.Equal(t, enterpriseID, id)
+	_, err = client.RecordTypeID("Account", "Retired")
+	assert.EqualError(t, err, "record type Retired on Account is not available to the current user")
+
+	meta, _, err := client.Describe("Account")
+	assert.Nil(t, err)
+	assert.Equal(t, force.MasterRecordTypeID, meta.MasterRecordType().GetRecordTypeId())
+	assert.Equal(t, "Enterprise", meta.DefaultRecordType().GetDeveloperName())
+
+	record := force.SObject{"Name": "Acme", "RecordType": force.SObject{"DeveloperName": "Enterprise"}}
+	assert.Nil(t, client.ResolveRecordType("Account", record))
+	assert.Equal(t, force.SObject{"Name": "Acme", "RecordTypeId": enterpriseID}, record)
+	assert.NotNil(t, client.ResolveRecordType("Account", force.SObject{"RecordType": force.SObject{"Name": "x"}}))
+
+	// A record type that can't be resolved leaves the record unchanged.
+	record = force.SObject{"Name": "Acme", "RecordType": force.SObject{"DeveloperName": "Retired"}}
+	assert.NotNil(t, client.ResolveRecordType("Account", record))
+	assert.Equal(t, force.SObject{"Name": "Acme", "RecordType": force.SObject{"DeveloperName": "Retired"}}, record)
+}
+
+func TestRecordTypePicklistValues(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.AddSObject(newRegionMetadata())
+	client := newFakeClient(t, fake)
+
+	northID, err := fake.AddRecordType("Region__c", &force.SObjectRecordTypeInfoMetadata{
+		DeveloperName: force.String("North_America")}, map[string][]string{"State__c": {"NY", "ON"}})
+	assert.Nil(t, err)
+
+	state, err := client.RecordTypePicklistField("Region__c", "North_America", "state__c")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"US": 0, "CA": 1}, state.ControllerValues)
+	assert.Len(t, state.Values, 2)
+	assert.Nil(t, state.FindValue("Other"))
+	assert.Equal(t, []int{1}, state.FindValue("ON").ValidFor)
+
+	values, err := client.RecordTypePicklistValues("Region__c", force.MasterRecordTypeID)
+	assert.Nil(t, err)
+	assert.Len(t, values["State__c"].Values, 3)
+	assert.Equal(t, map[string]int{"false": 0, "true": 1}, values["Reason__c"].ControllerValues)
+
+	// Values are cached, so record types added later aren't seen until the cache is cleared.
+	fake.AddSObject(forcetest.NewSObjectMetadata("Region__c", "a02"))
+	values, err = client.RecordTypePicklistValues("Region__c", northID)
+	assert.Nil(t, err)
+	assert.Len(t, values, 3)
+
+	_, err = client.RecordTypePicklistValues("Region__c", "012000000000009AAA")
+	assert.NotNil(t, err)
+
+	// A developer name that can't be resolved returns the lookup error rather than being used as an ID.
+	_, err = client.RecordTypePicklistValues("Region__c", "Enterprise")
+	assert.EqualError(t, err, "record type Enterprise does not exist on Region__c")
+}
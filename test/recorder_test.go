@@ -0,0 +1,154 @@
+package test
+
+import (
+	"bytes"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	fake := newFakeServer()
+	fake.SetCredentials("user@example.com", "s3cret", "client-id", "client-secret")
+	cassette := filepath.Join(t.TempDir(), "account.json")
+	loginURL := fake.URL
+
+	run := func(recorder *force.Recorder) (string, *force.QueryResult) {
+		client, err := force.New(
+			force.LoginURL(loginURL),
+			force.OAuthCred("user@example.com", "s3cret", "client-id", "client-secret"),
+			force.Transport(recorder))
+		assert.Nil(t, err)
+
+		created, _, createErr := client.Create("Account", map[string]interface{}{"Name": "Acme", "Industry": "Energy"})
+		assert.Nil(t, createErr)
+
+		result, _, queryErr := client.Query(&force.QueryRequest{SOQL: "SELECT Name FROM Account"})
+		assert.Nil(t, queryErr)
+		return created.ID, result
+	}
+
+	recorder, err := force.NewRecorder(cassette, force.RecorderModeRecord,
+		force.ScrubFields("Industry"),
+		force.ScrubPattern(regexp.MustCompile(`Acme`), "Company"))
+	assert.Nil(t, err)
+	recordedID, recordedResult := run(recorder)
+	fake.Close()
+
+	data, readErr := ioutil.ReadFile(cassette)
+	assert.Nil(t, readErr)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.NotContains(t, string(data), fake.AccessToken())
+	assert.NotContains(t, string(data), "Energy")
+	assert.NotContains(t, string(data), "Acme")
+
+	// Replay with the fake server shut down so every response must come from the cassette.
+	replayer, err := force.NewRecorder(cassette, force.RecorderModeReplay,
+		force.ScrubFields("Industry"),
+		force.ScrubPattern(regexp.MustCompile(`Acme`), "Company"))
+	assert.Nil(t, err)
+	replayedID, replayedResult := run(replayer)
+
+	assert.Equal(t, recordedID, replayedID)
+	assert.Equal(t, recordedResult.TotalSize, replayedResult.TotalSize)
+	assert.Equal(t, "Company", replayedResult.Records[0]["Name"])
+}
+
+func TestRecorder_ReplayUnmatchedRequest(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	cassette := filepath.Join(t.TempDir(), "empty.json")
+
+	_, err := force.NewRecorder(cassette, force.RecorderModeRecord)
+	assert.Nil(t, err)
+
+	replayer, err := force.NewRecorder(cassette, force.RecorderModeReplay)
+	assert.Nil(t, err)
+	client := newFakeClient(t, fake, force.Transport(replayer))
+
+	_, _, err = client.Describe("Account")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no recorded interaction")
+}
+
+func TestRecorder_BinaryBodies(t *testing.T) {
+	fake := newFakeServer()
+	cassette := filepath.Join(t.TempDir(), "binary.json")
+	content := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe, 0x00, 0xc3, 0x28}
+	fake.HandleApexREST("/files", func(w http.ResponseWriter, r *http.Request) {
+		upload, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(append(upload, content...))
+	})
+	url := fake.URL + "/services/apexrest/files"
+
+	fetch := func(recorder *force.Recorder) []byte {
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(content[:4]))
+		req.Header.Set("Authorization", "Bearer "+forcetest.DefaultAccessToken)
+		resp, err := (&http.Client{Transport: recorder}).Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		return body
+	}
+
+	recorder, err := force.NewRecorder(cassette, force.RecorderModeRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, append(content[:4:4], content...), fetch(recorder))
+	fake.Close()
+	assert.Equal(t, force.BodyEncodingBase64, recorder.Cassette().Interactions[0].Response.BodyEncoding)
+
+	// The replayed body is byte for byte the recorded one.
+	replayer, err := force.NewRecorder(cassette, force.RecorderModeReplay)
+	assert.Nil(t, err)
+	assert.Equal(t, append(content[:4:4], content...), fetch(replayer))
+}
+
+func TestRecorder_ScrubsPersonalData(t *testing.T) {
+	fake := newFakeServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Lead", "00Q",
+		forcetest.NewField("LastName", force.FieldDataTypes.String),
+		forcetest.NewField("Email", force.FieldDataTypes.Email),
+		forcetest.NewField("MobilePhone", force.FieldDataTypes.Phone)))
+	cassette := filepath.Join(t.TempDir(), "lead.json")
+	loginURL := fake.URL
+
+	run := func(recorder *force.Recorder) *force.Identity {
+		client, err := force.New(force.LoginURL(loginURL),
+			force.OAuthCred("user@example.com", "s3cret", "client-id", "client-secret"), force.Transport(recorder))
+		assert.Nil(t, err)
+
+		_, _, err = client.Create("Lead", force.SObject{"LastName": "Smith", "Email": "jane@example.com",
+			"MobilePhone": "+1 555 0100"})
+		assert.Nil(t, err)
+		identity, _, err := client.Identity()
+		assert.Nil(t, err)
+		return identity
+	}
+
+	recorder, err := force.NewRecorder(cassette, force.RecorderModeRecord)
+	assert.Nil(t, err)
+	recorded := run(recorder)
+	fake.Close()
+
+	data, err := ioutil.ReadFile(cassette)
+	assert.Nil(t, err)
+	for _, value := range []string{"jane@example.com", "+1 555 0100", "user@example.com", forcetest.OrganizationID[:15],
+		"/id/" + forcetest.OrganizationID + "/" + forcetest.UserID} {
+		assert.NotContains(t, string(data), value)
+	}
+	assert.Contains(t, string(data), "Smith")
+
+	replayer, err := force.NewRecorder(cassette, force.RecorderModeReplay)
+	assert.Nil(t, err)
+	replayed := run(replayer)
+	assert.Equal(t, force.ScrubbedValue, replayed.Email)
+	assert.Equal(t, force.ScrubbedValue, replayed.UserID)
+	assert.Equal(t, recorded.Timezone, replayed.Timezone)
+}
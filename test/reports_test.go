@@ -0,0 +1,120 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newReportServer() (*forcetest.Server, string) {
+	fake := newFakeServer()
+	id := fake.AddReport(&force.ReportMetadata{Name: "Pipeline", DeveloperName: "Pipeline",
+		DetailColumns: []string{"OPPORTUNITY_NAME", "AMOUNT", "CLOSE_DATE", "WON"},
+		Aggregates:    []string{"s!AMOUNT", "RowCount"},
+	}, map[string]*force.ReportColumnInfo{
+		"OPPORTUNITY_NAME": {Label: "Opportunity Name", DataType: "string"},
+		"STAGE_NAME":       {Label: "Stage", DataType: "picklist"},
+		"AMOUNT":           {Label: "Amount", DataType: "currency"},
+		"CLOSE_DATE":       {Label: "Close Date", DataType: "date"},
+		"WON":              {Label: "Won", DataType: "boolean"},
+	},
+		force.SObject{"OPPORTUNITY_NAME": "Acme", "STAGE_NAME": "Closed Won", "AMOUNT": 1500, "CLOSE_DATE": "2020-03-01", "WON": true},
+		force.SObject{"OPPORTUNITY_NAME": "Globex", "STAGE_NAME": "Prospecting", "AMOUNT": 200, "CLOSE_DATE": "2020-04-15", "WON": false},
+		force.SObject{"OPPORTUNITY_NAME": "Initech", "STAGE_NAME": "Closed Won", "AMOUNT": nil, "CLOSE_DATE": "2020-05-20", "WON": true},
+	)
+	fake.AddDashboard(&force.Dashboard{Name: "Sales"})
+	return fake, id
+}
+
+func TestReports_ListAndDescribe(t *testing.T) {
+	fake, id := newReportServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	reports, _, err := client.Reports.List()
+	assert.Nil(t, err)
+	assert.Len(t, reports, 1)
+	assert.Equal(t, id, reports[0].ID)
+
+	describe, _, err := client.Reports.Describe(id)
+	assert.Nil(t, err)
+	assert.Equal(t, force.ReportFormats.Tabular, describe.ReportMetadata.ReportFormat)
+	assert.Equal(t, "currency", describe.ReportExtendedMetadata.DetailColumnInfo["AMOUNT"].DataType)
+	assert.Equal(t, "Sum of Amount", describe.ReportExtendedMetadata.AggregateColumnInfo["s!AMOUNT"].Label)
+
+	dashboards, _, err := client.Dashboards.List()
+	assert.Nil(t, err)
+	assert.Len(t, dashboards, 1)
+	assert.Equal(t, "Sales", dashboards[0].Name)
+
+	_, _, err = client.Reports.Describe("00O000000000000AAA")
+	assert.NotNil(t, err)
+}
+
+func TestReports_RunTabular(t *testing.T) {
+	fake, id := newReportServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	result, _, err := client.Reports.Run(id, &force.ReportRunRequest{IncludeDetails: true,
+		ReportMetadata: &force.ReportMetadata{ReportFilters: []*force.ReportFilter{
+			{Column: "STAGE_NAME", Operator: force.ReportFilterOperators.Equals, Value: "Closed Won"},
+		}}})
+	assert.Nil(t, err)
+
+	rows, err := result.Rows()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "Acme", rows[0].Get("OPPORTUNITY_NAME").Value)
+	assert.Equal(t, 1500.0, rows[0].Get("AMOUNT").Value)
+	assert.Equal(t, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), rows[0].Get("CLOSE_DATE").Value)
+	assert.Equal(t, true, rows[0].Get("won").Value)
+	assert.Nil(t, rows[1].Values()["AMOUNT"])
+
+	result, _, err = client.Reports.Run(id, nil)
+	assert.Nil(t, err)
+	rows, err = result.Rows()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, map[string]interface{}{"s!AMOUNT": 1700.0, "RowCount": 3.0}, rows[0].Values())
+
+	_, _, err = client.Reports.Run(id, &force.ReportRunRequest{ReportMetadata: &force.ReportMetadata{
+		ReportFilters: []*force.ReportFilter{{Column: "MISSING", Operator: "equals", Value: "x"}}}})
+	assert.NotNil(t, err)
+}
+
+func TestReports_RunSummaryAndWait(t *testing.T) {
+	fake, id := newReportServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	summary := &force.ReportMetadata{ReportFormat: force.ReportFormats.Summary,
+		GroupingsDown: []*force.ReportGrouping{{Name: "STAGE_NAME", SortOrder: "Asc"}}}
+
+	result, err := client.Reports.RunAndWait(id, &force.ReportRunRequest{IncludeDetails: true, ReportMetadata: summary},
+		force.PollInterval(time.Millisecond))
+	assert.Nil(t, err)
+	assert.Equal(t, force.ReportInstanceStatuses.Success, result.Attributes.Status)
+
+	rows, err := result.Rows()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "Closed Won", rows[0].Get("STAGE_NAME").Value)
+	assert.Equal(t, "Initech", rows[1].Get("OPPORTUNITY_NAME").Value)
+	assert.Equal(t, "Prospecting", rows[2].Groupings[0].Label)
+
+	result, err = client.Reports.RunAndWait(id, &force.ReportRunRequest{ReportMetadata: summary},
+		force.PollInterval(time.Millisecond))
+	assert.Nil(t, err)
+	rows, err = result.Rows()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 1500.0, rows[0].Get("s!AMOUNT").Value)
+	assert.Equal(t, 2.0, rows[0].Get("RowCount").Value)
+
+	instances, _, err := client.Reports.Instances(id)
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"bytes"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newIndustryField(length int, values ...string) *force.SObjectFieldMetadata {
+	f := forcetest.NewField("Industry", force.FieldDataTypes.Picklist)
+	f.Length = force.Int(length)
+	for _, v := range values {
+		f.PicklistValues = append(f.PicklistValues, force.SObjectFieldPicklistEntryMetadata{
+			Value: force.String(v), Active: force.Bool(true)})
+	}
+	return f
+}
+
+func TestDiffSchemas(t *testing.T) {
+	sourceAccount := forcetest.NewSObjectMetadata("Account", "001",
+		forcetest.NewField("Name", force.FieldDataTypes.String), newIndustryField(40, "Energy", "Other"),
+		forcetest.NewField("Legacy__c", force.FieldDataTypes.String))
+	sourceAccount.ChildRelationships = []*force.SObjectChildRelationshipMetadata{
+		{RelationshipName: force.String("Contacts"), ChildSObject: force.String("Contact"),
+			Field: force.String("AccountId"), CascadeDelete: force.Bool(false)},
+		{RelationshipName: force.String("Invoices__r"), ChildSObject: force.String("Invoice__c"),
+			Field: force.String("Account__c")},
+	}
+
+	name := forcetest.NewField("Name", force.FieldDataTypes.String)
+	name.Nillable = force.Bool(false)
+	score := forcetest.NewField("Score__c", force.FieldDataTypes.Double)
+	score.CalculatedFormula = force.String("AnnualRevenue / 1000")
+	targetAccount := forcetest.NewSObjectMetadata("Account", "001", name, newIndustryField(255, "Energy", "Mining"),
+		score)
+	targetAccount.ChildRelationships = []*force.SObjectChildRelationshipMetadata{
+		{RelationshipName: force.String("Contacts"), ChildSObject: force.String("Contact"),
+			Field: force.String("AccountId"), CascadeDelete: force.Bool(true)},
+	}
+
+	contact := forcetest.NewSObjectMetadata("Contact", "003")
+	diff := force.DiffSchemas(
+		[]*force.SObjectMetadata{sourceAccount, contact},
+		[]*force.SObjectMetadata{targetAccount, contact, forcetest.NewSObjectMetadata("Invoice__c", "a01")})
+
+	assert.Len(t, diff.Objects, 2)
+	account := diff.Objects[0]
+	assert.Equal(t, "Account", account.Name)
+	assert.Equal(t, force.SchemaChanges.Changed, account.Change)
+	assert.Equal(t, &force.ObjectDiff{Name: "Invoice__c", Change: force.SchemaChanges.Added}, diff.Objects[1])
+
+	assert.Len(t, account.Fields, 4)
+	assert.Equal(t, "Industry", account.Fields[0].Name)
+	assert.Equal(t, []*force.PropertyChange{{Name: "length", Source: 40, Target: 255}}, account.Fields[0].Properties)
+	assert.Equal(t, []string{"Mining"}, account.Fields[0].PicklistValuesAdded)
+	assert.Equal(t, []string{"Other"}, account.Fields[0].PicklistValuesRemoved)
+	assert.Equal(t, force.SchemaChanges.Removed, account.Fields[1].Change)
+	assert.Equal(t, []*force.PropertyChange{{Name: "required", Source: false, Target: true}},
+		account.Fields[2].Properties)
+	assert.Equal(t, force.SchemaChanges.Added, account.Fields[3].Change)
+
+	var buf bytes.Buffer
+	assert.Nil(t, diff.WriteReport(&buf))
+	assert.Equal(t, `~ Account
+    ~ field Industry: length 40 -> 255, picklist values +Mining -Other
+    - field Legacy__c
+    ~ field Name: required false -> true
+    + field Score__c
+    ~ relationship Contacts: cascadeDelete false -> true
+    - relationship Invoices__r
++ Invoice__c
+`, buf.String())
+
+	assert.True(t, force.DiffSchemas([]*force.SObjectMetadata{contact}, []*force.SObjectMetadata{contact}).IsEmpty())
+
+	// Custom objects get a different key prefix in every org.
+	assert.True(t, force.DiffSchemas(
+		[]*force.SObjectMetadata{forcetest.NewSObjectMetadata("Invoice__c", "a01")},
+		[]*force.SObjectMetadata{forcetest.NewSObjectMetadata("Invoice__c", "a0B")}).IsEmpty())
+}
+
+func TestCompareSchemas(t *testing.T) {
+	source := newFakeServer()
+	defer source.Close()
+	target := newFakeServer()
+	defer target.Close()
+	target.AddSObject(forcetest.NewSObjectMetadata("Account", "001",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Industry", force.FieldDataTypes.String),
+	))
+	target.AddSObject(forcetest.NewSObjectMetadata("Invoice__c", "a01"))
+
+	diff, err := force.CompareSchemas(newFakeClient(t, source), newFakeClient(t, target))
+	assert.Nil(t, err)
+	assert.Len(t, diff.Objects, 2)
+	assert.Equal(t, []*force.PropertyChange{{Name: "type", Source: "picklist", Target: "string"}},
+		diff.Objects[0].Fields[0].Properties)
+	assert.Equal(t, force.SchemaChanges.Added, diff.Objects[1].Change)
+
+	diff, err = force.CompareSchemas(newFakeClient(t, source), newFakeClient(t, target), "Contact", "Invoice__c")
+	assert.Nil(t, err)
+	assert.Len(t, diff.Objects, 1)
+	assert.Equal(t, "Invoice__c", diff.Objects[0].Name)
+}
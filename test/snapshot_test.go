@@ -0,0 +1,155 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.AddSObject(newInvoiceMetadata())
+	client := newFakeClient(t, fake)
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	snapshot, err := client.Snapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Account", "Contact", "Invoice__c"}, snapshot.ObjectNames())
+	assert.Nil(t, snapshot.Save(dir))
+	_, err = os.Stat(filepath.Join(dir, "sobjects", "Invoice__c.json"))
+	assert.Nil(t, err)
+
+	// The snapshot works without the org.
+	fake.Close()
+	loaded, err := force.LoadSnapshot(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, force.SnapshotFormatVersion, loaded.Manifest.FormatVersion)
+	assert.Equal(t, force.DefaultAPIVersion, loaded.Manifest.APIVersion)
+	assert.Equal(t, snapshot.ObjectNames(), loaded.ObjectNames())
+
+	v, err := loaded.Validator("invoice__c")
+	assert.Nil(t, err)
+	contactID := "003000000000001AAA"
+	_, ok := v.ValidateCreate(force.SObject{"Name": "INV-1", "Account__c": contactID}).(*force.ValidationError)
+	assert.True(t, ok)
+
+	query, err := loaded.BaseSObjectQuery("Contact")
+	assert.Nil(t, err)
+	assert.Equal(t, "select Id,CreatedDate,LastModifiedDate,SystemModstamp,LastName,AccountId from Contact ", query)
+
+	name, ok := loaded.KeyPrefixes().ObjectName(contactID)
+	assert.True(t, ok)
+	assert.Equal(t, "Contact", name)
+
+	_, err = loaded.Describe("Opportunity")
+	assert.EqualError(t, err, "sobject Opportunity is not in the snapshot")
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"formatVersion": 2}`), 0644))
+	_, err = force.LoadSnapshot(dir)
+	assert.NotNil(t, err)
+}
+
+func TestGenerateStructs(t *testing.T) {
+	snapshot := force.NewSnapshot(force.DefaultAPIVersion,
+		forcetest.NewSObjectMetadata("Billing_Invoice__c", "a01",
+			forcetest.NewField("Amount__c", force.FieldDataTypes.Currency),
+			forcetest.NewField("Paid__c", force.FieldDataTypes.Boolean),
+			forcetest.NewReferenceField("Account__c", "Account__r", "Account"),
+			forcetest.NewReferenceField("OwnerId", "Owner", "User"),
+		))
+
+	src, err := snapshot.GenerateStructs("models", "Billing_Invoice__c")
+	assert.Nil(t, err)
+	assert.Equal(t, "// Code generated by force-go. DO NOT EDIT.\n\n"+
+		"package models\n\n"+
+		"import \"encoding/json\"\n\n"+
+		"// BillingInvoice is generated from the describe of the Billing_Invoice__c SObject.\n"+
+		"type BillingInvoice struct {\n"+
+		"\tID               string       `json:\"Id,omitempty\"`\n"+
+		"\tCreatedDate      string       `json:\"CreatedDate,omitempty\"`\n"+
+		"\tLastModifiedDate string       `json:\"LastModifiedDate,omitempty\"`\n"+
+		"\tSystemModstamp   string       `json:\"SystemModstamp,omitempty\"`\n"+
+		"\tAmount           *json.Number `json:\"Amount__c,omitempty\"`\n"+
+		"\tPaid             *bool        `json:\"Paid__c,omitempty\"`\n"+
+		"\tAccountID        string       `json:\"Account__c,omitempty\"`\n"+
+		"\tOwnerID          string       `json:\"OwnerId,omitempty\"`\n"+
+		"}\n", string(src))
+
+	_, err = snapshot.GenerateStructs("models", "Account")
+	assert.NotNil(t, err)
+}
+
+// generatedAccount is the struct GenerateStructs declares for the Account in TestGenerateStructs_RoundTrip.
+type generatedAccount struct {
+	ID                string                 `json:"Id,omitempty"`
+	CreatedDate       string                 `json:"CreatedDate,omitempty"`
+	LastModifiedDate  string                 `json:"LastModifiedDate,omitempty"`
+	SystemModstamp    string                 `json:"SystemModstamp,omitempty"`
+	Name              string                 `json:"Name,omitempty"`
+	BillingAddress    map[string]interface{} `json:"BillingAddress,omitempty"`
+	Active            *bool                  `json:"Active__c,omitempty"`
+	NumberOfEmployees *int                   `json:"NumberOfEmployees,omitempty"`
+	AnnualRevenue     *json.Number           `json:"AnnualRevenue,omitempty"`
+}
+
+func TestGenerateStructs_RoundTrip(t *testing.T) {
+	fake := forcetest.NewServer()
+	defer fake.Close()
+	fake.AddSObject(forcetest.NewSObjectMetadata("Account", "001",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("BillingAddress", force.FieldDataTypes.Address),
+		forcetest.NewField("Active__c", force.FieldDataTypes.Boolean),
+		forcetest.NewField("NumberOfEmployees", force.FieldDataTypes.Int),
+		forcetest.NewField("AnnualRevenue", force.FieldDataTypes.Currency),
+	))
+	client := newFakeClient(t, fake)
+
+	snapshot, err := client.Snapshot("Account")
+	assert.Nil(t, err)
+	src, err := snapshot.GenerateStructs("models", "Account")
+	assert.Nil(t, err)
+	declaration := "type Account struct {\n" +
+		"\tID                string                 `json:\"Id,omitempty\"`\n" +
+		"\tCreatedDate       string                 `json:\"CreatedDate,omitempty\"`\n" +
+		"\tLastModifiedDate  string                 `json:\"LastModifiedDate,omitempty\"`\n" +
+		"\tSystemModstamp    string                 `json:\"SystemModstamp,omitempty\"`\n" +
+		"\tName              string                 `json:\"Name,omitempty\"`\n" +
+		"\tBillingAddress    map[string]interface{} `json:\"BillingAddress,omitempty\"`\n" +
+		"\tActive            *bool                  `json:\"Active__c,omitempty\"`\n" +
+		"\tNumberOfEmployees *int                   `json:\"NumberOfEmployees,omitempty\"`\n" +
+		"\tAnnualRevenue     *json.Number           `json:\"AnnualRevenue,omitempty\"`\n" +
+		"}\n"
+	assert.Contains(t, string(src), declaration)
+
+	// Records of the base query decode into the generated struct, compound fields included.
+	_, err = fake.Insert("Account", force.SObject{"Name": "Acme", "Active__c": false, "NumberOfEmployees": 0,
+		"AnnualRevenue": 1250.5, "BillingAddress": map[string]interface{}{"city": "Paris", "country": "France"}})
+	assert.Nil(t, err)
+	query, err := snapshot.BaseSObjectQuery("Account")
+	assert.Nil(t, err)
+	result, _, err := client.Query(&force.QueryRequest{SOQL: query})
+	assert.Nil(t, err)
+	assert.Len(t, result.Records, 1)
+
+	data, err := json.Marshal(result.Records[0])
+	assert.Nil(t, err)
+	var account generatedAccount
+	assert.Nil(t, json.Unmarshal(data, &account))
+	assert.Equal(t, "Paris", account.BillingAddress["city"])
+	assert.Equal(t, "1250.5", account.AnnualRevenue.String())
+
+	// False and 0 are written while unset fields are omitted.
+	data, err = json.Marshal(&generatedAccount{ID: account.ID, Active: account.Active,
+		NumberOfEmployees: account.NumberOfEmployees})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Id": "`+account.ID+`", "Active__c": false, "NumberOfEmployees": 0}`, string(data))
+}
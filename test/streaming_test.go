@@ -0,0 +1,125 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, sub *force.Subscription) *force.StreamingEvent {
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatal("events channel closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return nil
+}
+
+func newStreamingClient(t *testing.T, fake *forcetest.Server) *force.StreamingClient {
+	stream, err := newFakeClient(t, fake).Streaming(force.StreamingRetryInterval(10 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
+func TestStreaming_PushTopic(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+
+	sub, err := stream.Subscribe("/topic/AccountUpdates", force.ReplayNew)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, fake.Subscribers("/topic/AccountUpdates"))
+
+	replayID := fake.PublishPushTopic("AccountUpdates", "created", force.SObject{"Id": "001000000000001AAA", "Name": "Acme"})
+
+	event := nextEvent(t, sub)
+	assert.Equal(t, "/topic/AccountUpdates", event.Channel)
+	assert.Equal(t, replayID, event.ReplayID)
+	assert.Equal(t, "created", event.Type)
+	assert.Equal(t, "Acme", event.SObject["Name"])
+
+	last, _ := stream.ReplayID("/topic/AccountUpdates")
+	assert.Equal(t, replayID, last)
+
+	assert.Nil(t, sub.Unsubscribe())
+	_, open := <-sub.Events()
+	assert.False(t, open)
+	assert.Equal(t, 0, fake.Subscribers("/topic/AccountUpdates"))
+}
+
+func TestStreaming_ReplayAll(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+
+	fake.Publish("/event/Order_Placed__e", map[string]interface{}{"payload": map[string]interface{}{"Amount__c": 10}})
+	fake.Publish("/event/Order_Placed__e", map[string]interface{}{"payload": map[string]interface{}{"Amount__c": 20}})
+
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+
+	sub, err := stream.Subscribe("/event/Order_Placed__e", force.ReplayAll)
+	assert.Nil(t, err)
+
+	for _, amount := range []int{10, 20} {
+		var payload struct {
+			Amount int `json:"Amount__c"`
+		}
+		assert.Nil(t, nextEvent(t, sub).DecodePayload(&payload))
+		assert.Equal(t, amount, payload.Amount)
+	}
+}
+
+func TestStreaming_ResubscribesAfterHandshakeAdvice(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+
+	sub, err := stream.Subscribe("/u/Notifications", force.ReplayNew)
+	assert.Nil(t, err)
+	fake.Publish("/u/Notifications", map[string]interface{}{"payload": "first"})
+	assert.Equal(t, []byte(`"first"`), []byte(nextEvent(t, sub).Payload))
+
+	clientID := stream.ClientID()
+	fake.DropStreamingClients()
+
+	// Published while the client is re-handshaking, so it must be replayed from the last received replay ID.
+	fake.Publish("/u/Notifications", map[string]interface{}{"payload": "second"})
+	assert.Equal(t, []byte(`"second"`), []byte(nextEvent(t, sub).Payload))
+	assert.NotEqual(t, clientID, stream.ClientID())
+}
+
+func TestStreaming_InvalidChannel(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	stream := newStreamingClient(t, fake)
+	defer stream.Close()
+
+	_, err := stream.Subscribe("/invalid", force.ReplayNew)
+	assert.NotNil(t, err)
+}
+
+func TestStreaming_CloseEndsSubscriptions(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	stream := newStreamingClient(t, fake)
+
+	sub, err := stream.Subscribe("/topic/AccountUpdates", force.ReplayNew)
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Close())
+
+	_, open := <-sub.Events()
+	assert.False(t, open)
+	assert.Nil(t, stream.Err())
+	_, err = stream.Subscribe("/topic/Other", force.ReplayNew)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tableSink is a SyncSink that keeps the synced records of each SObject in memory.
+type tableSink struct {
+	mu     sync.Mutex
+	tables map[string]map[string]force.SObject
+}
+
+func (t *tableSink) Upsert(objectName string, records []force.SObject) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tables[objectName] == nil {
+		t.tables[objectName] = map[string]force.SObject{}
+	}
+	for _, r := range records {
+		t.tables[objectName][r["Id"].(string)] = r
+	}
+	return nil
+}
+
+func (t *tableSink) Delete(objectName string, records []*force.DeletedRecord) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, r := range records {
+		delete(t.tables[objectName], r.ID)
+	}
+	return nil
+}
+
+func TestGetUpdatedAndDeleted(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	start := time.Now().Add(-time.Hour)
+	acme, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	globex, _ := fake.Insert("Account", force.SObject{"Name": "Globex"})
+	_, err := client.Destroy("Account", globex)
+	assert.Nil(t, err)
+
+	updated, _, err := client.GetUpdated("Account", start, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{acme}, updated.IDs)
+	assert.NotEmpty(t, updated.LatestDateCovered)
+
+	deleted, _, err := client.GetDeleted("Account", start, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Len(t, deleted.DeletedRecords, 1)
+	assert.Equal(t, globex, deleted.DeletedRecords[0].ID)
+
+	_, _, err = client.GetUpdated("Account", time.Now().Add(-force.ReplicationRetention-time.Hour), time.Now())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "INVALID_REPLICATION_DATE")
+
+	_, _, err = client.GetDeleted("Account", start, start)
+	assert.NotNil(t, err)
+}
+
+func TestSyncer(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	acme, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	globex, _ := fake.Insert("Account", force.SObject{"Name": "Globex"})
+	_, _ = fake.Insert("Contact", force.SObject{"LastName": "Smith", "AccountId": acme})
+
+	path := filepath.Join(t.TempDir(), "watermarks.json")
+	store, err := force.NewFileWatermarkStore(path)
+	assert.Nil(t, err)
+	sink := &tableSink{tables: map[string]map[string]force.SObject{}}
+	syncer, err := force.NewSyncer(client, store, sink, force.SyncFields("Account", "Id", "Name"), force.SyncBatchSize(1))
+	assert.Nil(t, err)
+
+	time.Sleep(time.Second)
+	results, err := syncer.Sync("Account", "Contact")
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, results[0].Upserted)
+	assert.Equal(t, 1, results[1].Upserted)
+	assert.Len(t, sink.tables["Account"], 2)
+	assert.Equal(t, "Acme", sink.tables["Account"][acme]["Name"])
+	assert.Equal(t, "Smith", sink.tables["Contact"][fake.Records("Contact")[0]["Id"].(string)]["LastName"])
+
+	_, err = client.Update("Account", acme, force.SObject{"Name": "Acme Corp"})
+	assert.Nil(t, err)
+	_, err = client.Destroy("Account", globex)
+	assert.Nil(t, err)
+	time.Sleep(time.Second)
+
+	// A new store reading the same file resumes from the saved watermark.
+	reloaded, err := force.NewFileWatermarkStore(path)
+	assert.Nil(t, err)
+	watermark, ok, err := reloaded.Load("Account")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, results[0].Watermark.UTC(), watermark.UTC())
+
+	syncer, err = force.NewSyncer(client, reloaded, sink, force.SyncFields("Account", "Id", "Name"))
+	assert.Nil(t, err)
+	result, err := syncer.SyncObject("Account")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Upserted)
+	assert.Equal(t, 1, result.Deleted)
+	assert.True(t, result.Watermark.After(watermark))
+	assert.Len(t, sink.tables["Account"], 1)
+	assert.Equal(t, "Acme Corp", sink.tables["Account"][acme]["Name"])
+}
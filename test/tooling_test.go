@@ -0,0 +1,79 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newToolingServer() *forcetest.Server {
+	fake := newFakeServer()
+	fake.AddSObject(forcetest.NewSObjectMetadata("ApexClass", "01p",
+		forcetest.NewField("Name", force.FieldDataTypes.String),
+		forcetest.NewField("Body", force.FieldDataTypes.Textarea),
+		forcetest.NewField("Status", force.FieldDataTypes.Picklist),
+	))
+	return fake
+}
+
+func TestTooling_CRUDAndQuery(t *testing.T) {
+	fake := newToolingServer()
+	defer fake.Close()
+	fake.SetPageSize(1)
+	client := newFakeClient(t, fake)
+
+	created, _, err := client.Tooling.Create("ApexClass", force.SObject{"Name": "Greeter", "Body": "public class Greeter {}"})
+	assert.Nil(t, err)
+	_, _ = fake.Insert("ApexClass", force.SObject{"Name": "Helper", "Status": "Active"})
+
+	_, err = client.Tooling.Update("ApexClass", created.ID, force.SObject{"Status": "Active"})
+	assert.Nil(t, err)
+
+	record, _, err := client.Tooling.Get("ApexClass", created.ID, "Name", "Status")
+	assert.Nil(t, err)
+	assert.Equal(t, "Active", record["Status"])
+
+	records, err := client.Tooling.QueryAll(&force.QueryRequest{SOQL: "SELECT Name FROM ApexClass WHERE Status = 'Active' ORDER BY Name"})
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "Greeter", records[0]["Name"])
+
+	meta, _, err := client.Tooling.Describe("ApexClass")
+	assert.Nil(t, err)
+	assert.NotNil(t, meta.FindField("body"))
+
+	_, err = client.Tooling.Destroy("ApexClass", created.ID)
+	assert.Nil(t, err)
+}
+
+func TestTooling_ExecuteAnonymous(t *testing.T) {
+	fake := newToolingServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	var executed string
+	fake.SetAnonymousApex(func(apex string) *force.ExecuteAnonymousResult {
+		executed = apex
+		return &force.ExecuteAnonymousResult{Line: 1, Column: 8, CompileProblem: force.String("Unexpected token '+'.")}
+	})
+
+	result, _, err := client.Tooling.ExecuteAnonymous("System.debug(1 + );")
+	assert.Nil(t, err)
+	assert.Equal(t, "System.debug(1 + );", executed)
+	assert.False(t, result.Compiled)
+	assert.Equal(t, 8, result.Column)
+	assert.Equal(t, "Unexpected token '+'.", result.GetCompileProblem())
+}
+
+func TestTooling_RunTestsAsynchronous(t *testing.T) {
+	fake := newToolingServer()
+	defer fake.Close()
+	client := newFakeClient(t, fake)
+
+	jobID, _, err := client.Tooling.RunTestsAsynchronous(&force.RunTestsRequest{
+		Tests: []*force.TestItem{{ClassName: "GreeterTest"}}, TestLevel: force.TestLevels.RunSpecifiedTests})
+	assert.Nil(t, err)
+	assert.Len(t, jobID, 18)
+	assert.Equal(t, "707", jobID[:3])
+}
@@ -0,0 +1,249 @@
+package test
+
+import (
+	"github.com/davidji99/force-go/force"
+	"github.com/davidji99/force-go/forcetest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newInvoiceMetadata() *force.SObjectMetadata {
+	name := forcetest.NewField("Name", force.FieldDataTypes.String)
+	name.Length = force.Int(10)
+	name.Nillable = force.Bool(false)
+
+	amount := forcetest.NewField("Amount__c", force.FieldDataTypes.Currency)
+	amount.Precision = force.Int(5)
+	amount.Scale = force.Int(2)
+
+	quantity := forcetest.NewField("Quantity__c", force.FieldDataTypes.Int)
+	quantity.Digits = force.Int(3)
+
+	status := forcetest.NewField("Status__c", force.FieldDataTypes.Picklist)
+	status.RestrictedPicklist = force.Bool(true)
+	status.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{
+		{Value: force.String("Draft"), Active: force.Bool(true)},
+		{Value: force.String("Sent"), Active: force.Bool(true)},
+		{Value: force.String("Legacy"), Active: force.Bool(false)},
+	}
+
+	total := forcetest.NewField("Total__c", force.FieldDataTypes.Double)
+	total.Createable = force.Bool(false)
+	total.Updateable = force.Bool(false)
+
+	return forcetest.NewSObjectMetadata("Invoice__c", "a01", name, amount, quantity, status, total,
+		forcetest.NewField("Paid__c", force.FieldDataTypes.Boolean),
+		forcetest.NewField("Due__c", force.FieldDataTypes.Date),
+		forcetest.NewReferenceField("Account__c", "Account__r", "Account"),
+	)
+}
+
+func TestValidator(t *testing.T) {
+	account := forcetest.NewSObjectMetadata("Account", "001")
+	v := force.NewValidator(newInvoiceMetadata(), account)
+
+	assert.Nil(t, v.ValidateCreate(force.SObject{"Name": "INV-1", "Amount__c": 999.99, "Quantity__c": 12,
+		"Status__c": "Draft", "Paid__c": false, "Due__c": "2020-06-30", "Account__c": "001000000000001AAA"}))
+	assert.Nil(t, v.ValidateCreate(struct {
+		Name    string                 `json:"Name"`
+		Account map[string]interface{} `json:"Account__r"`
+	}{Name: "INV-2", Account: map[string]interface{}{"External_ID__c": "A-1"}}))
+
+	err := v.ValidateCreate(force.SObject{"Amount__c": 1000, "Quantity__c": 1.5, "Status__c": "Legacy",
+		"Paid__c": "yes", "Due__c": "30/06/2020", "Account__c": "003000000000001AAA", "Total__c": 1,
+		"Unknown__c": "x"})
+	validationErr, ok := err.(*force.ValidationError)
+	assert.True(t, ok)
+	codes := map[string]string{}
+	for _, e := range validationErr.Errors {
+		codes[e.Fields[0]] = e.StatusCode
+	}
+	assert.Equal(t, map[string]string{
+		"Account__c":  "FIELD_INTEGRITY_EXCEPTION",
+		"Amount__c":   "NUMBER_OUTSIDE_VALID_RANGE",
+		"Due__c":      "INVALID_TYPE_ON_FIELD_IN_RECORD",
+		"Name":        "REQUIRED_FIELD_MISSING",
+		"Paid__c":     "INVALID_TYPE_ON_FIELD_IN_RECORD",
+		"Quantity__c": "INVALID_TYPE_ON_FIELD_IN_RECORD",
+		"Status__c":   "INVALID_OR_NULL_FOR_RESTRICTED_PICKLIST",
+		"Total__c":    "INVALID_FIELD_FOR_INSERT_UPDATE",
+		"Unknown__c":  "INVALID_FIELD",
+	}, codes)
+
+	err = v.ValidateUpdate(force.SObject{"Id": "a01000000000001AAA", "Name": "INV-0000001", "Quantity__c": 1000})
+	validationErr, ok = err.(*force.ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "STRING_TOO_LONG", validationErr.Errors[0].StatusCode)
+	assert.Equal(t, "NUMBER_OUTSIDE_VALID_RANGE", validationErr.Errors[1].StatusCode)
+
+	err = v.ValidateUpdate(force.SObject{"Name": nil})
+	assert.EqualError(t, err, "invalid Invoice__c record: REQUIRED_FIELD_MISSING: Required fields are missing: "+
+		"[Name] (Name)")
+	assert.Nil(t, v.ValidateUpdate(force.SObject{"Status__c": "Sent"}))
+}
+
+func TestValidateWrites(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.AddSObject(newInvoiceMetadata())
+	client := newFakeClient(t, fake, force.ValidateWrites())
+
+	accountID, _ := fake.Insert("Account", force.SObject{"Name": "Acme"})
+	contactID, _ := fake.Insert("Contact", force.SObject{"LastName": "Smith"})
+
+	_, _, err := client.Create("Invoice__c", force.SObject{"Name": "INV-1", "Account__c": contactID})
+	_, ok := err.(*force.ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, fake.Records("Invoice__c"), 0)
+
+	created, _, err := client.Create("Invoice__c", force.SObject{"Name": "INV-1", "Account__c": accountID})
+	assert.Nil(t, err)
+	assert.Len(t, fake.Records("Invoice__c"), 1)
+
+	_, err = client.Update("Invoice__c", created.ID, force.SObject{"Status__c": "Void"})
+	assert.NotNil(t, err)
+	record, _ := fake.Record("Invoice__c", created.ID)
+	assert.Nil(t, record["Status__c"])
+
+	_, err = client.Update("Invoice__c", created.ID, force.SObject{"Status__c": "Sent"})
+	assert.Nil(t, err)
+}
+
+// describeCounter counts describe requests and fails the describe global request if failGlobal is set.
+type describeCounter struct {
+	describes  int
+	failGlobal bool
+}
+
+func (c *describeCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/describe") {
+		c.describes++
+	}
+	if c.failGlobal && strings.HasSuffix(req.URL.Path, "/sobjects") {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{},
+			Body: ioutil.NopCloser(strings.NewReader(`[{"errorCode":"UNKNOWN_EXCEPTION"}]`)), Request: req}, nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestValidator_PolymorphicReference(t *testing.T) {
+	fake := newFakeServer()
+	defer fake.Close()
+	fake.AddSObject(newInvoiceMetadata())
+	what := forcetest.NewReferenceField("WhatId", "What", "Account", "Invoice__c")
+	fake.AddSObject(forcetest.NewSObjectMetadata("Task", "00T", what))
+	counter := &describeCounter{}
+	client := newFakeClient(t, fake, force.ValidateWrites(), force.Transport(counter))
+
+	contactID, _ := fake.Insert("Contact", force.SObject{"LastName": "Smith"})
+	invoiceID, _ := fake.Insert("Invoice__c", force.SObject{"Name": "INV-1"})
+
+	_, _, err := client.Create("Task", force.SObject{"WhatId": contactID})
+	_, ok := err.(*force.ValidationError)
+	assert.True(t, ok)
+	_, _, err = client.Create("Task", force.SObject{"WhatId": invoiceID})
+	assert.Nil(t, err)
+
+	// Only Task is described; the targets' key prefixes come from describe global.
+	assert.Equal(t, 1, counter.describes)
+
+	failing := newFakeClient(t, fake, force.Transport(&describeCounter{failGlobal: true}))
+	_, err = failing.Validator("Task")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "UNKNOWN_EXCEPTION")
+}
+
+func newRegionMetadata() *force.SObjectMetadata {
+	entry := func(value, validFor string) force.SObjectFieldPicklistEntryMetadata {
+		return force.SObjectFieldPicklistEntryMetadata{Value: force.String(value), Active: force.Bool(true),
+			ValidFor: force.String(validFor)}
+	}
+
+	country := forcetest.NewField("Country__c", force.FieldDataTypes.Picklist)
+	country.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{entry("US", ""), entry("CA", "")}
+
+	state := forcetest.NewField("State__c", force.FieldDataTypes.Picklist)
+	state.DependentPicklist = force.Bool(true)
+	state.ControllerName = force.String("Country__c")
+	state.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{
+		entry("NY", "gA=="), entry("ON", "QA=="), entry("Other", "wA=="),
+	}
+
+	reason := forcetest.NewField("Reason__c", force.FieldDataTypes.Multipicklist)
+	reason.DependentPicklist = force.Bool(true)
+	reason.ControllerName = force.String("Active__c")
+	reason.PicklistValues = []force.SObjectFieldPicklistEntryMetadata{entry("Churn", "gA=="), entry("Upsell", "QA==")}
+
+	return forcetest.NewSObjectMetadata("Region__c", "a02", country, state, reason,
+		forcetest.NewField("Active__c", force.FieldDataTypes.Boolean))
+}
+
+func TestDependentPicklistValues(t *testing.T) {
+	meta := newRegionMetadata()
+
+	values, err := meta.DependentPicklistValues("State__c", "CA")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ON", "Other"}, values)
+
+	values, err = meta.DependentPicklistValues("Reason__c", true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Upsell"}, values)
+
+	indexes, err := meta.FindField("State__c").PicklistValues[2].ValidForIndexes()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, indexes)
+
+	_, err = meta.DependentPicklistValues("State__c", "MX")
+	assert.NotNil(t, err)
+	_, err = meta.DependentPicklistValues("Country__c", "US")
+	assert.NotNil(t, err)
+
+	v := force.NewValidator(meta)
+	assert.Nil(t, v.ValidateCreate(force.SObject{"Country__c": "US", "State__c": "NY", "Reason__c": "Churn"}))
+	assert.Nil(t, v.ValidateUpdate(force.SObject{"State__c": "ON"}))
+
+	err = v.ValidateCreate(force.SObject{"Country__c": "US", "State__c": "ON", "Active__c": false,
+		"Reason__c": "Churn;Upsell"})
+	validationErr, ok := err.(*force.ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "State__c: value ON is not valid for Country__c = US", validationErr.Errors[0].Message)
+	assert.Equal(t, "Reason__c: value Upsell is not valid for Active__c = false", validationErr.Errors[1].Message)
+}
+
+func TestDependentPicklistValues_ControllerDefaults(t *testing.T) {
+	meta := newRegionMetadata()
+	meta.FindField("Country__c").PicklistValues[1].DefaultValue = force.Bool(true)
+	meta.FindField("Active__c").DefaultValue = true
+	v := force.NewValidator(meta)
+
+	// Country__c defaults to CA and Active__c to true when they are left out on create.
+	assert.Nil(t, v.ValidateCreate(force.SObject{"State__c": "ON", "Reason__c": "Upsell"}))
+
+	err := v.ValidateCreate(force.SObject{"State__c": "NY", "Reason__c": "Churn"})
+	validationErr, ok := err.(*force.ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "State__c: value NY is not valid for Country__c = CA", validationErr.Errors[0].Message)
+	assert.Equal(t, "Reason__c: value Churn is not valid for Active__c = true", validationErr.Errors[1].Message)
+
+	// A controller defaulting to a formula can't be evaluated, so its dependent values are not checked.
+	meta.FindField("Active__c").DefaultValueFormula = force.String("ISPICKVAL(Country__c, 'CA')")
+	err = v.ValidateCreate(force.SObject{"Country__c": "CA", "Reason__c": "Churn"})
+	assert.Nil(t, err)
+}
+
+func TestDependentPicklistValues_InvalidValidFor(t *testing.T) {
+	meta := newRegionMetadata()
+	meta.FindField("State__c").PicklistValues[0].ValidFor = force.String("not base64!")
+	v := force.NewValidator(meta)
+
+	err := v.ValidateCreate(force.SObject{"Country__c": "US", "State__c": "NY"})
+	assert.NotNil(t, err)
+	_, ok := err.(*force.ValidationError)
+	assert.False(t, ok)
+}